@@ -0,0 +1,125 @@
+package gen
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// generatedFileSuffixes suffixes of files generated for each model, gen.go and scopes.go are shared by all models
+var generatedFileSuffixes = []string{".gen.go", ".gen_test.go"}
+
+// generatedFileHeader first line of DO NOT EDIT header written by gen
+var generatedFileHeader = strings.SplitN(strings.TrimSpace(tmpl.NotEditMark), "\n", 2)[0]
+
+// Clean remove files generated for tables or models no longer generated, e.g. dropped tables, call it after
+// all models are generated by GenerateModel, GenerateAllTable or ApplyBasic.
+// Only files in query path and model paths carrying the DO NOT EDIT header of gen are removed, sub dirs of model path
+// are walked when models are split into packages by ModelPkgPerTable or PackageByPrefix, hand-written files are never touched
+func (g *Generator) Clean() error {
+	if len(g.models) == 0 && len(g.Data) == 0 {
+		return fmt.Errorf("no model is generated, clean would remove all generated files")
+	}
+	modelOutPath, err := g.getModelOutputPath()
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool)
+	dirs := map[string]bool{filepath.Clean(g.OutPath): true, filepath.Clean(modelOutPath): true}
+	for _, data := range g.models {
+		if data == nil || !data.Generated {
+			continue
+		}
+		file := filepath.Clean(g.modelFilePath(modelOutPath, data))
+		keep[file] = true
+		dirs[filepath.Dir(file)] = true
+	}
+	if g.splitsModelPkgs() { // packages of tables no longer generated are sub dirs of model path too
+		err = filepath.WalkDir(modelOutPath, func(path string, entry fs.DirEntry, err error) error {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			if err == nil && entry.IsDir() {
+				dirs[filepath.Clean(path)] = true
+			}
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("walk dir %s fail: %w", modelOutPath, err)
+		}
+	}
+	if !g.SingleQueryFile {
+		for _, data := range g.Data {
+			for _, suffix := range generatedFileSuffixes {
+				keep[filepath.Join(g.OutPath, data.FileName+suffix)] = true
+			}
+		}
+	}
+
+	var stale []string
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read dir %s fail: %w", dir, err)
+		}
+		for _, entry := range entries {
+			file := filepath.Join(dir, entry.Name())
+			if entry.IsDir() || keep[file] || !hasGeneratedFileSuffix(entry.Name()) {
+				continue
+			}
+			generated, err := isGeneratedFile(file)
+			if err != nil {
+				return err
+			}
+			if generated {
+				stale = append(stale, file)
+			}
+		}
+	}
+	sort.Strings(stale)
+
+	for _, file := range stale {
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("remove stale file %s fail: %w", file, err)
+		}
+		g.info("remove stale file: " + file)
+	}
+	return nil
+}
+
+// hasGeneratedFileSuffix check if file name has suffix of files generated for each model
+func hasGeneratedFileSuffix(name string) bool {
+	for _, suffix := range generatedFileSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGeneratedFile check if the first non-blank line of file is DO NOT EDIT header of gen
+func isGeneratedFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("open file %s fail: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line == generatedFileHeader, nil
+		}
+	}
+	return false, scanner.Err()
+}
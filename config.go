@@ -1,14 +1,19 @@
 package gen
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"text/template"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/utils/tests"
 
+	"gorm.io/gen/internal/generate"
 	"gorm.io/gen/internal/model"
 )
 
@@ -37,28 +42,142 @@ type Config struct {
 	OutFile      string // query code file name, default: gen.go
 	ModelPkgPath string // generated model code's package name
 	WithUnitTest bool   // generate unit test for query code
+	DryRun       bool   // report files would be generated by Execute without writing them
+	// build constraints of generated go files, e.g. []string{"integration", "linux || darwin"}
+	BuildTags []string
 
 	// generate model global configuration
-	FieldNullable     bool // generate pointer when field is nullable
+	FieldNullable     bool // generate pointer when field is nullable, including *[]byte and *time.Time, sql.Null* and []uint8 scan types are replaced by pointer of their value type
 	FieldCoverable    bool // generate pointer when field has default value, to fix problem zero value cannot be assign: https://gorm.io/docs/create.html#Default-Values
 	FieldSignable     bool // detect integer field's unsigned type, adjust generated data type
 	FieldWithIndexTag bool // generate with gorm index tag
 	FieldWithTypeTag  bool // generate with gorm column type tag
+	WithIndexSort     bool // generate index tag with sort direction and collation, e.g. index:idx_name,priority:1,sort:desc
+	FieldWithComment  bool // generate column comment as doc comment above field instead of trailing comment
+	// generate value instead of pointer for nullable column with non-null default value when FieldNullable is enabled
+	FieldNullableExceptDefaulted bool
+	// generate check constraints read from mysql 8 or postgres as doc comment above model struct, for documentation only
+	FieldWithCheckConstraints bool
+	// generate default tag which AutoMigrate can round-trip, e.g. default:'active'
+	FieldWithDefaultValueTag bool
+	// generate index tags of mysql 8 invisible indexes, they are skipped by default as AutoMigrate recreates them as visible
+	IncludeInvisibleIndexes bool
+	// tag generated (computed) columns of mysql and postgres read-only (gorm:"->")
+	DetectGeneratedColumns bool
+	// generate belongs-to relation fields from foreign keys, foreign keys referencing tables which are not generated are skipped
+	WithForeignKeyRelations bool
+	// generate has-one/has-many relation fields on models referenced by other generated models' foreign keys
+	WithReverseRelations bool
+	// generate integer columns as go types of the same width read from column type, e.g. smallint unsigned => uint16
+	PreciseIntegerTypes bool
+	// generate mysql tinyint(1) columns as bool when PreciseIntegerTypes is enabled, instead of int8
+	PreciseTinyIntAsBool bool
+	// generate json/jsonb columns of mysql and postgres as datatypes.JSON, unless mapped by WithDataTypeMap
+	UseDatatypesJSON bool
+	// generate one-dimensional postgres array columns as types of ArrayLibPQ or ArrayLibPgx, e.g. integer[] => pq.Int32Array
+	PostgresArrayLib ArrayLib
+	// generate named go type with constants for each value of mysql enum columns, e.g. UserStatus
+	GenerateEnumTypes bool
+	// nullable timestamp column with this name is generated as gorm.DeletedAt, default: deleted_at (matched even when not null)
+	SoftDeleteField string
+	// detect nullable timestamp columns named in SoftDeleteFields as soft delete columns too
+	DetectSoftDelete bool
+	// names of soft delete columns detected when DetectSoftDelete is enabled, default: [deleted_at]
+	SoftDeleteFields []string
+	// generate TableName() with schema prefix for tables of non-default schema, e.g. analytics.events
+	TableNameWithSchema bool
+	// schema qualifying generated table names of all tables, takes precedence over TableNameWithSchema
+	TableNamePrefix string
+	// base struct embedded into models having all of its columns instead of generating them inline
+	EmbedBaseModel BaseModelSpec
+	// generate <Model>Columns variable and constants holding column names of model fields in model package
+	WithColumnNames bool
+	// generate each table model into its own package under model path, e.g. model/user/user.gen.go with package user
+	ModelPkgPerTable bool
+	// table name prefix => model sub package under model path, e.g. {"billing_": "billing"}
+	PackageByPrefix map[string]string
+	// column name => scope function name generated into scopes.go of query package, e.g. {"tenant_id": "ByTenant"}
+	ScopeColumns map[string]string
+	// trim prefix of PackageByPrefix from model struct name, e.g. billing_invoices => billing.Invoice
+	StripPackagePrefix bool
+	// state source schema and table in model doc comment, e.g. // User maps table "public"."users"
+	TableNameInComment bool
+	// write hash of table schema into model file header, used by CheckStale
+	WithSchemaFingerprint bool
+	// dialect name => whether resolve column go type from driver scan type, e.g. {"clickhouse": false}
+	UseScanTypeDialects map[string]bool
+	// version column of optimistic locking, models having it get UpdateWithVersion method in query code, e.g. version
+	OptimisticLockField string
+	// generate <Model>Filter struct and FilterConds method translating its non-nil fields to conditions in query code
+	WithQueryFilter bool
+	// generate <Model>OrderBy function in query code mapping column names of model to order expressions
+	WithOrderBy bool
+	// match column names of <Model>OrderBy case-insensitively
+	OrderByCaseInsensitive bool
+	// generate UpsertByColumns method in query code, dialect of connected db must support upsert
+	WithUpsert bool
+	// generate query code of all structs into OutFile instead of a <table>.gen.go file per struct
+	SingleQueryFile bool
+	// generate BulkInsert method in query code when greater than 0, used as batch size when BulkInsert is called with 0
+	DefaultBatchSize int
+	// save associations of records created by generated CreateInBatches and BulkInsert
+	BatchCreateWithAssociations bool
+	// generate WhereBy<Field> methods filtering by columns of unique single-column index, e.g. u.WhereByEmail("a@b.com")
+	GenerateIndexLookups bool
+	// generate DAO methods executing sql and transaction helpers with ctx as first argument, e.g. First(ctx)
+	ContextFirstArg bool
+	// omit Save and FirstOrCreate and reject Delete by models in query code of tables without primary key
+	RequirePrimaryKey bool
+	// text/template overriding built-in model template, executed with TemplateData of each table model
+	ModelTemplate string
+	// text/template overriding built-in query code of each model, executed with TemplateData
+	QueryTemplate string
+
+	// number of tables introspected concurrently by GenerateAllTable, default: 1
+	IntrospectConcurrency int
+	// abort introspection of GenerateAllTable at the first table error, otherwise errors of all tables are reported
+	IntrospectFailFast bool
+	// retry introspection queries failing with connection-level errors at most IntrospectRetries times
+	IntrospectRetries int
+	// wait before the first retry of introspection query, doubled after each retry
+	IntrospectBackoff time.Duration
+
+	// mark fields of nullable columns optional in messages written by GenerateProto
+	ProtoOptionalNullable bool
 
 	Mode GenerateMode // generate mode
+	// generate I<Model>Do interfaces as WithQueryInterface mode and assert DOs implement them
+	GenerateQueryInterfaces bool
+
+	// context of database introspection and generation, default: context.Background()
+	Context context.Context
 
 	queryPkgName   string // generated query code's package name
 	modelPkgPath   string // model pkg path in target project
 	dbNameOpts     []model.SchemaNameOpt
 	importPkgPaths []string
+	tableInfo      model.ITableInfo // table metadata provider, read from db when nil
+	tableFilters   []ModelOpt       // table filter options applied by GenerateAllTable
 
 	// name strategy for syncing table from db
 	tableNameNS func(tableName string) (targetTableName string)
 	modelNameNS func(tableName string) (modelName string)
 	fileNameNS  func(tableName string) (fileName string)
+	diyMethodNS func(methodName string) (newName string)
+	initialisms []string
+	naming      model.NamingStrategy
 
 	dataTypeMap    map[string]func(columnType gorm.ColumnType) (dataType string)
+	fieldTypeRules []model.FieldTypeRule
+	dateType       string                               // go type of date-only columns
+	jsonTypes      map[string]map[string]model.JSONType // table name => column name => go type of json column
 	fieldJSONTagNS func(columnName string) (tagContent string)
+	tagProviders   []model.TagProvider
+
+	fileModifier   func(path string, content []byte) ([]byte, error)
+	fileSystem     FileSystem // destination of generated files, written to disk when nil
+	modelInterface generate.ModelInterface
+	dbResolver     string // name of dbresolver resolver used by generated query code
 
 	modelOpts []ModelOpt
 }
@@ -96,16 +215,157 @@ func (cfg *Config) WithFileNameStrategy(ns func(tableName string) (fileName stri
 	cfg.fileNameNS = ns
 }
 
+// WithDIYMethodRenameStrategy specify name strategy of methods generated from interfaces applied by ApplyInterface,
+// sql of method comment is parsed with the declared name, e.g. strings.TrimPrefix(name, "Get") generates GetByID as ByID
+func (cfg *Config) WithDIYMethodRenameStrategy(ns func(methodName string) (newName string)) {
+	cfg.diyMethodNS = ns
+}
+
+// WithInitialisms specify initialisms kept in their written form in model struct and field names,
+// e.g. WithInitialisms("ID", "OAuth2") generates oauth2_token as OAuth2Token
+func (cfg *Config) WithInitialisms(words ...string) {
+	cfg.initialisms = append(cfg.initialisms, words...)
+}
+
+// WithNamingStrategy specify naming strategy of table, model struct and field names, only work when syncing table from db.
+// WithTableNameStrategy and WithModelNameStrategy take precedence over it
+func (cfg *Config) WithNamingStrategy(ns NamingStrategy) {
+	cfg.naming = ns
+}
+
 // WithDataTypeMap specify data type mapping relationship, only work when syncing table from db
 func (cfg *Config) WithDataTypeMap(newMap map[string]func(columnType gorm.ColumnType) (dataType string)) {
 	cfg.dataTypeMap = newMap
 }
 
+// FieldTypeByName specify go type of columns whose name matches pattern, only work when syncing table from db.
+// It takes precedence over WithDataTypeMap, patterns are evaluated in registration order and the first match wins.
+// goType can be qualified with import path, e.g. "github.com/acme/money.Money", then the import is added automatically
+func (cfg *Config) FieldTypeByName(pattern *regexp.Regexp, goType string) {
+	if pkgPath, typ := splitTypeImport(goType); pkgPath != "" {
+		cfg.WithImportPkgPath(pkgPath)
+		goType = typ
+	}
+	cfg.fieldTypeRules = append(cfg.fieldTypeRules, model.FieldTypeRule{Pattern: pattern, GoType: goType})
+}
+
+// WithValueObjectType specify value object type defined in importPath for columns whose name matches columnPattern,
+// e.g. WithValueObjectType(regexp.MustCompile(`^email$`), "Email", "github.com/acme/domain") generates domain.Email.
+// The type should implement sql.Scanner and driver.Valuer so that gorm can read and write it.
+// It shares evaluation order with FieldTypeByName, the first matching pattern wins
+func (cfg *Config) WithValueObjectType(columnPattern *regexp.Regexp, goType, importPath string) {
+	cfg.FieldTypeByName(columnPattern, qualifyType(goType, importPath))
+}
+
+// qualifyType qualify goType with importPath, e.g. qualifyType("*civil.Date", "cloud.google.com/go/civil")
+// returns "*cloud.google.com/go/civil.Date", goType is returned as is when importPath is empty
+func qualifyType(goType, importPath string) string {
+	if importPath = strings.Trim(strings.TrimSpace(importPath), `"`); importPath != "" {
+		rest := strings.TrimLeft(goType, "*[]")
+		goType = goType[:len(goType)-len(rest)] + importPath + "." + rest[strings.LastIndex(rest, ".")+1:]
+	}
+	return goType
+}
+
+// DateType specify go type defined in importPath for date-only columns (DATE of mysql, postgres, sqlite and sqlserver),
+// e.g. DateType("civil.Date", "cloud.google.com/go/civil"), datetime and timestamp columns are still generated as time.Time.
+// It only works when syncing table from db, FieldTypeByName and WithDataTypeMap take precedence over it
+func (cfg *Config) DateType(goType, importPath string) {
+	pkgPath, typ := splitTypeImport(qualifyType(strings.TrimSpace(goType), importPath))
+	if pkgPath != "" {
+		cfg.WithImportPkgPath(pkgPath)
+	}
+	cfg.dateType = typ
+}
+
+// FieldType specify go type of json or jsonb column of table, e.g. a struct implementing sql.Scanner and driver.Valuer,
+// only work when syncing table from db and takes precedence over other type mappings, other columns are not affected.
+// goType can be qualified with import path, e.g. "github.com/acme/mypkg.Payload", then the import is added to the model file.
+// Generating fails when goType is not a valid qualified identifier
+func (cfg *Config) FieldType(tableName, columnName, goType string) {
+	pkgPath, typ := splitTypeImport(strings.TrimSpace(goType))
+	if cfg.jsonTypes == nil {
+		cfg.jsonTypes = make(map[string]map[string]model.JSONType)
+	}
+	if cfg.jsonTypes[tableName] == nil {
+		cfg.jsonTypes[tableName] = make(map[string]model.JSONType)
+	}
+	cfg.jsonTypes[tableName][columnName] = model.JSONType{GoType: typ, PkgPath: pkgPath}
+}
+
+// WithModelInterface generate method named methodName returning primary key value of each table model, and assertion
+// that model implements interface ifaceName defined in importPath, e.g. WithModelInterface("GetID", "github.com/acme/repo", "Identifiable")
+// generates GetID() interface{} and var _ repo.Identifiable = (*User)(nil). Composite primary key is returned as []interface{}
+// of key values in column order, models of tables without primary key are skipped. Assertion is omitted when ifaceName is empty
+func (cfg *Config) WithModelInterface(methodName, importPath, ifaceName string) {
+	cfg.modelInterface = generate.ModelInterface{Method: methodName}
+	if ifaceName == "" {
+		return
+	}
+	if importPath = strings.Trim(strings.TrimSpace(importPath), `"`); importPath != "" {
+		cfg.WithImportPkgPath(importPath)
+	}
+	_, cfg.modelInterface.Interface = splitTypeImport(qualifyType(ifaceName, importPath))
+}
+
+// WithDBResolverClause make generated query code run on resolver name registered to gorm's dbresolver plugin,
+// db passed to Use, SetDefault, ReplaceDB and WithTx is wrapped with Clauses(dbresolver.Use(name)), so Transaction,
+// Begin and DAO methods run on it. ReadDB and WriteDB of query code still switch between sources and replicas of the resolver
+func (cfg *Config) WithDBResolverClause(name string) {
+	cfg.dbResolver = name
+}
+
+// WithTableFilter select tables of GenerateAllTable by regexp before they are introspected, nil regexp is ignored.
+// Tables matching exclude are skipped even if they match include, e.g. WithTableFilter(nil, regexp.MustCompile(`^flyway_|_migrations$`))
+func (cfg *Config) WithTableFilter(include, exclude *regexp.Regexp) {
+	if include != nil {
+		cfg.tableFilters = append(cfg.tableFilters, model.TableFilterOpt{Patterns: []*regexp.Regexp{include}})
+	}
+	if exclude != nil {
+		cfg.tableFilters = append(cfg.tableFilters, model.TableFilterOpt{Exclude: true, Patterns: []*regexp.Regexp{exclude}})
+	}
+}
+
 // WithJSONTagNameStrategy specify json tag naming strategy
 func (cfg *Config) WithJSONTagNameStrategy(ns func(columnName string) (tagContent string)) {
 	cfg.fieldJSONTagNS = ns
 }
 
+// WithTagProviders register providers of extra struct tags, e.g. validate or msgpack tags, they are consulted
+// per column in order of registration. Tags follow built-in gorm and json tags sorted by key, tags set by
+// field options take precedence. Two providers emitting the same key of a column is an error. e.g.
+//
+//	g.WithTagProviders(func(c *gen.Column) (key, value string, ok bool) {
+//		if nullable, ok := c.Nullable(); ok && !nullable {
+//			return "validate", "required", true
+//		}
+//		return "", "", false
+//	})
+func (cfg *Config) WithTagProviders(providers ...TagProvider) {
+	cfg.tagProviders = append(cfg.tagProviders, providers...)
+}
+
+// WithFileModifier specify modifier of generated file content, it is called right before each file is written
+// with imports already processed, e.g. to regroup imports or inject license header, files of Plan and
+// ExecuteDryRun included. Its output is formatted by gofmt again, generating is aborted when it returns an error
+// or malformed code. It may be called concurrently for different files
+func (cfg *Config) WithFileModifier(modifier func(path string, content []byte) ([]byte, error)) {
+	cfg.fileModifier = modifier
+}
+
+// FileSystem destination of generated files, e.g. in-memory file system of tests or sandboxed build actions.
+// path is the absolute path file would be written to on disk, creating its directory is up to WriteFile
+type FileSystem interface {
+	WriteFile(path string, data []byte) error
+}
+
+// WithFileSystem write generated files into fs instead of disk, directories are not created on disk.
+// Import path of model package is resolved by go.mod on disk above model path. WriteFile may be called concurrently
+// for different files
+func (cfg *Config) WithFileSystem(fs FileSystem) {
+	cfg.fileSystem = fs
+}
+
 // WithImportPkgPath specify import package path
 func (cfg *Config) WithImportPkgPath(paths ...string) {
 	for i, path := range paths {
@@ -146,6 +406,19 @@ func (cfg *Config) Revise() (err error) {
 	if strings.TrimSpace(cfg.ModelPkgPath) == "" {
 		cfg.ModelPkgPath = model.DefaultModelPkg
 	}
+	if cfg.GenerateQueryInterfaces {
+		cfg.Mode |= WithQueryInterface
+	}
+	if cfg.SoftDeleteField == "" {
+		cfg.SoftDeleteField = model.DefaultSoftDeleteField
+	}
+	if cfg.DetectSoftDelete && len(cfg.SoftDeleteFields) == 0 {
+		cfg.SoftDeleteFields = []string{model.DefaultSoftDeleteField}
+	}
+	if pkgPath, typ := splitTypeImport(cfg.EmbedBaseModel.Type); pkgPath != "" {
+		cfg.WithImportPkgPath(pkgPath)
+		cfg.EmbedBaseModel.Type = typ
+	}
 
 	cfg.OutPath, err = filepath.Abs(cfg.OutPath)
 	if err != nil {
@@ -164,8 +437,31 @@ func (cfg *Config) Revise() (err error) {
 	if cfg.db == nil {
 		cfg.db, _ = gorm.Open(tests.DummyDialector{})
 	}
+	if cfg.Context == nil {
+		cfg.Context = context.Background()
+	}
 
+	if _, err = template.New("model").Parse(cfg.ModelTemplate); err != nil {
+		return fmt.Errorf("model template is invalid: %w", err)
+	}
+	if _, err = template.New("query").Parse(cfg.QueryTemplate); err != nil {
+		return fmt.Errorf("query template is invalid: %w", err)
+	}
 	return nil
 }
 
+// softDeleteFields names of columns generated as gorm.DeletedAt
+func (cfg *Config) softDeleteFields() []string {
+	names := []string{cfg.SoftDeleteField}
+	if !cfg.DetectSoftDelete {
+		return names
+	}
+	for _, name := range cfg.SoftDeleteFields {
+		if name != cfg.SoftDeleteField {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func (cfg *Config) judgeMode(mode GenerateMode) bool { return cfg.Mode&mode != 0 }
@@ -335,6 +335,14 @@ func (d *DO) Scopes(funcs ...func(Dao) Dao) Dao {
 	return d.getInstance(d.db.Scopes(fcs...))
 }
 
+// DBScope convert gorm scope into scope of Dao, e.g. u.Scopes(gen.DBScope(query.ByTenant(1)))
+func DBScope(scope func(*gorm.DB) *gorm.DB) func(Dao) Dao {
+	return func(dao Dao) Dao {
+		do := dao.(*DO)
+		return do.getInstance(scope(do.db))
+	}
+}
+
 // Unscoped ...
 func (d *DO) Unscoped() Dao {
 	return d.getInstance(d.db.Unscoped())
@@ -669,6 +677,16 @@ func (d *DO) FirstOrCreate() (result interface{}, err error) {
 	return d.singleQuery(d.db.FirstOrCreate)
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (d *DO) FirstOrInitWith(seed interface{}) error {
+	return d.db.FirstOrInit(seed).Error
+}
+
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (d *DO) FirstOrCreateWith(seed interface{}) error {
+	return d.db.FirstOrCreate(seed).Error
+}
+
 // Update ...
 func (d *DO) Update(column field.Expr, value interface{}) (info ResultInfo, err error) {
 	tx := d.prepareTx()
@@ -1,13 +1,20 @@
 package gen
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	"gorm.io/hints"
 
 	"gorm.io/gen/field"
@@ -164,6 +171,13 @@ func TestDO_methods(t *testing.T) {
 			ExpectedVars: []interface{}{"tom", 18},
 			Result:       "WHERE `name` = ? AND `age` > ?",
 		},
+		{
+			Expr: DBScope(func(db *gorm.DB) *gorm.DB {
+				return db.Where(clause.Eq{Column: clause.Column{Name: "tenant_id"}, Value: 1})
+			})(&u.Where(u.Name.Eq("tom")).DO),
+			ExpectedVars: []interface{}{"tom", 1},
+			Result:       "WHERE `name` = ? AND `tenant_id` = ?",
+		},
 		{
 			Expr:   u.Order(u.ID),
 			Result: "ORDER BY `id`",
@@ -423,3 +437,239 @@ func TestDO_methods(t *testing.T) {
 		checkBuildExpr(t, testcase.Expr, testcase.Opts, testcase.Result, testcase.ExpectedVars)
 	}
 }
+
+var driverSeq int64
+
+// openFakeDB open gorm db on fake driver d with default callbacks, driver is registered with a unique name every time
+func openFakeDB(tb testing.TB, d driver.Driver) *gorm.DB {
+	name := fmt.Sprintf("gen_fake_%d", atomic.AddInt64(&driverSeq, 1))
+	sql.Register(name, d)
+	sqlDB, err := sql.Open(name, "")
+	if err != nil {
+		tb.Fatalf("open fake db fail: %s", err)
+	}
+	db, err := gorm.Open(mysqlDialectors{}, &gorm.Config{ConnPool: sqlDB, Logger: logger.Discard})
+	if err != nil {
+		tb.Fatalf("open gorm db fail: %s", err)
+	}
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return db
+}
+
+// countDriver fake database driver answering every query with a zero count, the last query is recorded
+type countDriver struct{ query string }
+
+func (d *countDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d *countDriver) Prepare(query string) (driver.Stmt, error) {
+	d.query = query
+	return d, nil
+}
+func (d *countDriver) Close() error                               { return nil }
+func (d *countDriver) Begin() (driver.Tx, error)                  { return nil, driver.ErrSkip }
+func (d *countDriver) NumInput() int                              { return -1 }
+func (d *countDriver) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (d *countDriver) Query([]driver.Value) (driver.Rows, error) {
+	return &countRows{values: []driver.Value{int64(0)}}, nil
+}
+
+type countRows struct{ values []driver.Value }
+
+func (r *countRows) Columns() []string { return []string{"count"} }
+func (r *countRows) Close() error      { return nil }
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.values == nil {
+		return io.EOF
+	}
+	copy(dest, r.values)
+	r.values = nil
+	return nil
+}
+
+type softUser struct {
+	ID        int64
+	Name      string
+	DeletedAt gorm.DeletedAt
+}
+
+type ISoftUserDo interface {
+	IGenericsDo[ISoftUserDo, *softUser]
+	FirstOrInitWith(seed *softUser) (*softUser, error)
+	CountBy(conds ...Condition) (count int64, err error)
+	FindPage(offset int, limit int, conds ...Condition) (result []*softUser, count int64, err error)
+}
+
+type softUserDo struct {
+	GenericsDo[ISoftUserDo, *softUser]
+}
+
+func (d *softUserDo) withDO(do Dao) ISoftUserDo {
+	r := &softUserDo{}
+	r.DO = *do.(*DO)
+	r.IWithDO = WithDOFunc[ISoftUserDo](d.withDO)
+	return r
+}
+
+func TestGenericsDo_CountBy(t *testing.T) {
+	d := &countDriver{}
+	countDB := openFakeDB(t, d)
+
+	do := &softUserDo{}
+	do.IWithDO = WithDOFunc[ISoftUserDo](do.withDO)
+	do.UseDB(countDB)
+	do.UseModel(&softUser{})
+
+	count, err := do.CountBy(field.NewString("", "name").Eq("nobody"))
+	if err != nil || count != 0 {
+		t.Errorf("CountBy expects (0, nil), got (%d, %v)", count, err)
+	}
+	if !strings.Contains(d.query, "`name` = ?") || !strings.Contains(d.query, "`deleted_at` IS NULL") {
+		t.Errorf("CountBy expects conditions and soft delete condition, got %q", d.query)
+	}
+
+	if _, err = do.Unscoped().CountBy(); err != nil {
+		t.Errorf("Unscoped CountBy fail: %s", err)
+	}
+	if strings.Contains(d.query, "deleted_at") {
+		t.Errorf("Unscoped CountBy expects no soft delete condition, got %q", d.query)
+	}
+}
+
+// userDriver fake database driver answering every query with user named found, the last query is recorded
+type userDriver struct{ countDriver }
+
+func (d *userDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d *userDriver) Prepare(query string) (driver.Stmt, error) {
+	d.query = query
+	return d, nil
+}
+func (d *userDriver) Query([]driver.Value) (driver.Rows, error) {
+	return &userRows{countRows{values: []driver.Value{int64(1), "found"}}}, nil
+}
+
+type userRows struct{ countRows }
+
+func (r *userRows) Columns() []string { return []string{"id", "name"} }
+
+func TestGenericsDo_singleQuery(t *testing.T) {
+	d := &userDriver{}
+	queryDB := openFakeDB(t, d)
+
+	do := &softUserDo{}
+	do.IWithDO = WithDOFunc[ISoftUserDo](do.withDO)
+	do.UseDB(queryDB)
+	do.UseModel(&softUser{})
+	name := field.NewString("", "name")
+
+	testcases := []struct {
+		query  func() (*softUser, error)
+		order  string
+		method string
+	}{
+		{func() (*softUser, error) { return do.Where(name.Eq("a")).First() }, "ORDER BY `soft_users`.`id` LIMIT ?", "First"},
+		{func() (*softUser, error) { return do.Where(name.Eq("a")).Last() }, "ORDER BY `soft_users`.`id` DESC LIMIT ?", "Last"},
+		{func() (*softUser, error) { return do.Where(name.Eq("a")).Take() }, "IS NULL LIMIT ?", "Take"},
+	}
+	for _, tc := range testcases {
+		if _, err := tc.query(); err != nil {
+			t.Fatalf("%s fail: %s", tc.method, err)
+		}
+		if !strings.Contains(d.query, "`name` = ?") || !strings.Contains(d.query, "`deleted_at` IS NULL") || !strings.HasSuffix(d.query, tc.order) {
+			t.Errorf("%s expects conditions, soft delete condition and %q, got %q", tc.method, tc.order, d.query)
+		}
+		if tc.method == "Take" && strings.Contains(d.query, "ORDER BY") {
+			t.Errorf("Take expects no order, got %q", d.query)
+		}
+	}
+
+	seed := &softUser{Name: "seed"}
+	result, err := do.Where(name.Eq("a")).FirstOrInitWith(seed)
+	if err != nil || result != seed || seed.ID != 1 || seed.Name != "found" {
+		t.Errorf("FirstOrInitWith expects found record scanned into seed, got (%+v, %v)", result, err)
+	}
+	if !strings.Contains(d.query, "`name` = ?") || !strings.HasSuffix(d.query, "ORDER BY `soft_users`.`id` LIMIT ?") {
+		t.Errorf("FirstOrInitWith expects first record query, got %q", d.query)
+	}
+}
+
+// pageDriver fake database driver answering count query with 5 and other queries with 3 users, queries are recorded
+type pageDriver struct{ queries []string }
+
+func (d *pageDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d *pageDriver) Prepare(query string) (driver.Stmt, error) {
+	d.queries = append(d.queries, query)
+	return &pageStmt{query: query}, nil
+}
+func (d *pageDriver) Close() error              { return nil }
+func (d *pageDriver) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type pageStmt struct{ query string }
+
+func (s *pageStmt) Close() error                               { return nil }
+func (s *pageStmt) NumInput() int                              { return -1 }
+func (s *pageStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *pageStmt) Query([]driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "count(*)") {
+		return &pageRows{columns: []string{"count"}, values: [][]driver.Value{{int64(5)}}}, nil
+	}
+	return &pageRows{columns: []string{"id", "name"}, values: [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}, {int64(3), "c"}}}, nil
+}
+
+type pageRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *pageRows) Columns() []string { return r.columns }
+func (r *pageRows) Close() error      { return nil }
+func (r *pageRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestGenericsDo_FindPage(t *testing.T) {
+	d := &pageDriver{}
+	pageDB := openFakeDB(t, d)
+
+	do := &softUserDo{}
+	do.IWithDO = WithDOFunc[ISoftUserDo](do.withDO)
+	do.UseDB(pageDB)
+	do.UseModel(&softUser{})
+	name := field.NewString("", "name")
+
+	testcases := []struct {
+		offset, limit int
+		count         int64
+		queries       int // count query is skipped when the last page is found
+		limited       bool
+	}{
+		{0, 10, 3, 1, true},
+		{2, 2, 5, 2, true},
+		{0, 0, 3, 1, false}, // all matching records without paging
+		{1, -1, 3, 1, false},
+	}
+	for _, tc := range testcases {
+		d.queries = nil
+		result, count, err := do.FindPage(tc.offset, tc.limit, name.Neq("z"))
+		if err != nil || len(result) != 3 || count != tc.count {
+			t.Errorf("FindPage(%d, %d) expects (3 records, %d, nil), got (%d, %d, %v)", tc.offset, tc.limit, tc.count, len(result), count, err)
+		}
+		if len(d.queries) != tc.queries {
+			t.Fatalf("FindPage(%d, %d) expects %d queries, got %q", tc.offset, tc.limit, tc.queries, d.queries)
+		}
+		for _, query := range d.queries {
+			if !strings.Contains(query, "`name` <> ?") || !strings.Contains(query, "`deleted_at` IS NULL") {
+				t.Errorf("FindPage(%d, %d) expects conditions and soft delete condition, got %q", tc.offset, tc.limit, query)
+			}
+		}
+		if limited := strings.Contains(d.queries[0], "LIMIT"); limited != tc.limited {
+			t.Errorf("FindPage(%d, %d) expects limited %t, got %q", tc.offset, tc.limit, tc.limited, d.queries[0])
+		}
+		if len(d.queries) > 1 && (strings.Contains(d.queries[1], "LIMIT") || strings.Contains(d.queries[1], "OFFSET")) {
+			t.Errorf("FindPage(%d, %d) expects count query without limit and offset, got %q", tc.offset, tc.limit, d.queries[1])
+		}
+	}
+}
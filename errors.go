@@ -5,4 +5,6 @@ import "errors"
 var (
 	// ErrEmptyCondition empty condition
 	ErrEmptyCondition = errors.New("empty condition")
+	// ErrStaleVersion no record matches version of generated UpdateWithVersion, e.g. record is updated concurrently
+	ErrStaleVersion = errors.New("stale version")
 )
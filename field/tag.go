@@ -15,12 +15,17 @@ const (
 	TagKeyGormColumn        = "column"
 	TagKeyGormType          = "type"
 	TagKeyGormPrimaryKey    = "primaryKey"
+	TagKeyGormPriority      = "priority"
 	TagKeyGormAutoIncrement = "autoIncrement"
 	TagKeyGormNotNull       = "not null"
 	TagKeyGormUniqueIndex   = "uniqueIndex"
 	TagKeyGormIndex         = "index"
 	TagKeyGormDefault       = "default"
 	TagKeyGormComment       = "comment"
+	TagKeyGormForeignKey    = "foreignKey"
+	TagKeyGormReferences    = "references"
+	TagKeyGormSerializer    = "serializer"
+	TagKeyGormReadOnly      = "->"
 )
 
 var (
@@ -31,11 +36,14 @@ var (
 		TagKeyGormColumn:        10,
 		TagKeyGormType:          9,
 		TagKeyGormPrimaryKey:    8,
+		TagKeyGormPriority:      8, // same as primaryKey, sorted after it by name
 		TagKeyGormAutoIncrement: 7,
 		TagKeyGormNotNull:       6,
 		TagKeyGormUniqueIndex:   5,
 		TagKeyGormIndex:         4,
 		TagKeyGormDefault:       3,
+		TagKeyGormForeignKey:    2,
+		TagKeyGormReferences:    1,
 		TagKeyGormComment:       0,
 	}
 )
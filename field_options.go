@@ -151,6 +151,18 @@ var (
 			return m
 		}
 	}
+	// FieldGormType specify column type in gorm tag, e.g. type:json for custom type with serializer, composes with FieldType
+	FieldGormType = func(columnName string, dbType string) model.ModifyFieldOpt {
+		return func(m *model.Field) *model.Field {
+			if m.ColumnName == columnName {
+				if m.GORMTag == nil {
+					m.GORMTag = field.GormTag{}
+				}
+				m.GORMTag.Set(field.TagKeyGormType, dbType)
+			}
+			return m
+		}
+	}
 	// FieldGenType specify field gen type in generated dao
 	FieldGenType = func(columnName string, newType string) model.ModifyFieldOpt {
 		return func(m *model.Field) *model.Field {
@@ -306,6 +318,51 @@ var (
 			}
 		}
 	}
+	// FieldRelateSelf relate to the model being generated, e.g. Parent and Children of adjacency list table.
+	// belongs-to and has-one fields are generated as pointer, recursive struct type needs it
+	FieldRelateSelf = func(relationship field.RelationshipType, fieldName string, config *field.RelateConfig) model.CreateFieldOpt {
+		if config == nil {
+			config = &field.RelateConfig{}
+		}
+
+		return func(*model.Field) *model.Field {
+			prefix := config.RelateFieldPrefix(relationship)
+			if prefix == "" || prefix == "[]" {
+				prefix += "*"
+			}
+			return &model.Field{
+				Name:         fieldName,
+				Type:         prefix, // completed with model struct name
+				Tag:          config.GetTag(fieldName),
+				GORMTag:      config.GORMTag,
+				Relation:     field.NewRelationWithType(relationship, fieldName, ""),
+				SelfRelation: true,
+			}
+		}
+	}
+
+	// ExcludeTables skip tables whose name matches any RegExp, only work with GenerateAllTable.
+	// It takes precedence over IncludeTables, e.g. ExcludeTables("_migrations$", "^events_\\d{4}_")
+	ExcludeTables = func(tableNameRegs ...string) model.TableFilterOpt {
+		return model.TableFilterOpt{Exclude: true, Patterns: compileRegs(tableNameRegs)}
+	}
+	// IncludeTables generate only tables whose name matches any RegExp, only work with GenerateAllTable
+	IncludeTables = func(tableNameRegs ...string) model.TableFilterOpt {
+		return model.TableFilterOpt{Patterns: compileRegs(tableNameRegs)}
+	}
+
+	// WithMode specify generate mode of model, e.g. WithMode(ModelOnly) for reference tables needing struct only
+	WithMode = func(mode ModelMode) model.ModeOpt {
+		return model.ModeOpt(mode)
+	}
+
+	// FieldJSONPath generate Where<goFieldName>(value goType) in query code filtering records by value extracted from json column,
+	// e.g. FieldJSONPath("metadata", "tenant_id", "TenantID", "string") generates WhereTenantID comparing metadata ->> 'tenant_id'.
+	// Nested path is dotted, e.g. owner.id, goType is string, bool, a number type or time.Time of postgres, extracted value is cast to it.
+	// Only mysql, sqlite and postgres are supported
+	FieldJSONPath = func(columnName, jsonPath, goFieldName, goType string) model.JSONPathOpt {
+		return model.JSONPathOpt{Column: columnName, Path: jsonPath, Name: goFieldName, Type: goType}
+	}
 
 	// WithMethod add custom method for table model
 	WithMethod = func(methods ...interface{}) model.AddMethodOpt {
@@ -313,6 +370,14 @@ var (
 	}
 )
 
+func compileRegs(regs []string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(regs))
+	for i, reg := range regs {
+		patterns[i] = regexp.MustCompile(reg)
+	}
+	return patterns
+}
+
 var (
 	DefaultMethodTableWithNamer = (&defaultModel{}).TableName
 )
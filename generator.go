@@ -5,20 +5,29 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"go/build/constraint"
+	"go/format"
 	"io"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 
+	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/imports"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
 
+	"gorm.io/gen/field"
 	"gorm.io/gen/helper"
 	"gorm.io/gen/internal/generate"
 	"gorm.io/gen/internal/model"
@@ -66,6 +75,13 @@ func NewGenerator(cfg Config) *Generator {
 type genInfo struct {
 	*generate.QueryStructMeta
 	Interfaces []*generate.InterfaceMethod
+	WithUpsert bool // generate UpsertByColumns method
+
+	DefaultBatchSize int // generate BulkInsert method when greater than 0
+
+	WithIndexLookups bool // generate WhereBy<Field> methods of columns backing unique single-column index
+
+	OptimisticLock *generate.OptimisticLock // generate UpdateWithVersion method when model has version column
 }
 
 func (i *genInfo) appendMethods(methods []*generate.InterfaceMethod) {
@@ -86,6 +102,74 @@ func (i *genInfo) methodInGenInfo(m *generate.InterfaceMethod) bool {
 	return false
 }
 
+// ITableInfo table metadata provider interface
+type ITableInfo = model.ITableInfo
+
+// NamingStrategy naming strategy of table, model struct and field names
+type NamingStrategy = model.NamingStrategy
+
+// Column exported model.Column, table column info returned by ITableInfo
+type Column = model.Column
+
+// TagProvider provide struct tag key and value of column, no tag is generated when ok is false
+type TagProvider = model.TagProvider
+
+// IForeignKeyInfo table metadata provider of foreign keys, optional for ITableInfo implementations
+type IForeignKeyInfo = model.IForeignKeyInfo
+
+// ForeignKey exported model.ForeignKey, table foreign key returned by IForeignKeyInfo
+type ForeignKey = model.ForeignKey
+
+// ICheckConstraintInfo table metadata provider of check constraints, optional for ITableInfo implementations
+type ICheckConstraintInfo = model.ICheckConstraintInfo
+
+// CheckConstraint exported model.CheckConstraint, table check constraint returned by ICheckConstraintInfo
+type CheckConstraint = model.CheckConstraint
+
+// ModelMode generate mode of a single model, overriding Config.Mode of generator
+type ModelMode = model.ModelMode
+
+const (
+	// ModelWithQuery generate model struct and query code, the default mode
+	ModelWithQuery = model.ModelWithQuery
+	// ModelOnly generate model struct only, query code is skipped when model is passed to ApplyBasic or ApplyInterface
+	ModelOnly = model.ModelOnly
+)
+
+// ArrayLib library of go types generated for postgres array columns
+type ArrayLib = model.ArrayLib
+
+const (
+	// ArrayLibPQ github.com/lib/pq array types, e.g. pq.Int64Array
+	ArrayLibPQ = model.ArrayLibPQ
+	// ArrayLibPgx github.com/jackc/pgtype array types, e.g. pgtype.Int8Array
+	ArrayLibPgx = model.ArrayLibPgx
+	// ArrayLibJSON go slice types of json columns with gorm json serializer tag, e.g. []string `gorm:"serializer:json"`
+	ArrayLibJSON = model.ArrayLibJSON
+)
+
+// BaseModelSpec base struct embedded into generated models having all of its columns
+type BaseModelSpec = model.BaseModel
+
+// TemplateData execution context of Config.ModelTemplate and Config.QueryTemplate, e.g. {{.ModelStructName}} model struct name,
+// {{.TableName}} table name, {{.TableComment}} table comment, {{.StructInfo.Package}} package of model,
+// {{range .Fields}}{{.Name}} {{.Type}} {{.ColumnName}}{{end}} model fields with their columns (see ModelField),
+// {{range .ImportPkgPaths}}{{.}}{{end}} quoted import paths of field types, {{.QueryStructName}} query struct name,
+// {{.S}} receiver name of query struct and {{range .ModelMethods}}{{.MethodName}}{{end}} custom methods bound to model
+type TemplateData = generate.QueryStructMeta
+
+// ModelMeta model assembled from table passed to ModelPlugin, fields and import paths can be changed
+type ModelMeta = model.Meta
+
+// ModelField field of ModelMeta, e.g. &ModelField{Name: "Score", Type: "int", Tag: field.Tag{"json": "score"}}
+type ModelField = model.Field
+
+// ModelPlugin transform models assembled from tables before code is rendered, e.g. add validator tags or rename fields,
+// generating fails when Apply returns an error
+type ModelPlugin interface {
+	Apply(m *ModelMeta) error
+}
+
 // Logger  gen logger interface
 type Logger interface {
 	Println(v ...any)
@@ -97,6 +181,16 @@ type Generator struct {
 	Data   map[string]*genInfo                  //gen query data
 	models map[string]*generate.QueryStructMeta //gen model data
 
+	indexColumnCache *model.IndexColumnCache // index column metadata cache of current generation run
+	columnAttrCache  *model.ColumnAttrCache  // column attributes cache of current generation run
+	plannedFiles     *plannedFiles           // collect generated files instead of writing when not nil
+
+	reverseRelationsFilled bool // reverse relations are added to models
+
+	plugins []ModelPlugin // applied to models in registration order
+
+	namedDBs map[string]*gorm.DB // data sources registered by UseNamedDB
+
 	logger Logger
 }
 
@@ -105,6 +199,11 @@ func (g *Generator) SetLogger(logger Logger) {
 	g.logger = logger
 }
 
+// Use register model plugins, they are applied in registration order to each model generated from table afterwards
+func (g *Generator) Use(plugins ...ModelPlugin) {
+	g.plugins = append(g.plugins, plugins...)
+}
+
 // UseDB set db connection
 func (g *Generator) UseDB(db *gorm.DB) {
 	if db != nil {
@@ -112,6 +211,14 @@ func (g *Generator) UseDB(db *gorm.DB) {
 	}
 }
 
+// UseTableInfo set table metadata provider, columns and indexes will be read from it instead of db,
+// index column sequences are only read when db connection is set by UseDB
+func (g *Generator) UseTableInfo(info ITableInfo) {
+	if info != nil {
+		g.tableInfo = info
+	}
+}
+
 /*
 ** The feature of mapping table from database server to Golang struct
 ** Provided by @qqxhb
@@ -119,42 +226,212 @@ func (g *Generator) UseDB(db *gorm.DB) {
 
 // GenerateModel catch table info from db, return a BaseStruct
 func (g *Generator) GenerateModel(tableName string, opts ...ModelOpt) *generate.QueryStructMeta {
-	return g.GenerateModelAs(tableName, g.db.Config.NamingStrategy.SchemaName(tableName), opts...)
+	ns := model.NameStrategy{Initialisms: g.initialisms, Naming: g.naming, TrimPrefixes: g.modelNamePrefixes()}
+	return g.GenerateModelAs(tableName, ns.DefaultModelName(g.db, tableName), opts...)
 }
 
 // GenerateModelAs catch table info from db, return a BaseStruct
 func (g *Generator) GenerateModelAs(tableName string, modelName string, opts ...ModelOpt) *generate.QueryStructMeta {
-	meta, err := generate.GetQueryStructMeta(g.db, g.genModelConfig(tableName, modelName, opts))
+	return g.generateModel(g.genModelConfig(tableName, modelName, opts))
+}
+
+// GenerateModelFromView catch view info from db, return a read-only BaseStruct,
+// indexes and primary keys are not introspected and query code excludes create, update and delete methods.
+// Query code of views is only generated in WithQueryInterface mode, plain and generic query code is rejected
+func (g *Generator) GenerateModelFromView(viewName string, opts ...ModelOpt) *generate.QueryStructMeta {
+	ns := model.NameStrategy{Initialisms: g.initialisms, Naming: g.naming, TrimPrefixes: g.modelNamePrefixes()}
+	conf := g.genModelConfig(viewName, ns.DefaultModelName(g.db, viewName), opts)
+	conf.View = true
+	return g.generateModel(conf)
+}
+
+// generateModel catch table info from db with model config
+func (g *Generator) generateModel(conf *model.Config) *generate.QueryStructMeta {
+	meta, err := generate.GetQueryStructMeta(g.db, conf)
 	if err != nil {
 		g.db.Logger.Error(context.Background(), "generate struct from table fail: %s", err)
 		panic("generate struct fail")
 	}
+	return g.addModel(conf, meta)
+}
+
+// addModel register introspected model, return nil when table is ignored
+func (g *Generator) addModel(conf *model.Config, meta *generate.QueryStructMeta) *generate.QueryStructMeta {
 	if meta == nil {
-		g.info(fmt.Sprintf("ignore table <%s>", tableName))
+		g.info(fmt.Sprintf("ignore table <%s>", conf.TableName))
 		return nil
 	}
+	if old := g.models[meta.ModelStructName]; g.StripPackagePrefix && old != nil && old.TableName != meta.TableName {
+		g.db.Logger.Error(context.Background(), "model %s of table %s conflicts with table %s, specify model name by GenerateModelAs", meta.ModelStructName, meta.TableName, old.TableName)
+		panic("model name conflict")
+	}
+	for _, plugin := range g.plugins {
+		if err := meta.ApplyPlugin(plugin.Apply); err != nil {
+			g.db.Logger.Error(context.Background(), "apply plugin to model %s fail: %s", meta.ModelStructName, err)
+			panic("apply model plugin fail")
+		}
+	}
 	g.models[meta.ModelStructName] = meta
 
 	g.info(fmt.Sprintf("got %d columns from table <%s>", len(meta.Fields), meta.TableName))
 	return meta
 }
 
-// GenerateAllTable generate all tables in db
+// GenerateAllTable generate all tables in db, tables can be selected by IncludeTables and ExcludeTables options
+// and Config.WithTableFilter
 func (g *Generator) GenerateAllTable(opts ...ModelOpt) (tableModels []interface{}) {
-	tableList, err := g.db.Migrator().GetTables()
+	tableList, err := g.db.WithContext(g.Context).Migrator().GetTables()
 	if err != nil {
 		panic(fmt.Errorf("get all tables fail: %w", err))
 	}
 
 	g.info(fmt.Sprintf("find %d table from db: %s", len(tableList), tableList))
+	tableList, opts = filterTables(tableList, append(g.tableFilters[:len(g.tableFilters):len(g.tableFilters)], opts...))
+
+	g.indexColumnCache = g.prefetchIndexColumns(tableList)
+	g.columnAttrCache = g.prefetchColumnAttrs(tableList)
+	defer func() { g.indexColumnCache, g.columnAttrCache = nil, nil }()
 
+	confs, metas := g.introspectTables(tableList, opts)
 	tableModels = make([]interface{}, len(tableList))
-	for i, tableName := range tableList {
-		tableModels[i] = g.GenerateModel(tableName, opts...)
+	for i := range tableList {
+		tableModels[i] = g.addModel(confs[i], metas[i])
 	}
 	return tableModels
 }
 
+// introspectWorkers number of tables introspected concurrently, default: 1
+func (g *Generator) introspectWorkers() int {
+	if g.IntrospectConcurrency > 0 {
+		return g.IntrospectConcurrency
+	}
+	return 1
+}
+
+// introspectTables read tables' metadata by introspectWorkers workers, results are in the order of tableList.
+// errors of all tables are logged before panic, or the first one when IntrospectFailFast is set
+func (g *Generator) introspectTables(tableList []string, opts []ModelOpt) ([]*model.Config, []*generate.QueryStructMeta) {
+	ctx, cancel := context.WithCancel(g.Context)
+	defer cancel()
+
+	ns := model.NameStrategy{Initialisms: g.initialisms, Naming: g.naming, TrimPrefixes: g.modelNamePrefixes()}
+	confs := make([]*model.Config, len(tableList))
+	metas := make([]*generate.QueryStructMeta, len(tableList))
+	errs := make([]error, len(tableList))
+	opts = opts[:len(opts):len(opts)] // model options are appended by each worker
+
+	failed := -1 // table aborting introspection when IntrospectFailFast is set
+	var failOnce sync.Once
+
+	pool := pools.NewPool(g.introspectWorkers())
+	for i, tableName := range tableList {
+		pool.Wait()
+		go func(i int, tableName string) {
+			defer pool.Done()
+			if errs[i] = ctx.Err(); errs[i] != nil { // aborted by fail fast or canceled Config.Context
+				return
+			}
+
+			db := g.db.Session(&gorm.Session{Context: ctx})
+			confs[i] = g.genModelConfig(tableName, ns.DefaultModelName(db, tableName), opts)
+			confs[i].Context = ctx
+			metas[i], errs[i] = generate.GetQueryStructMeta(db, confs[i])
+			if errs[i] != nil && g.IntrospectFailFast {
+				failOnce.Do(func() {
+					failed = i
+					cancel()
+				})
+			}
+		}(i, tableName)
+	}
+	pool.WaitAll()
+
+	if err := g.Context.Err(); err != nil {
+		g.db.Logger.Error(context.Background(), "generate struct fail: %s", err)
+		panic(fmt.Errorf("generate struct fail: %w", err))
+	}
+	if failed >= 0 {
+		g.db.Logger.Error(context.Background(), "generate struct from table <%s> fail: %s", tableList[failed], errs[failed])
+		panic("generate struct fail")
+	}
+	var count int
+	for i, err := range errs {
+		if err != nil {
+			count++
+			g.db.Logger.Error(context.Background(), "generate struct from table <%s> fail: %s", tableList[i], err)
+		}
+	}
+	if count > 0 {
+		panic(fmt.Sprintf("generate struct of %d tables fail", count))
+	}
+	return confs, metas
+}
+
+// filterTables select tables by table filter options, return the rest options for models.
+// table is generated when it matches any include pattern (or no include option is given) and no exclude pattern
+func filterTables(tableList []string, opts []ModelOpt) (tables []string, modelOpts []ModelOpt) {
+	var includes, excludes []model.TableFilterOpt
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case model.TableFilterOpt:
+			if opt.Exclude {
+				excludes = append(excludes, opt)
+			} else {
+				includes = append(includes, opt)
+			}
+		default:
+			modelOpts = append(modelOpts, opt)
+		}
+	}
+	if len(includes) == 0 && len(excludes) == 0 {
+		return tableList, opts
+	}
+
+	match := func(filters []model.TableFilterOpt, tableName string) bool {
+		for _, f := range filters {
+			if f.Match(tableName) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, tableName := range tableList {
+		if (len(includes) == 0 || match(includes, tableName)) && !match(excludes, tableName) {
+			tables = append(tables, tableName)
+		}
+	}
+	return tables, modelOpts
+}
+
+// prefetchIndexColumns query index column sequences of all tables in one batch, avoid querying table by table
+func (g *Generator) prefetchIndexColumns(tableList []string) *model.IndexColumnCache {
+	if !g.FieldWithIndexTag {
+		return nil
+	}
+	cache := model.NewIndexColumnCache()
+	schemaName := (&model.Config{NameStrategy: model.NameStrategy{SchemaNameOpts: g.dbNameOpts}}).GetSchemaName(g.db)
+	if err := generate.PrefetchIndexColumns(g.Context, g.db, schemaName, tableList, cache); err != nil {
+		g.db.Logger.Warn(context.Background(), "prefetch index column sequences fail: %s", err)
+		return nil
+	}
+	return cache
+}
+
+// prefetchColumnAttrs query ordinal, unsigned and generated attributes of columns of all tables in one batch,
+// avoid querying table by table
+func (g *Generator) prefetchColumnAttrs(tableList []string) *model.ColumnAttrCache {
+	if g.tableInfo != nil && !g.FieldSignable && !g.DetectGeneratedColumns {
+		return nil
+	}
+	cache := model.NewColumnAttrCache()
+	schemaName := (&model.Config{NameStrategy: model.NameStrategy{SchemaNameOpts: g.dbNameOpts}}).GetSchemaName(g.db)
+	if err := generate.PrefetchColumnAttrs(g.Context, g.db, schemaName, tableList, g.DetectGeneratedColumns, cache); err != nil {
+		g.db.Logger.Warn(context.Background(), "prefetch column attributes fail: %s", err)
+		return nil
+	}
+	return cache
+}
+
 // GenerateModelFrom generate model from object
 func (g *Generator) GenerateModelFrom(obj helper.Object) *generate.QueryStructMeta {
 	s, err := generate.GetQueryStructMetaFromObject(obj, g.genModelObjConfig())
@@ -180,22 +457,58 @@ func (g *Generator) genModelConfig(tableName string, modelName string, modelOpts
 		ModelName:      modelName,
 		ImportPkgPaths: g.importPkgPaths,
 		ModelOpts:      modelOpts,
+		TableInfo:      g.tableInfo,
+		Context:        g.Context,
+
+		TableNameInComment: g.TableNameInComment,
+		IndexColumnCache:   g.indexColumnCache,
+		ColumnAttrCache:    g.columnAttrCache,
+		IntrospectRetries:  g.IntrospectRetries,
+		IntrospectBackoff:  g.IntrospectBackoff,
 		NameStrategy: model.NameStrategy{
-			SchemaNameOpts: g.dbNameOpts,
-			TableNameNS:    g.tableNameNS,
-			ModelNameNS:    g.modelNameNS,
-			FileNameNS:     g.fileNameNS,
+			SchemaNameOpts:      g.dbNameOpts,
+			TableNameWithSchema: g.TableNameWithSchema,
+			TableNameSchema:     g.TableNamePrefix,
+			Initialisms:         g.initialisms,
+			Naming:              g.naming,
+			TrimPrefixes:        g.modelNamePrefixes(),
+			TableNameNS:         g.tableNameNS,
+			ModelNameNS:         g.modelNameNS,
+			FileNameNS:          g.fileNameNS,
 		},
 		FieldConfig: model.FieldConfig{
-			DataTypeMap: g.dataTypeMap,
-
-			FieldSignable:     g.FieldSignable,
-			FieldNullable:     g.FieldNullable,
-			FieldCoverable:    g.FieldCoverable,
-			FieldWithIndexTag: g.FieldWithIndexTag,
-			FieldWithTypeTag:  g.FieldWithTypeTag,
-
-			FieldJSONTagNS: g.fieldJSONTagNS,
+			DataTypeMap:    g.dataTypeMap,
+			FieldTypeRules: g.fieldTypeRules,
+			FieldDateType:  g.dateType,
+
+			UseScanTypeDialects: g.UseScanTypeDialects,
+
+			FieldSignable:                g.FieldSignable,
+			FieldNullable:                g.FieldNullable,
+			FieldNullableExceptDefaulted: g.FieldNullableExceptDefaulted,
+			FieldCoverable:               g.FieldCoverable,
+			FieldWithIndexTag:            g.FieldWithIndexTag,
+			FieldWithTypeTag:             g.FieldWithTypeTag,
+			FieldWithIndexSort:           g.WithIndexSort,
+			FieldWithIndexLookups:        g.GenerateIndexLookups,
+			FieldWithComment:             g.FieldWithComment,
+			FieldWithForeignKeyRelations: g.WithForeignKeyRelations,
+			FieldWithReverseRelations:    g.WithReverseRelations,
+			FieldUseDatatypesJSON:        g.UseDatatypesJSON,
+			FieldWithEnumTypes:           g.GenerateEnumTypes,
+			FieldWithCheckConstraints:    g.FieldWithCheckConstraints,
+			FieldArrayLib:                g.PostgresArrayLib,
+			FieldWithDefaultValueTag:     g.FieldWithDefaultValueTag,
+			FieldWithInvisibleIndexes:    g.IncludeInvisibleIndexes,
+			FieldDetectGeneratedColumns:  g.DetectGeneratedColumns,
+			FieldPreciseIntegerTypes:     g.PreciseIntegerTypes,
+			FieldPreciseTinyIntAsBool:    g.PreciseTinyIntAsBool,
+			FieldSoftDeleteNames:         g.softDeleteFields(),
+			FieldEmbedBaseModel:          g.EmbedBaseModel,
+			FieldJSONTypes:               g.jsonTypes[tableName],
+
+			FieldJSONTagNS:    g.fieldJSONTagNS,
+			FieldTagProviders: g.tagProviders,
 		},
 	}
 }
@@ -232,7 +545,15 @@ func (g *Generator) ApplyInterface(fc interface{}, models ...interface{}) {
 		g.db.Logger.Error(context.Background(), "check struct fail: %v", err)
 		panic("check struct fail")
 	}
-	g.apply(fc, structs)
+	queryStructs := structs[:0:0]
+	for _, s := range structs {
+		if s.ModelOnly {
+			g.info(fmt.Sprintf("skip query code of model only struct <%s>", s.ModelStructName))
+			continue
+		}
+		queryStructs = append(queryStructs, s)
+	}
+	g.apply(fc, queryStructs)
 }
 
 func (g *Generator) apply(fc interface{}, structs []*generate.QueryStructMeta) {
@@ -248,7 +569,11 @@ func (g *Generator) apply(fc interface{}, structs []*generate.QueryStructMeta) {
 		g.db.Logger.Error(context.Background(), "parser interface file fail: %s", err)
 		panic("parser interface file fail")
 	}
+	g.applyInterfaceSet(readInterface, structs)
+}
 
+// applyInterfaceSet build methods of parsed interfaces on structs
+func (g *Generator) applyInterfaceSet(readInterface *parser.InterfaceSet, structs []*generate.QueryStructMeta) {
 	for _, interfaceStructMeta := range structs {
 		if g.judgeMode(WithoutContext) {
 			interfaceStructMeta.ReviseFieldNameFor(model.GormKeywords)
@@ -266,12 +591,88 @@ func (g *Generator) apply(fc interface{}, structs []*generate.QueryStructMeta) {
 			g.db.Logger.Error(context.Background(), "check interface fail: %v", err)
 			panic("check interface fail")
 		}
+		if g.diyMethodNS != nil {
+			if err = generate.RenameDIYMethod(functions, interfaceStructMeta, genInfo.Interfaces, g.diyMethodNS); err != nil {
+				g.db.Logger.Error(context.Background(), "rename interface method fail: %v", err)
+				panic("rename interface method fail")
+			}
+		}
 		genInfo.appendMethods(functions)
 	}
 }
 
+// GeneratedFile generated file's path and formatted content
+type GeneratedFile struct {
+	Path    string
+	Content []byte
+}
+
+// plannedFiles generated files collected by Plan
+type plannedFiles struct {
+	mu    sync.Mutex
+	files []GeneratedFile
+}
+
+func (p *plannedFiles) add(path string, content []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files = append(p.files, GeneratedFile{Path: path, Content: content})
+}
+
+// Plan render all model and query files without writing them to disk, files are sorted by path
+func (g *Generator) Plan() ([]GeneratedFile, error) {
+	g.plannedFiles = &plannedFiles{}
+	defer func() { g.plannedFiles = nil }()
+
+	if err := g.generateModelFile(); err != nil {
+		return nil, fmt.Errorf("generate model struct fail: %w", err)
+	}
+	if err := g.generateQueryFile(); err != nil {
+		return nil, fmt.Errorf("generate query code fail: %w", err)
+	}
+
+	files := g.plannedFiles.files
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// FileChange file would be created or changed by Execute
+type FileChange struct {
+	Path    string
+	Created bool // file does not exist yet
+	Changed bool // content differs from existing file
+}
+
+// ExecuteDryRun render all files in memory and compare them with existing files without writing,
+// only files would be created or changed are returned, so an unchanged schema reports no change
+func (g *Generator) ExecuteDryRun() ([]FileChange, error) {
+	files, err := g.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FileChange
+	for _, f := range files {
+		content, err := os.ReadFile(f.Path)
+		switch {
+		case os.IsNotExist(err):
+			changes = append(changes, FileChange{Path: f.Path, Created: true, Changed: true})
+		case err != nil:
+			return nil, fmt.Errorf("read generated file %s fail: %w", f.Path, err)
+		case !bytes.Equal(content, f.Content):
+			changes = append(changes, FileChange{Path: f.Path, Changed: true})
+		}
+	}
+	return changes, nil
+}
+
 // Execute generate code to output path
 func (g *Generator) Execute() {
+	if g.DryRun {
+		g.dryRun()
+		return
+	}
+
 	g.info("Start generating code.")
 
 	if err := g.generateModelFile(); err != nil {
@@ -287,6 +688,26 @@ func (g *Generator) Execute() {
 	g.info("Generate code done.")
 }
 
+// dryRun report files would be generated by Execute
+func (g *Generator) dryRun() {
+	g.info("Start dry run, no file will be written.")
+
+	files, err := g.Plan()
+	if err != nil {
+		g.db.Logger.Error(context.Background(), "plan generated files fail: %s", err)
+		panic("plan generated files fail")
+	}
+	for _, f := range files {
+		action := "create"
+		if _, err := os.Stat(f.Path); err == nil {
+			action = "overwrite"
+		}
+		g.info(fmt.Sprintf("[dry run] %s %s (%d bytes)", action, f.Path, len(f.Content)))
+	}
+
+	g.info(fmt.Sprintf("Dry run done, %d files would be generated.", len(files)))
+}
+
 // info logger
 func (g *Generator) info(logInfos ...string) {
 	for _, l := range logInfos {
@@ -295,26 +716,46 @@ func (g *Generator) info(logInfos ...string) {
 	}
 }
 
+// queryMethodData data of Query methods and their unit test, db passed to Use is wrapped with resolver clause once
+type queryMethodData struct {
+	*Generator
+	DBResolver string // name of dbresolver resolver, see WithDBResolverClause
+}
+
 // generateQueryFile generate query code and save to file
 func (g *Generator) generateQueryFile() (err error) {
 	if len(g.Data) == 0 {
 		return nil
 	}
+	if err = g.Context.Err(); err != nil {
+		return err
+	}
+	if g.WithUpsert {
+		if err = g.checkUpsertDialect(); err != nil {
+			return err
+		}
+	}
+	if g.ContextFirstArg && (g.judgeMode(WithGeneric) || g.WithUnitTest) {
+		return fmt.Errorf("ContextFirstArg is not supported with WithGeneric mode or WithUnitTest")
+	}
 
-	if err = os.MkdirAll(g.OutPath, os.ModePerm); err != nil {
+	if err = g.mkdirAll(g.OutPath); err != nil {
 		return fmt.Errorf("make dir outpath(%s) fail: %s", g.OutPath, err)
 	}
 
-	errChan := make(chan error)
-	pool := pools.NewPool(concurrent)
-	// generate query code for all struct
-	for _, info := range g.Data {
-		pool.Wait()
-		go func(info *genInfo) {
-			defer pool.Done()
-			err := g.generateSingleQueryFile(info)
-			if err != nil {
-				errChan <- err
+	importPkgs := importList.Add(g.importPkgPaths...)
+	var queryCode bytes.Buffer // query code of all structs when SingleQueryFile is enabled
+	if g.SingleQueryFile {
+		names := make([]string, 0, len(g.Data))
+		for name := range g.Data {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			info := g.Data[name]
+			importPkgs = importPkgs.Add(g.queryImportPkgPaths(info)...)
+			if err = g.renderQueryCode(&queryCode, info); err != nil {
+				return err
 			}
 
 			if g.WithUnitTest {
@@ -323,19 +764,40 @@ func (g *Generator) generateQueryFile() (err error) {
 					g.db.Logger.Error(context.Background(), "generate unit test fail: %s", err)
 				}
 			}
-		}(info)
-	}
-	select {
-	case err = <-errChan:
-		return err
-	case <-pool.AsyncWaitAll():
+		}
+	} else {
+		errChan := make(chan error, len(g.Data))
+		pool := pools.NewPool(concurrent)
+		// generate query code for all struct
+		for _, info := range g.Data {
+			pool.Wait()
+			go func(info *genInfo) {
+				defer pool.Done()
+				err := g.generateSingleQueryFile(info)
+				if err != nil {
+					errChan <- err
+				}
+
+				if g.WithUnitTest {
+					err = g.generateQueryUnitTestFile(info)
+					if err != nil { // do not panic
+						g.db.Logger.Error(context.Background(), "generate unit test fail: %s", err)
+					}
+				}
+			}(info)
+		}
+		select {
+		case err = <-errChan:
+			return err
+		case <-pool.AsyncWaitAll():
+		}
 	}
 
 	// generate query file
 	var buf bytes.Buffer
 	err = render(tmpl.Header, &buf, map[string]interface{}{
 		"Package":        g.queryPkgName,
-		"ImportPkgPaths": importList.Add(g.importPkgPaths...).Paths(),
+		"ImportPkgPaths": importPkgs.Paths(),
 	})
 	if err != nil {
 		return err
@@ -347,10 +809,11 @@ func (g *Generator) generateQueryFile() (err error) {
 			return err
 		}
 	}
-	err = render(tmpl.QueryMethod, &buf, g)
+	err = render(tmpl.QueryMethod, &buf, queryMethodData{Generator: g, DBResolver: g.dbResolver})
 	if err != nil {
 		return err
 	}
+	buf.Write(queryCode.Bytes())
 
 	err = g.output(g.OutFile, buf.Bytes())
 	if err != nil {
@@ -358,6 +821,10 @@ func (g *Generator) generateQueryFile() (err error) {
 	}
 	g.info("generate query file: " + g.OutFile)
 
+	if err = g.generateScopesFile(); err != nil {
+		return err
+	}
+
 	// generate query unit test file
 	if g.WithUnitTest {
 		buf.Reset()
@@ -374,7 +841,7 @@ func (g *Generator) generateQueryFile() (err error) {
 		if err != nil {
 			return err
 		}
-		err = render(tmpl.QueryMethodTest, &buf, g)
+		err = render(tmpl.QueryMethodTest, &buf, queryMethodData{Generator: g, DBResolver: g.dbResolver})
 		if err != nil {
 			g.db.Logger.Error(context.Background(), "generate query unit test fail: %s", err)
 			return nil
@@ -395,21 +862,63 @@ func (g *Generator) generateQueryFile() (err error) {
 func (g *Generator) generateSingleQueryFile(data *genInfo) (err error) {
 	var buf bytes.Buffer
 
-	structPkgPath := data.StructInfo.PkgPath
-	if structPkgPath == "" {
-		structPkgPath = g.modelPkgPath
-	}
 	err = render(tmpl.Header, &buf, map[string]interface{}{
 		"Package":        g.queryPkgName,
-		"ImportPkgPaths": importList.Add(g.importPkgPaths...).Add(structPkgPath).Add(getImportPkgPaths(data)...).Paths(),
+		"ImportPkgPaths": importList.Add(g.importPkgPaths...).Add(g.queryImportPkgPaths(data)...).Paths(),
 	})
 	if err != nil {
 		return err
 	}
+	if err = g.renderQueryCode(&buf, data); err != nil {
+		return err
+	}
+
+	defer g.info(fmt.Sprintf("generate query file: %s%s%s.gen.go", g.OutPath, string(os.PathSeparator), data.FileName))
+	return g.output(fmt.Sprintf("%s%s%s.gen.go", g.OutPath, string(os.PathSeparator), data.FileName), buf.Bytes())
+}
+
+// queryImportPkgPaths import paths used by query code of struct
+func (g *Generator) queryImportPkgPaths(data *genInfo) []string {
+	structPkgPath := data.StructInfo.PkgPath
+	if structPkgPath == "" {
+		structPkgPath = g.modelPkgPath
+	}
+	return append([]string{structPkgPath, ""}, getImportPkgPaths(data)...) // model import in its own group
+}
 
+// renderQueryCode render query code of struct without file header
+func (g *Generator) renderQueryCode(buf *bytes.Buffer, data *genInfo) (err error) {
 	data.QueryStructMeta = data.QueryStructMeta.
 		IfaceMode(g.judgeMode(WithQueryInterface) || g.judgeMode(WithGeneric)).
-		GenericMode(g.judgeMode(WithGeneric))
+		GenericMode(g.judgeMode(WithGeneric)).
+		ContextMode(g.ContextFirstArg)
+	data.DBResolver = g.dbResolver
+	data.BatchCreateWithAssociations = g.BatchCreateWithAssociations
+	data.AssertQueryInterface = g.GenerateQueryInterfaces
+	if g.RequirePrimaryKey && data.NoPrimaryKey {
+		if !g.judgeMode(WithQueryInterface) || g.judgeMode(WithGeneric) {
+			// plain query embeds gen.DO and generic query gen.IGenericsDo, both expose Save, FirstOrCreate and Delete
+			return fmt.Errorf("table %s has no primary key: Save, FirstOrCreate and Delete of plain and generic query can't be omitted", data.TableName)
+		}
+		data.WithoutPrimaryKeyMethods = true
+		g.info(fmt.Sprintf("table %s has no primary key: Save and FirstOrCreate are not generated and Delete by models is rejected", data.TableName))
+	}
+	if data.ReadOnly && (!g.judgeMode(WithQueryInterface) || g.judgeMode(WithGeneric)) {
+		// plain query embeds gen.DO and generic query gen.IGenericsDo, both expose create, update and delete methods
+		return fmt.Errorf("view %s is read-only only in WithQueryInterface mode: create, update and delete methods of plain and generic query can't be omitted", data.TableName)
+	}
+	for _, method := range data.Interfaces {
+		if g.ContextFirstArg && method.HasParam("ctx") {
+			return fmt.Errorf("param ctx of interface method %s.%s conflicts with the ctx added by ContextFirstArg", method.InterfaceName, method.MethodName)
+		}
+		method.ContextFirstArg = g.ContextFirstArg
+	}
+	if g.QueryTemplate != "" {
+		if option := g.queryTemplateConflict(data); option != "" {
+			return fmt.Errorf("query of %s: %s can't be generated with QueryTemplate replacing built-in query code", data.ModelStructName, option)
+		}
+		return render(g.QueryTemplate, buf, data.QueryStructMeta)
+	}
 
 	structTmpl := tmpl.TableQueryStructWithContext
 	crudTmpl := tmpl.CRUDMethod
@@ -428,11 +937,11 @@ func (g *Generator) generateSingleQueryFile(data *genInfo) (err error) {
 	} else {
 		structTmpl += tmpl.DefineMethodStruct
 	}
-	err = render(structTmpl, &buf, data.QueryStructMeta)
+	err = render(structTmpl, buf, data.QueryStructMeta)
 	if err != nil {
 		return err
 	}
-	err = render(ifaceTmpl, &buf, data)
+	err = render(ifaceTmpl, buf, data)
 	if err != nil {
 		return err
 	}
@@ -443,23 +952,122 @@ func (g *Generator) generateSingleQueryFile(data *genInfo) (err error) {
 			// which indicates SkipImpl is true.
 			continue
 		}
-		err = render(tmpl.DIYMethod, &buf, method)
+		err = render(tmpl.DIYMethod, buf, method)
 		if err != nil {
 			return err
 		}
 	}
 
-	err = render(crudTmpl, &buf, data.QueryStructMeta)
+	err = render(crudTmpl, buf, data.QueryStructMeta)
 	if err != nil {
 		return err
 	}
 
-	defer g.info(fmt.Sprintf("generate query file: %s%s%s.gen.go", g.OutPath, string(os.PathSeparator), data.FileName))
-	return g.output(fmt.Sprintf("%s%s%s.gen.go", g.OutPath, string(os.PathSeparator), data.FileName), buf.Bytes())
+	if data.WithUpsert && !data.ReadOnly {
+		err = render(tmpl.UpsertMethod, buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+	if data.DefaultBatchSize > 0 && !data.ReadOnly {
+		err = render(tmpl.BulkInsertMethod, buf, data)
+		if err != nil {
+			return err
+		}
+	}
+	if data.OptimisticLock != nil {
+		err = render(tmpl.OptimisticLockMethod, buf, data)
+		if err != nil {
+			return err
+		}
+	}
+	if data.WithIndexLookups {
+		err = render(tmpl.IndexLookupMethod, buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+	if len(data.JSONPathConds) > 0 {
+		err = render(tmpl.JSONPathMethod, buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+	if len(data.HasManyRelations()) > 0 {
+		err = render(tmpl.AssociationMethod, buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if g.WithQueryFilter {
+		err = render(tmpl.TableQueryFilter, buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+	if g.WithOrderBy {
+		data.OrderByCaseInsensitive = g.OrderByCaseInsensitive
+		err = render(tmpl.TableOrderBy, buf, data.QueryStructMeta)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryTemplateConflict option whose methods are generated by built-in query code only, empty when there is none
+func (g *Generator) queryTemplateConflict(data *genInfo) string {
+	switch {
+	case len(data.Interfaces) > 0:
+		return "methods of ApplyInterface"
+	case g.WithUpsert:
+		return "WithUpsert"
+	case g.DefaultBatchSize > 0:
+		return "DefaultBatchSize"
+	case data.OptimisticLock != nil:
+		return "OptimisticLockField"
+	case g.GenerateIndexLookups:
+		return "GenerateIndexLookups"
+	case len(data.JSONPathConds) > 0:
+		return "FieldJSONPath"
+	case g.WithQueryFilter:
+		return "WithQueryFilter"
+	case g.WithOrderBy:
+		return "WithOrderBy"
+	}
+	return ""
+}
+
+// minSQLiteUpsertVersion first sqlite version supporting ON CONFLICT DO UPDATE
+var minSQLiteUpsertVersion = []int{3, 24, 0}
+
+// checkUpsertDialect check connected db supports upsert, generated UpsertByColumns relies on it
+func (g *Generator) checkUpsertDialect() error {
+	switch dialect := g.db.Dialector.Name(); dialect {
+	case "dummy": // default dialector when UseDB is not called
+		return fmt.Errorf("generate upsert method fail: no db connected to detect dialect, call UseDB first")
+	case "mysql", "postgres":
+		return nil
+	case "sqlite":
+		var version string
+		if err := g.db.WithContext(g.Context).Raw("SELECT sqlite_version()").Scan(&version).Error; err != nil {
+			return fmt.Errorf("generate upsert method fail: query sqlite version fail: %w", err)
+		}
+		if generate.CompareVersion(version, minSQLiteUpsertVersion) < 0 {
+			return fmt.Errorf("generate upsert method fail: sqlite %s does not support ON CONFLICT, 3.24.0 or later is required", version)
+		}
+		return nil
+	default:
+		return fmt.Errorf("generate upsert method fail: unsupported dialect %q, only mysql, postgres and sqlite are supported", dialect)
+	}
 }
 
 // generateQueryUnitTestFile generate unit test file for query
 func (g *Generator) generateQueryUnitTestFile(data *genInfo) (err error) {
+	if data.ReadOnly || data.WithoutPrimaryKeyMethods { // unit test creates, saves and deletes records
+		return nil
+	}
 	var buf bytes.Buffer
 
 	structPkgPath := data.StructInfo.PkgPath
@@ -490,22 +1098,66 @@ func (g *Generator) generateQueryUnitTestFile(data *genInfo) (err error) {
 	return g.output(fmt.Sprintf("%s%s%s.gen_test.go", g.OutPath, string(os.PathSeparator), data.FileName), buf.Bytes())
 }
 
+// fillReverseRelations add has-one/has-many fields to models referenced by other models' foreign keys
+func (g *Generator) fillReverseRelations() {
+	if !g.WithReverseRelations || g.reverseRelationsFilled {
+		return
+	}
+	g.reverseRelationsFilled = true
+	if g.splitsModelPkgs() {
+		g.db.Logger.Warn(context.Background(), "reverse relations are ignored when models are generated into multiple packages, they cause import cycle between model packages")
+		return
+	}
+
+	metas := make([]*generate.QueryStructMeta, 0, len(g.models))
+	for _, meta := range g.models {
+		if meta != nil {
+			metas = append(metas, meta)
+		}
+	}
+	generate.FillReverseRelations(g.db, metas)
+}
+
+// pruneForeignKeyRelations remove belongs-to relation fields referencing tables which are not generated
+func (g *Generator) pruneForeignKeyRelations() {
+	if !g.WithForeignKeyRelations {
+		return
+	}
+	metas := make([]*generate.QueryStructMeta, 0, len(g.models))
+	for _, meta := range g.models {
+		if meta != nil {
+			metas = append(metas, meta)
+		}
+	}
+	generate.PruneForeignKeyRelations(g.db, metas)
+}
+
 // generateModelFile generate model structures and save to file
 func (g *Generator) generateModelFile() error {
 	if len(g.models) == 0 {
 		return nil
 	}
+	if err := g.Context.Err(); err != nil {
+		return err
+	}
+	g.fillReverseRelations()
+	g.pruneForeignKeyRelations()
 
 	modelOutPath, err := g.getModelOutputPath()
 	if err != nil {
 		return err
 	}
 
-	if err = os.MkdirAll(modelOutPath, os.ModePerm); err != nil {
+	if err = g.mkdirAll(modelOutPath); err != nil {
 		return fmt.Errorf("create model pkg path(%s) fail: %s", modelOutPath, err)
 	}
+	if g.splitsModelPkgs() {
+		if err = g.splitModelPkgs(modelOutPath); err != nil {
+			return err
+		}
+	}
 
-	errChan := make(chan error)
+	errChan := make(chan error, len(g.models))
 	pool := pools.NewPool(concurrent)
 	for _, data := range g.models {
 		if data == nil || !data.Generated {
@@ -515,8 +1167,18 @@ func (g *Generator) generateModelFile() error {
 		go func(data *generate.QueryStructMeta) {
 			defer pool.Done()
 
+			if g.WithSchemaFingerprint && data.Source == model.Table {
+				data.SchemaFingerprint = schemaFingerprint(data.Columns)
+			}
+			data.WithColumnNames = g.WithColumnNames
+			data.ModelInterface = g.modelInterface
+
+			modelTmpl := tmpl.Model
+			if g.ModelTemplate != "" {
+				modelTmpl = g.ModelTemplate
+			}
 			var buf bytes.Buffer
-			err := render(tmpl.Model, &buf, data)
+			err := render(modelTmpl, &buf, data)
 			if err != nil {
 				errChan <- err
 				return
@@ -530,7 +1192,7 @@ func (g *Generator) generateModelFile() error {
 				}
 			}
 
-			modelFile := modelOutPath + data.FileName + ".gen.go"
+			modelFile := g.modelFilePath(modelOutPath, data)
 			err = g.output(modelFile, buf.Bytes())
 			if err != nil {
 				errChan <- err
@@ -544,11 +1206,154 @@ func (g *Generator) generateModelFile() error {
 	case err = <-errChan:
 		return err
 	case <-pool.AsyncWaitAll():
-		g.fillModelPkgPath(modelOutPath)
+		if !g.splitsModelPkgs() { // model pkg path is resolved by splitModelPkgs
+			g.fillModelPkgPath(modelOutPath)
+		}
+	}
+	return nil
+}
+
+// splitModelPkgs move table models into sub packages under modelOutPath, e.g. model/user or model/billing,
+// relation fields referencing models in other packages are qualified with package name and imported
+func (g *Generator) splitModelPkgs(modelOutPath string) error {
+	modelPkgPath, err := getPkgPath(modelOutPath)
+	if err != nil {
+		return fmt.Errorf("resolve model pkg path fail: %w", err)
+	}
+	g.Config.modelPkgPath = modelPkgPath
+
+	dirs := make(map[string]string, len(g.models)) // model struct name -> sub package dir, empty for model path
+	for _, data := range g.models {
+		if data == nil || !data.Generated || data.Source != model.Table {
+			continue
+		}
+		dir := g.modelSubPkg(data)
+		dirs[data.ModelStructName] = dir
+		if dir == "" {
+			continue
+		}
+		if err = g.mkdirAll(filepath.Join(modelOutPath, filepath.FromSlash(dir))); err != nil {
+			return fmt.Errorf("create model pkg path(%s) fail: %s", dir, err)
+		}
+		data.StructInfo.Package = modelPkgName(path.Base(dir))
+		data.StructInfo.PkgPath = path.Join(modelPkgPath, dir)
+	}
+
+	for _, data := range g.models {
+		if data == nil {
+			continue
+		}
+		dir, ok := dirs[data.ModelStructName]
+		if !ok {
+			continue
+		}
+		data.ImportPkgPaths = data.ImportPkgPaths[:len(data.ImportPkgPaths):len(data.ImportPkgPaths)] // avoid appending to shared slice
+		for _, f := range data.Fields {
+			if f.Relation == nil {
+				continue
+			}
+			typ := strings.TrimLeft(f.Type, "*[]")
+			relDir, ok := dirs[typ]
+			if !ok {
+				continue
+			}
+			pkg := g.models[typ].StructInfo.Package
+			f.Relation = field.NewRelationWithType(f.Relation.Relationship(), f.Relation.Name(), pkg+"."+typ)
+			if relDir == dir {
+				continue
+			}
+			f.Type = strings.TrimSuffix(f.Type, typ) + pkg + "." + typ
+			if importPath := strconv.Quote(path.Join(modelPkgPath, relDir)); !utils.Contains(data.ImportPkgPaths, importPath) {
+				data.ImportPkgPaths = append(data.ImportPkgPaths, importPath)
+			}
+		}
 	}
 	return nil
 }
 
+// splitsModelPkgs whether models are generated into sub packages of model path
+func (g *Generator) splitsModelPkgs() bool {
+	return g.ModelPkgPerTable || len(g.PackageByPrefix) > 0
+}
+
+// modelSubPkg sub package dir of model under model path by PackageByPrefix and ModelPkgPerTable, empty for model path
+func (g *Generator) modelSubPkg(data *generate.QueryStructMeta) string {
+	if data.Source != model.Table {
+		return ""
+	}
+	dir := strings.Trim(filepath.ToSlash(g.PackageByPrefix[model.LongestPrefix(data.TableName, g.packagePrefixes())]), "/")
+	if dir != "" {
+		dir = path.Clean(dir)
+	}
+	if g.ModelPkgPerTable {
+		dir = path.Join(dir, modelPkgName(data.FileName))
+	}
+	return dir
+}
+
+// modelNamePrefixes table name prefixes trimmed from model struct names
+func (g *Generator) modelNamePrefixes() []string {
+	if !g.StripPackagePrefix {
+		return nil
+	}
+	return g.packagePrefixes()
+}
+
+// packagePrefixes table name prefixes of PackageByPrefix
+func (g *Generator) packagePrefixes() []string {
+	prefixes := make([]string, 0, len(g.PackageByPrefix))
+	for prefix := range g.PackageByPrefix {
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes
+}
+
+// modelPkgName package name of table model, e.g. order_items => orderitems
+func modelPkgName(fileName string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.ToLower(fileName))
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "model" + name
+	}
+	return name
+}
+
+// getPkgPath import path of dir, resolved by module path in go.mod of dir or its parents
+func getPkgPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		content, err := os.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			rel, err := filepath.Rel(d, dir)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(modfile.ModulePath(content), filepath.ToSlash(rel)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if filepath.Dir(d) == d {
+			return "", fmt.Errorf("go.mod not found for %s", dir)
+		}
+	}
+}
+
+// modelFilePath path of model file generated from data
+func (g *Generator) modelFilePath(modelOutPath string, data *generate.QueryStructMeta) string {
+	if g.splitsModelPkgs() {
+		return filepath.Join(modelOutPath, filepath.FromSlash(g.modelSubPkg(data)), data.FileName+".gen.go")
+	}
+	return modelOutPath + data.FileName + ".gen.go"
+}
+
 func (g *Generator) getModelOutputPath() (outPath string, err error) {
 	if strings.Contains(g.ModelPkgPath, string(os.PathSeparator)) {
 		outPath, err = filepath.Abs(g.ModelPkgPath)
@@ -562,6 +1367,15 @@ func (g *Generator) getModelOutputPath() (outPath string, err error) {
 }
 
 func (g *Generator) fillModelPkgPath(filePath string) {
+	if g.fileSystem != nil || g.plannedFiles != nil { // model path may not exist on disk, resolve it by go.mod above
+		pkgPath, err := getPkgPath(filePath)
+		if err != nil {
+			g.db.Logger.Warn(context.Background(), "parse model pkg path fail: %s", err)
+			return
+		}
+		g.Config.modelPkgPath = pkgPath
+		return
+	}
 	pkgs, err := packages.Load(&packages.Config{
 		Mode: packages.NeedName,
 		Dir:  filePath,
@@ -579,6 +1393,12 @@ func (g *Generator) fillModelPkgPath(filePath string) {
 
 // output format and output
 func (g *Generator) output(fileName string, content []byte) error {
+	if len(g.BuildTags) > 0 && strings.HasSuffix(fileName, ".go") {
+		var err error
+		if content, err = withBuildConstraint(content, g.BuildTags); err != nil {
+			return err
+		}
+	}
 	result, err := imports.Process(fileName, content, nil)
 	if err != nil {
 		lines := strings.Split(string(content), "\n")
@@ -596,13 +1416,86 @@ func (g *Generator) output(fileName string, content []byte) error {
 		}
 		return fmt.Errorf("cannot format file: %w", err)
 	}
-	return os.WriteFile(fileName, result, 0640)
+	if g.fileModifier != nil {
+		if result, err = g.fileModifier(fileName, result); err != nil {
+			return fmt.Errorf("modify file %s fail: %w", fileName, err)
+		}
+		if result, err = format.Source(result); err != nil {
+			return fmt.Errorf("cannot format file %s returned by file modifier: %w", fileName, err)
+		}
+	}
+	if g.plannedFiles != nil {
+		g.plannedFiles.add(fileName, result)
+		return nil
+	}
+	return g.writeFile(fileName, result)
+}
+
+// writeFile write file to file system set by WithFileSystem, or to disk
+func (g *Generator) writeFile(fileName string, content []byte) error {
+	if g.fileSystem != nil {
+		return g.fileSystem.WriteFile(fileName, content)
+	}
+	return os.WriteFile(fileName, content, 0640)
+}
+
+// generatedHeader DO NOT EDIT header of generated file, see https://go.dev/s/generatedcode
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// withBuildConstraint insert //go:build line of tags below DO NOT EDIT header, so that header stays the first line
+func withBuildConstraint(content []byte, tags []string) ([]byte, error) {
+	var expr constraint.Expr
+	for _, tag := range tags {
+		x, err := constraint.Parse("//go:build " + tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid build tag %q: %w", tag, err)
+		}
+		if expr == nil {
+			expr = x
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: x}
+		}
+	}
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	i := 0
+	for i < len(lines) && len(bytes.TrimSpace(lines[i])) == 0 {
+		i++
+	}
+	for i < len(lines) && generatedHeader.Match(bytes.TrimSpace(lines[i])) {
+		i++
+	}
+	var buf bytes.Buffer
+	buf.Write(bytes.Join(lines[:i], nil))
+	buf.WriteString("\n//go:build " + expr.String() + "\n\n")
+	buf.Write(bytes.Join(lines[i:], nil))
+	return buf.Bytes(), nil
+}
+
+// mkdirAll create directory, skipped when planning generated files or writing them to file system set by WithFileSystem
+func (g *Generator) mkdirAll(path string) error {
+	if g.plannedFiles != nil || g.fileSystem != nil {
+		return nil
+	}
+	return os.MkdirAll(path, os.ModePerm)
 }
 
 func (g *Generator) pushQueryStructMeta(meta *generate.QueryStructMeta) (*genInfo, error) {
 	structName := meta.ModelStructName
 	if g.Data[structName] == nil {
-		g.Data[structName] = &genInfo{QueryStructMeta: meta}
+		g.Data[structName] = &genInfo{
+			QueryStructMeta:  meta,
+			WithUpsert:       g.WithUpsert,
+			DefaultBatchSize: g.DefaultBatchSize,
+			WithIndexLookups: g.GenerateIndexLookups,
+		}
+		if !meta.ReadOnly {
+			lock, err := meta.OptimisticLock(g.OptimisticLockField)
+			if err != nil { // e.g. optimisticlock.Version of gorm plugin, which guards updates itself
+				g.db.Logger.Warn(context.Background(), "%s, UpdateWithVersion is not generated", err)
+			}
+			g.Data[structName].OptimisticLock = lock
+		}
 	}
 	if g.Data[structName].Source != meta.Source {
 		return nil, fmt.Errorf("cannot generate struct with the same name from different source:%s.%s and %s.%s",
@@ -1,17 +1,41 @@
 package gen
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/migrator"
 	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
 	"gorm.io/gorm/utils/tests"
 
 	"gorm.io/gen/field"
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	genparser "gorm.io/gen/internal/parser"
 )
 
 func TestConfig(t *testing.T) {
@@ -27,6 +51,2631 @@ func TestConfig(t *testing.T) {
 	}
 }
 
+func TestGenerator_Plan(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "query")
+	g := NewGenerator(Config{OutPath: outPath, DryRun: true})
+	g.ApplyBasic(User{})
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	expects := []string{filepath.Join(outPath, "gen.go"), filepath.Join(outPath, "users_info.gen.go")}
+	if len(files) != len(expects) {
+		t.Fatalf("expect %d files, got %d", len(expects), len(files))
+	}
+	for i, f := range files {
+		if f.Path != expects[i] || len(f.Content) == 0 {
+			t.Errorf("expect file %s with content, got %s (%d bytes)", expects[i], f.Path, len(f.Content))
+		}
+	}
+
+	g.Execute()
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("dry run should not write to %s, got err: %v", outPath, err)
+	}
+}
+
+// memFileSystem in-memory file system of generated files
+type memFileSystem struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (fs *memFileSystem) WriteFile(path string, data []byte) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.files[path] = data
+	return nil
+}
+
+func TestGenerator_WithFileSystem(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n"), 0640); err != nil {
+		t.Fatalf("write go.mod fail: %s", err)
+	}
+	outPath := filepath.Join(dir, "virtual", "query")
+	fs := &memFileSystem{files: make(map[string][]byte)}
+	cfg := Config{OutPath: outPath}
+	cfg.WithFileSystem(fs)
+	g := NewGenerator(cfg)
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"))
+	g.Execute()
+
+	modelPath := filepath.Join(filepath.Dir(outPath), "model")
+	expects := map[string]string{
+		filepath.Join(outPath, "gen.go"):         "func Use(db *gorm.DB, opts ...gen.DOOption) *Query {",
+		filepath.Join(outPath, "users.gen.go"):   `"example.com/shop/virtual/model"`,
+		filepath.Join(modelPath, "users.gen.go"): "type User struct {",
+	}
+	if len(fs.files) != len(expects) {
+		t.Errorf("expect %d files written to file system, got %d", len(expects), len(fs.files))
+	}
+	for path, expect := range expects {
+		if content, ok := fs.files[path]; !ok || !bytes.Contains(content, []byte(expect)) {
+			t.Errorf("expect %s in %s of file system, got:\n%s", expect, path, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Dir(outPath)); !os.IsNotExist(err) {
+		t.Errorf("expect nothing written to disk, got err: %v", err)
+	}
+}
+
+func TestGenerator_PlanModelPkgPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n"), 0640); err != nil {
+		t.Fatalf("write go.mod fail: %s", err)
+	}
+	outPath := filepath.Join(dir, "dal", "query")
+	g := NewGenerator(Config{OutPath: outPath})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var content []byte
+	for _, f := range files {
+		if f.Path == filepath.Join(outPath, "users.gen.go") {
+			content = f.Content
+		}
+	}
+	if !bytes.Contains(content, []byte(`"example.com/shop/dal/model"`)) {
+		t.Errorf("expect planned query imports model package example.com/shop/dal/model, got:\n%s", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dal")); !os.IsNotExist(err) {
+		t.Errorf("expect nothing written to disk, got err: %v", err)
+	}
+}
+
+func TestGenerator_ExecuteDryRun(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "query")
+	g := NewGenerator(Config{OutPath: outPath})
+	g.ApplyBasic(User{})
+
+	changes, err := g.ExecuteDryRun()
+	if err != nil {
+		t.Fatalf("dry run fail: %s", err)
+	}
+	if len(changes) != 2 || !changes[0].Created || !changes[1].Created {
+		t.Fatalf("expect 2 created files before execute, got %+v", changes)
+	}
+
+	g.Execute()
+	for i := 0; i < 2; i++ { // generated content is stable across runs
+		if changes, err := g.ExecuteDryRun(); err != nil || len(changes) != 0 {
+			t.Fatalf("expect no change after execute, got %+v, err: %v", changes, err)
+		}
+	}
+
+	genFile := filepath.Join(outPath, "gen.go")
+	if err := os.WriteFile(genFile, []byte("package query\n"), 0640); err != nil {
+		t.Fatalf("modify generated file fail: %s", err)
+	}
+	changes, err = g.ExecuteDryRun()
+	if err != nil {
+		t.Fatalf("dry run fail: %s", err)
+	}
+	if expect := []FileChange{{Path: genFile, Changed: true}}; !reflect.DeepEqual(changes, expect) {
+		t.Errorf("expect changes %+v, got %+v", expect, changes)
+	}
+}
+
+// shopTableInfo table metadata of users and orders, orders.user_id references users.id
+type shopTableInfo struct{}
+
+func (shopTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	column := func(name string) *Column {
+		return &Column{ColumnType: migrator.ColumnType{
+			NameValue:     sql.NullString{String: name, Valid: true},
+			DataTypeValue: sql.NullString{String: "bigint", Valid: true},
+			NullableValue: sql.NullBool{Bool: false, Valid: true},
+			ScanTypeValue: reflect.TypeOf(int64(0)),
+		}, TableName: tableName}
+	}
+	if tableName == "orders" {
+		return []*Column{column("id"), column("user_id")}, nil
+	}
+	return []*Column{column("id")}, nil
+}
+
+func (shopTableInfo) GetTableIndex(_ string, tableName string) ([]gorm.Index, error) {
+	if tableName == "orders" {
+		return []gorm.Index{&migrator.Index{TableName: "orders", NameValue: "idx_user_order", ColumnList: []string{"user_id", "id"}}}, nil
+	}
+	return nil, nil
+}
+
+func (shopTableInfo) GetTableForeignKeys(_ string, tableName string) ([]*model.ForeignKey, error) {
+	if tableName == "orders" {
+		return []*model.ForeignKey{{Name: "fk_user", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}}}, nil
+	}
+	return nil, nil
+}
+
+// pkTableInfo table metadata of logs without primary key, members with single primary key and user_roles with composite primary key
+type pkTableInfo struct{ shopTableInfo }
+
+func (pkTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	column := func(name string, pk bool) *Column {
+		return &Column{ColumnType: migrator.ColumnType{
+			NameValue:       sql.NullString{String: name, Valid: true},
+			DataTypeValue:   sql.NullString{String: "bigint", Valid: true},
+			NullableValue:   sql.NullBool{Bool: false, Valid: true},
+			PrimaryKeyValue: sql.NullBool{Bool: pk, Valid: true},
+			ScanTypeValue:   reflect.TypeOf(int64(0)),
+		}, TableName: tableName}
+	}
+	switch tableName {
+	case "logs":
+		return []*Column{column("level", false), column("message", false)}, nil
+	case "members":
+		return []*Column{column("id", true), column("score", false)}, nil
+	}
+	return []*Column{column("user_id", true), column("role_id", true)}, nil
+}
+
+func TestGenerator_RequirePrimaryKey(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), Mode: WithDefaultQuery | WithQueryInterface, RequirePrimaryKey: true})
+	g.UseTableInfo(pkTableInfo{})
+	g.ApplyBasic(g.GenerateModel("logs"), g.GenerateModel("user_roles"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	contents := make(map[string]string)
+	for _, f := range files {
+		contents[f.Path] = string(f.Content)
+	}
+	logs := contents[filepath.Join(g.OutPath, "logs.gen.go")]
+	if strings.Contains(logs, "Save(") || strings.Contains(logs, "FirstOrCreate(") || strings.Contains(logs, "FirstOrCreateWith(") {
+		t.Errorf("expect Save and FirstOrCreate omitted for table without primary key, got:\n%s", logs)
+	}
+	for _, line := range []string{
+		"// Save is not generated: table <logs> has no primary key to identify records to update",
+		"// FirstOrCreate is not generated: table <logs> has no primary key to identify the found or created record",
+		"FirstOrInitWith(seed *model.Log) (*model.Log, error)",
+		"return result, gorm.ErrPrimaryKeyRequired",
+	} {
+		if !strings.Contains(logs, line) {
+			t.Errorf("expect %s in query of logs, got:\n%s", line, logs)
+		}
+	}
+	userRoles := contents[filepath.Join(g.OutPath, "user_roles.gen.go")]
+	if !strings.Contains(userRoles, "Save(values ...*model.UserRole) error") || !strings.Contains(userRoles, "FirstOrCreateWith(seed *model.UserRole) (*model.UserRole, error)") ||
+		strings.Contains(userRoles, "ErrPrimaryKeyRequired") {
+		t.Errorf("expect by primary key methods for composite primary key, got:\n%s", userRoles)
+	}
+
+	for _, mode := range []GenerateMode{WithDefaultQuery, WithGeneric} {
+		g = NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), Mode: mode, RequirePrimaryKey: true})
+		g.UseTableInfo(pkTableInfo{})
+		g.ApplyBasic(g.GenerateModel("logs"))
+		if _, err := g.Plan(); err == nil || !strings.Contains(err.Error(), "table logs has no primary key") {
+			t.Errorf("expect table without primary key rejected in mode %d, got %v", mode, err)
+		}
+	}
+}
+
+// accountTableInfo table metadata of accounts with nullable, enum and time columns
+type accountTableInfo struct{ shopTableInfo }
+
+func (accountTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	column := func(name, dataType, columnType string, nullable bool, scanType interface{}) *Column {
+		return &Column{ColumnType: migrator.ColumnType{
+			NameValue:       sql.NullString{String: name, Valid: true},
+			DataTypeValue:   sql.NullString{String: dataType, Valid: true},
+			ColumnTypeValue: sql.NullString{String: columnType, Valid: true},
+			NullableValue:   sql.NullBool{Bool: nullable, Valid: true},
+			ScanTypeValue:   reflect.TypeOf(scanType),
+		}, TableName: tableName}
+	}
+	return []*Column{
+		column("id", "bigint", "bigint", false, int64(0)),
+		column("nick-name", "varchar", "varchar(64)", true, ""),
+		column("status", "enum", "enum('active','banned')", false, ""),
+		column("verified", "tinyint", "tinyint(1)", false, false),
+		column("created_at", "datetime", "datetime", false, time.Time{}),
+		column("password", "varchar", "varchar(64)", false, ""),
+	}, nil
+}
+
+func TestGenerator_GenerateTypeScript(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), GenerateEnumTypes: true})
+	g.UseTableInfo(accountTableInfo{})
+	g.ApplyBasic(g.GenerateModel("accounts", FieldJSONTag("password", "-")))
+
+	outDir := filepath.Join(t.TempDir(), "ts")
+	if err := g.GenerateTypeScript(outDir); err != nil {
+		t.Fatalf("generate typescript fail: %s", err)
+	}
+	content, err := os.ReadFile(filepath.Join(outDir, "accounts.ts"))
+	if err != nil {
+		t.Fatalf("read typescript file fail: %s", err)
+	}
+	expect := "// Code generated by gorm.io/gen. DO NOT EDIT.\n\n" +
+		"export type AccountStatus = \"active\" | \"banned\";\n\n" +
+		"// Account mapped from table <accounts>\n" +
+		"export interface Account {\n" +
+		"  id: number;\n" +
+		"  \"nick-name\": string | null;\n" +
+		"  status: AccountStatus;\n" +
+		"  verified: boolean;\n" +
+		"  created_at: Date;\n" +
+		"}\n"
+	if string(content) != expect {
+		t.Errorf("expect typescript:\n%s\ngot:\n%s", expect, content)
+	}
+}
+
+func TestGenerator_GenerateProto(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), ProtoOptionalNullable: true})
+	g.UseTableInfo(accountTableInfo{})
+	g.ApplyBasic(g.GenerateModel("accounts"))
+
+	outDir := t.TempDir()
+	if err := g.GenerateProto(outDir, "shop.v1"); err != nil {
+		t.Fatalf("generate proto fail: %s", err)
+	}
+	expect := "// Code generated by gorm.io/gen. DO NOT EDIT.\n\n" +
+		"syntax = \"proto3\";\n\n" +
+		"package shop.v1;\n\n" +
+		"import \"google/protobuf/timestamp.proto\";\n\n" +
+		"// Account mapped from table <accounts>\n" +
+		"message Account {\n" +
+		"  int64 id = 1;\n" +
+		"  optional string nick_name = 2;\n" +
+		"  string status = 3;\n" +
+		"  bool verified = 4;\n" +
+		"  google.protobuf.Timestamp created_at = 5;\n" +
+		"  string password = 6;\n" +
+		"}\n"
+	fileName := filepath.Join(outDir, "accounts.proto")
+	if content, _ := os.ReadFile(fileName); string(content) != expect {
+		t.Errorf("expect proto:\n%s\ngot:\n%s", expect, content)
+	}
+
+	previous := "message Account {\n  reserved 3;\n\n  int64 id = 1;\n  string legacy = 2;\n  string status = 5;\n}\n"
+	if err := os.WriteFile(fileName, []byte(previous), 0640); err != nil {
+		t.Fatalf("write proto file fail: %s", err)
+	}
+	if err := g.GenerateProto(outDir, "shop.v1"); err != nil {
+		t.Fatalf("generate proto fail: %s", err)
+	}
+	content, _ := os.ReadFile(fileName)
+	for _, line := range []string{
+		"  reserved 2, 3;\n",
+		"  int64 id = 1;\n",
+		"  optional string nick_name = 6;\n",
+		"  string status = 5;\n",
+		"  bool verified = 7;\n",
+		"  string password = 9;\n",
+	} {
+		if !strings.Contains(string(content), line) {
+			t.Errorf("expect %q in regenerated proto, got:\n%s", line, content)
+		}
+	}
+}
+
+func TestProtoFieldName(t *testing.T) {
+	for column, expect := range map[string]string{
+		"user_name":  "user_name",
+		"userName":   "user_name",
+		"HTTPStatus": "http_status",
+		"nick-name":  "nick_name",
+		"package":    "package_",
+		"1st":        "f_1st",
+	} {
+		if name := protoFieldName(column); name != expect {
+			t.Errorf("column %s expects proto field name %s, got %s", column, expect, name)
+		}
+	}
+}
+
+// eventTableInfo table metadata of events with json columns
+type eventTableInfo struct{ shopTableInfo }
+
+func (eventTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	column := func(name, dataType string) *Column {
+		return &Column{ColumnType: migrator.ColumnType{
+			NameValue:     sql.NullString{String: name, Valid: true},
+			DataTypeValue: sql.NullString{String: dataType, Valid: true},
+			NullableValue: sql.NullBool{Bool: false, Valid: true},
+		}, TableName: tableName}
+	}
+	return []*Column{column("id", "bigint"), column("payload", "json"), column("meta", "json")}, nil
+}
+
+func TestConfig_FieldType(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query")})
+	g.UseTableInfo(eventTableInfo{})
+	g.FieldType("events", "payload", "github.com/acme/mypkg.Payload")
+	g.FieldType("events", "id", "mypkg.ID") // not a json column
+	g.FieldType("orders", "meta", "mypkg.Meta")
+	g.ApplyBasic(g.GenerateModel("events"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var content string
+	for _, f := range files {
+		if filepath.Base(filepath.Dir(f.Path)) == "model" {
+			content = string(f.Content)
+		}
+	}
+	for _, line := range []string{`"github.com/acme/mypkg"`, "Payload mypkg.Payload", "Meta    string", "ID      int64"} {
+		if !strings.Contains(content, line) {
+			t.Errorf("expect %s in events model, got:\n%s", line, content)
+		}
+	}
+
+	g = NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query")})
+	g.UseTableInfo(eventTableInfo{})
+	g.FieldType("events", "payload", "mypkg.Pay load")
+	_, err = generate.GetQueryStructMeta(g.db, g.genModelConfig("events", "Event", nil))
+	if err == nil || !strings.Contains(err.Error(), `type "mypkg.Pay load" of json column payload is not a valid qualified identifier`) {
+		t.Errorf("expect invalid type rejected, got %v", err)
+	}
+}
+
+func TestGenerator_ModelPkgPerTable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n"), 0640); err != nil {
+		t.Fatalf("write go.mod fail: %s", err)
+	}
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query"), ModelPkgPerTable: true, WithForeignKeyRelations: true})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"), g.GenerateModel("orders"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	imports := make(map[string][]string, len(files))
+	pkgNames := make(map[string]string, len(files))
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f.Path)
+		file, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("generated file %s is invalid: %s", rel, err)
+		}
+		pkgNames[filepath.ToSlash(rel)] = file.Name.Name
+		for _, spec := range file.Imports {
+			imports[filepath.ToSlash(rel)] = append(imports[filepath.ToSlash(rel)], strings.Trim(spec.Path.Value, `"`))
+		}
+	}
+
+	for file, pkg := range map[string]string{"model/users/users.gen.go": "users", "model/orders/orders.gen.go": "orders"} {
+		if pkgNames[file] != pkg {
+			t.Errorf("expect %s with package %s, got %q", file, pkg, pkgNames[file])
+		}
+	}
+	expectImports := map[string][]string{
+		"model/orders/orders.gen.go": {"example.com/shop/model/users"},
+		"query/orders.gen.go":        {"example.com/shop/model/orders", "example.com/shop/model/users"},
+		"query/users.gen.go":         {"example.com/shop/model/users"},
+	}
+	for file, paths := range expectImports {
+		for _, p := range paths {
+			if !utils.Contains(imports[file], p) {
+				t.Errorf("expect %s imports %s, got %v", file, p, imports[file])
+			}
+		}
+	}
+	if utils.Contains(imports["model/users/users.gen.go"], "example.com/shop/model/orders") {
+		t.Errorf("users model should not import orders model")
+	}
+}
+
+// domainTableInfo table metadata of users and tables prefixed by domain, their user_id references users.id
+type domainTableInfo struct{ shopTableInfo }
+
+func (domainTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	return shopTableInfo{}.GetTableColumns("", map[string]string{"billing_invoices": "orders", "auth_sessions": "orders"}[tableName])
+}
+
+func (domainTableInfo) GetTableIndex(string, string) ([]gorm.Index, error) { return nil, nil }
+
+func (domainTableInfo) GetTableForeignKeys(_ string, tableName string) ([]*model.ForeignKey, error) {
+	if tableName == "users" {
+		return nil, nil
+	}
+	return []*model.ForeignKey{{Name: "fk_user", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}}}, nil
+}
+
+func TestGenerator_PackageByPrefix(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n"), 0640); err != nil {
+		t.Fatalf("write go.mod fail: %s", err)
+	}
+	g := NewGenerator(Config{
+		OutPath:                 filepath.Join(dir, "query"),
+		PackageByPrefix:         map[string]string{"billing_": "billing", "auth_": "domain/auth"},
+		StripPackagePrefix:      true,
+		WithForeignKeyRelations: true,
+	})
+	g.UseTableInfo(domainTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"), g.GenerateModel("billing_invoices"), g.GenerateModel("auth_sessions"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	contents := make(map[string]string, len(files))
+	imports := make(map[string][]string, len(files))
+	pkgNames := make(map[string]string, len(files))
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f.Path)
+		rel = filepath.ToSlash(rel)
+		file, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, parser.ImportsOnly)
+		if err != nil {
+			t.Fatalf("generated file %s is invalid: %s", rel, err)
+		}
+		contents[rel], pkgNames[rel] = string(f.Content), file.Name.Name
+		for _, spec := range file.Imports {
+			imports[rel] = append(imports[rel], strings.Trim(spec.Path.Value, `"`))
+		}
+	}
+
+	for file, pkg := range map[string]string{
+		"model/users.gen.go":                     "model",
+		"model/billing/billing_invoices.gen.go":  "billing",
+		"model/domain/auth/auth_sessions.gen.go": "auth",
+	} {
+		if pkgNames[file] != pkg {
+			t.Errorf("expect %s with package %s, got %q", file, pkg, pkgNames[file])
+		}
+	}
+	if content := contents["model/billing/billing_invoices.gen.go"]; !strings.Contains(content, "type Invoice struct") ||
+		!regexp.MustCompile(`User\s+\*model\.User`).MatchString(content) {
+		t.Errorf("expect model Invoice with prefix stripped and relation to model.User, got:\n%s", content)
+	}
+	if content := contents["query/auth_sessions.gen.go"]; !strings.Contains(content, "auth.Session") {
+		t.Errorf("expect query of auth.Session, got:\n%s", content)
+	}
+	expectImports := map[string][]string{
+		"model/billing/billing_invoices.gen.go":  {"example.com/shop/model"},
+		"model/domain/auth/auth_sessions.gen.go": {"example.com/shop/model"},
+		"query/billing_invoices.gen.go":          {"example.com/shop/model/billing", "example.com/shop/model"},
+		"query/auth_sessions.gen.go":             {"example.com/shop/model/domain/auth", "example.com/shop/model"},
+		"query/users.gen.go":                     {"example.com/shop/model"},
+	}
+	for file, paths := range expectImports {
+		for _, p := range paths {
+			if !utils.Contains(imports[file], p) {
+				t.Errorf("expect %s imports %s, got %v", file, p, imports[file])
+			}
+		}
+	}
+}
+
+func TestGenerator_ForeignKeyRelationsOfSelection(t *testing.T) {
+	testcases := []struct {
+		tables []string
+		expect bool
+	}{
+		{[]string{"orders"}, false}, // users is out of selection
+		{[]string{"orders", "users"}, true},
+	}
+	for _, tc := range testcases {
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), WithForeignKeyRelations: true})
+		g.UseTableInfo(shopTableInfo{})
+		for _, table := range tc.tables {
+			g.GenerateModel(table)
+		}
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		for _, f := range files {
+			if filepath.Base(f.Path) != "orders.gen.go" || filepath.Base(filepath.Dir(f.Path)) != "model" {
+				continue
+			}
+			relation := "User   *User `gorm:\"foreignKey:user_id;references:id\" json:\"user\"`"
+			if got := bytes.Contains(f.Content, []byte(relation)); got != tc.expect {
+				t.Errorf("generate %v: expect belongs-to User generated %t, got:\n%s", tc.tables, tc.expect, f.Content)
+			}
+		}
+	}
+}
+
+func TestGenerator_ExportSchema(t *testing.T) {
+	g := NewGenerator(Config{FieldWithIndexTag: true})
+	g.UseTableInfo(shopTableInfo{})
+	g.GenerateModel("users")
+	g.GenerateModel("orders")
+
+	var buf bytes.Buffer
+	if err := g.ExportSchema(&buf, SchemaFormatJSON); err != nil {
+		t.Fatalf("export schema fail: %s", err)
+	}
+	var tables []TableSchema
+	if err := json.Unmarshal(buf.Bytes(), &tables); err != nil {
+		t.Fatalf("exported schema is invalid json: %s", err)
+	}
+	if len(tables) != 2 || tables[0].Name != "orders" || tables[1].Name != "users" {
+		t.Fatalf("expect tables sorted by name, got %+v", tables)
+	}
+	expectIndexes := []IndexSchema{{Name: "idx_user_order", Columns: []IndexColumnSchema{{Name: "user_id", Priority: 1}, {Name: "id", Priority: 2}}}}
+	if !reflect.DeepEqual(tables[0].Indexes, expectIndexes) {
+		t.Errorf("expect indexes %+v, got %+v", expectIndexes, tables[0].Indexes)
+	}
+	if col := tables[0].Columns[1]; col.Name != "user_id" || col.DatabaseType != "bigint" || col.GoType != "int64" || col.Nullable {
+		t.Errorf("unexpected column schema: %+v", col)
+	}
+
+	var yamlBuf, yamlAgain bytes.Buffer
+	if err := g.ExportSchema(&yamlBuf, SchemaFormatYAML); err != nil {
+		t.Fatalf("export schema fail: %s", err)
+	}
+	_ = g.ExportSchema(&yamlAgain, SchemaFormatYAML)
+	if !bytes.Contains(yamlBuf.Bytes(), []byte("- name: idx_user_order")) || yamlBuf.String() != yamlAgain.String() {
+		t.Errorf("expect stable yaml schema with index, got:\n%s", yamlBuf.String())
+	}
+	if err := g.ExportSchema(&buf, "xml"); err == nil {
+		t.Errorf("export schema with unsupported format expects error")
+	}
+}
+
+func TestGenerator_CheckStale(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n"), 0640); err != nil {
+		t.Fatalf("write go.mod fail: %s", err)
+	}
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query"), FieldWithIndexTag: true, WithSchemaFingerprint: true})
+	g.UseTableInfo(shopTableInfo{})
+	g.GenerateModel("users")
+	g.GenerateModel("orders")
+
+	if stale, err := g.CheckStale(); err != nil || !reflect.DeepEqual(stale, []string{"orders", "users"}) {
+		t.Fatalf("expect all tables stale before execute, got %v, err: %v", stale, err)
+	}
+
+	g.Execute()
+	if stale, err := g.CheckStale(); err != nil || len(stale) != 0 {
+		t.Fatalf("expect no stale table after execute, got %v, err: %v", stale, err)
+	}
+
+	modelFile := filepath.Join(dir, "model", "orders.gen.go")
+	content, err := os.ReadFile(modelFile)
+	if err != nil {
+		t.Fatalf("read model file fail: %s", err)
+	}
+	if !strings.Contains(string(content), fingerprintPrefix+"sha256:") {
+		t.Fatalf("expect schema fingerprint in model file header, got:\n%s", content)
+	}
+	content = []byte(strings.Replace(string(content), fingerprintPrefix+"sha256:", fingerprintPrefix+"sha256:0", 1))
+	if err := os.WriteFile(modelFile, content, 0640); err != nil {
+		t.Fatalf("modify model file fail: %s", err)
+	}
+	if stale, err := g.CheckStale(); err != nil || !reflect.DeepEqual(stale, []string{"orders"}) {
+		t.Errorf("expect orders stale, got %v, err: %v", stale, err)
+	}
+}
+
+func TestGenerator_SchemaFingerprint(t *testing.T) {
+	fingerprint := func(info ITableInfo, tables ...string) string {
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), FieldWithIndexTag: true})
+		g.UseTableInfo(info)
+		for _, table := range tables {
+			g.GenerateModel(table)
+		}
+		fingerprint, err := g.SchemaFingerprint()
+		if err != nil {
+			t.Fatalf("get schema fingerprint fail: %s", err)
+		}
+		return fingerprint
+	}
+
+	expect := fingerprint(shopTableInfo{}, "users", "orders")
+	if !strings.HasPrefix(expect, "sha256:") {
+		t.Errorf("expect sha256 fingerprint, got %s", expect)
+	}
+	for i := 0; i < 3; i++ {
+		if got := fingerprint(shopTableInfo{}, "orders", "users"); got != expect {
+			t.Errorf("expect fingerprint independent of table order, got %s and %s", expect, got)
+		}
+	}
+	if got := fingerprint(uniqueTableInfo{}, "users", "orders"); got == expect {
+		t.Errorf("expect fingerprint changed by unique index of orders")
+	}
+	if got := fingerprint(shopTableInfo{}, "users"); got == expect {
+		t.Errorf("expect fingerprint changed by introspected tables")
+	}
+
+	// field options apply to generated fields, the same table generated twice is hashed once
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), FieldWithIndexTag: true})
+	g.UseTableInfo(shopTableInfo{})
+	g.GenerateModel("users")
+	g.GenerateModelAs("orders", "Order", FieldIgnore("user_id"))
+	g.GenerateModelAs("orders", "OrderArchive")
+	if got, err := g.SchemaFingerprint(); err != nil || got != expect {
+		t.Errorf("expect fingerprint %s of introspected columns, got %s, err: %v", expect, got, err)
+	}
+
+	if _, err := NewGenerator(Config{}).SchemaFingerprint(); err == nil {
+		t.Errorf("expect error when no table is introspected")
+	}
+}
+
+func TestGenerator_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), Context: ctx})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"))
+
+	cancel()
+	if _, err := generate.GetQueryStructMeta(g.db, g.genModelConfig("orders", "Order", nil)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expect introspection aborted with context canceled, got %v", err)
+	}
+	if _, err := g.Plan(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expect generation aborted with context canceled, got %v", err)
+	}
+}
+
+// cancelTableInfo shop table metadata canceling generation while reading columns of the first table
+type cancelTableInfo struct {
+	shopTableInfo
+	cancel context.CancelFunc
+}
+
+func (c cancelTableInfo) GetTableColumns(schemaName string, tableName string) ([]*Column, error) {
+	c.cancel()
+	return c.shopTableInfo.GetTableColumns(schemaName, tableName)
+}
+
+func TestGenerator_GenerateAllTableCanceled(t *testing.T) {
+	tableDB, err := gorm.Open(tablesDialector{tables: []string{"users", "orders", "items"}}, &gorm.Config{Logger: db.Logger})
+	if err != nil {
+		t.Fatalf("open db fail: %s", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), Context: ctx})
+	g.UseDB(tableDB)
+	g.UseTableInfo(cancelTableInfo{cancel: cancel})
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, context.Canceled) {
+			t.Errorf("expect GenerateAllTable aborted with context canceled, got %v", err)
+		}
+	}()
+	g.GenerateAllTable()
+}
+
+// treeTableInfo table metadata of categories, categories.parent_id references categories.id
+type treeTableInfo struct{}
+
+func (treeTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	column := func(name string, nullable bool) *Column {
+		return &Column{ColumnType: migrator.ColumnType{
+			NameValue:     sql.NullString{String: name, Valid: true},
+			DataTypeValue: sql.NullString{String: "bigint", Valid: true},
+			NullableValue: sql.NullBool{Bool: nullable, Valid: true},
+			ScanTypeValue: reflect.TypeOf(int64(0)),
+		}, TableName: tableName}
+	}
+	return []*Column{column("id", false), column("parent_id", true)}, nil
+}
+
+func (treeTableInfo) GetTableIndex(string, string) ([]gorm.Index, error) { return nil, nil }
+
+func TestGenerator_TableInfoWithoutForeignKeys(t *testing.T) {
+	var info ITableInfo = treeTableInfo{}
+	if _, ok := info.(IForeignKeyInfo); ok {
+		t.Fatal("expect treeTableInfo not providing foreign keys")
+	}
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), WithForeignKeyRelations: true})
+	g.UseTableInfo(info)
+	g.ApplyBasic(g.GenerateModel("categories"))
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	for _, f := range files {
+		if filepath.Base(f.Path) == "categories.gen.go" && filepath.Base(filepath.Dir(f.Path)) == "model" && bytes.Contains(f.Content, []byte("foreignKey")) {
+			t.Errorf("expect no relation without foreign keys, got:\n%s", f.Content)
+		}
+	}
+}
+
+func TestGenerator_FieldRelateSelf(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query")})
+	g.UseTableInfo(treeTableInfo{})
+	tag := field.GormTag{}.Set(field.TagKeyGormForeignKey, "parent_id").Set(field.TagKeyGormReferences, "id")
+	g.ApplyBasic(g.GenerateModel("categories",
+		FieldRelateSelf(field.BelongsTo, "Parent", &field.RelateConfig{GORMTag: tag}),
+		FieldRelateSelf(field.HasMany, "Children", &field.RelateConfig{GORMTag: tag}),
+	))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	for _, f := range files {
+		if filepath.Base(filepath.Dir(f.Path)) != "model" {
+			continue
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, f.Path, f.Content, 0)
+		if err != nil {
+			t.Fatalf("generated model is invalid: %s", err)
+		}
+		if _, err := (&types.Config{Importer: importer.Default()}).Check("model", fset, []*ast.File{file}, nil); err != nil {
+			t.Fatalf("generated model does not compile: %s\n%s", err, f.Content)
+		}
+		for _, line := range []string{
+			"Parent   *Category   `gorm:\"foreignKey:parent_id;references:id\" json:\"parent\"`",
+			"Children []*Category `gorm:\"foreignKey:parent_id;references:id\" json:\"children\"`",
+		} {
+			if !bytes.Contains(f.Content, []byte(line)) {
+				t.Errorf("expect field %s in generated model, got:\n%s", line, f.Content)
+			}
+		}
+		return
+	}
+	t.Fatalf("model file of categories is not generated")
+}
+
+func TestGenerator_WithQueryFilter(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), WithQueryFilter: true})
+	g.UseTableInfo(treeTableInfo{})
+	g.ApplyBasic(g.GenerateModel("categories"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	for _, f := range files {
+		if filepath.Base(f.Path) != "categories.gen.go" || filepath.Base(filepath.Dir(f.Path)) != "query" {
+			continue
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, 0); err != nil {
+			t.Fatalf("generated query is invalid: %s", err)
+		}
+		for _, line := range []string{
+			"type CategoryFilter struct {",
+			"ParentIDIsNull *bool  // parent_id IS NULL when true, IS NOT NULL when false",
+			"func (c category) FilterConds(filter CategoryFilter) (conds []gen.Condition) {",
+			"conds = append(conds, c.ID.Eq(*filter.ID))",
+			"conds = append(conds, c.ParentID.IsNotNull())",
+		} {
+			if !bytes.Contains(f.Content, []byte(line)) {
+				t.Errorf("expect %s in generated query, got:\n%s", line, f.Content)
+			}
+		}
+		return
+	}
+	t.Fatalf("query file of categories is not generated")
+}
+
+func TestGenerator_WithDBResolverClause(t *testing.T) {
+	queryContents := func(cfg Config) map[string]string {
+		cfg.OutPath = filepath.Join(t.TempDir(), "query")
+		cfg.WithUnitTest = true
+		g := NewGenerator(cfg)
+		g.UseTableInfo(shopTableInfo{})
+		g.ApplyBasic(g.GenerateModel("users"))
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		contents := make(map[string]string)
+		for _, f := range files {
+			if filepath.Base(filepath.Dir(f.Path)) == "query" {
+				contents[filepath.Base(f.Path)] = string(f.Content)
+			}
+		}
+		return contents
+	}
+
+	cfg := Config{}
+	cfg.WithDBResolverClause("analytics")
+	contents := queryContents(cfg)
+	// db of Query carries the clause, so that Transaction, Begin, ReadDB and WriteDB run on resolver
+	wrap := `db = db.Clauses(dbresolver.Use("analytics"))`
+	for _, expect := range []string{
+		"func Use(db *gorm.DB, opts ...gen.DOOption) *Query {\n\t" + wrap + "\n\treturn &Query{",
+		"func (q *Query) ReplaceDB(db *gorm.DB) *Query {\n\t" + wrap,
+		"func (q *Query) WithTx(tx *gorm.DB) *Query {\n\ttx = tx.Clauses(dbresolver.Use(\"analytics\"))",
+		"return q.db.Transaction(func(tx *gorm.DB) error { return fc(q.clone(tx)) }, opts...)",
+		"return q.clone(q.db.Clauses(dbresolver.Read))",
+	} {
+		if !strings.Contains(contents["gen.go"], expect) {
+			t.Errorf("expect %s in generated query, got:\n%s", expect, contents["gen.go"])
+		}
+	}
+	if users := contents["users.gen.go"]; !strings.Contains(users, "_user.userDo.UseDB(db, opts...)") || strings.Contains(users, "dbresolver.Use") {
+		t.Errorf("expect db wrapped once by Use instead of newUser, got:\n%s", users)
+	}
+	if test := contents["gen_test.go"]; !strings.Contains(test, "func Test_DBResolver(t *testing.T) {") {
+		t.Errorf("expect unit test of resolver clause on Transaction and ReadDB paths, got:\n%s", test)
+	}
+
+	for name, content := range queryContents(Config{}) {
+		if strings.Contains(content, "dbresolver.Use") || strings.Contains(content, "Test_DBResolver") {
+			t.Errorf("expect %s without resolver clause, got:\n%s", name, content)
+		}
+	}
+}
+
+func TestGenerator_WithOrderBy(t *testing.T) {
+	testcases := []struct {
+		caseInsensitive bool
+		lines           []string
+	}{
+		{false, []string{
+			"var _orderOrderByColumns = map[string]string{\n\t\"id\":      \"id\",\n\t\"user_id\": \"user_id\",\n}",
+			"func OrderOrderBy(column string, desc bool) (field.Expr, error) {\n\tname, ok := _orderOrderByColumns[column]",
+			"return nil, fmt.Errorf(\"order by unknown column %q of orders\", column)",
+			"f := field.NewField(\"orders\", name)",
+		}},
+		{true, []string{"name, ok := _orderOrderByColumns[strings.ToLower(column)]"}},
+	}
+	for _, tc := range testcases {
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), WithOrderBy: true, OrderByCaseInsensitive: tc.caseInsensitive})
+		g.UseTableInfo(shopTableInfo{})
+		g.ApplyBasic(g.GenerateModel("orders"))
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		var content []byte
+		for _, f := range files {
+			if filepath.Base(f.Path) == "orders.gen.go" && filepath.Base(filepath.Dir(f.Path)) == "query" {
+				content = f.Content
+			}
+		}
+		for _, line := range tc.lines {
+			if !bytes.Contains(content, []byte(line)) {
+				t.Errorf("case insensitive %t: expect %s in generated query, got:\n%s", tc.caseInsensitive, line, content)
+			}
+		}
+	}
+
+	meta := &generate.QueryStructMeta{OrderByCaseInsensitive: true, Fields: []*model.Field{
+		{Name: "UserName", ColumnName: "UserName"}, {Name: "Username", ColumnName: "username"},
+	}}
+	if columns := meta.OrderByColumns(); !reflect.DeepEqual(columns, []generate.OrderByColumn{{Key: "username", Name: "UserName"}}) {
+		t.Errorf("expect the first of columns differing only in case, got %+v", columns)
+	}
+}
+
+func TestGenerator_CustomTemplate(t *testing.T) {
+	g := NewGenerator(Config{
+		OutPath: filepath.Join(t.TempDir(), "query"),
+		ModelTemplate: `package {{.StructInfo.Package}}
+const TableName{{.ModelStructName}} = "{{.TableName}}"
+// {{.ModelStructName}} of {{.TableName}}
+type {{.ModelStructName}} struct { {{range .Fields}}
+{{.Name}}   {{.Type}}{{end}}
+}`,
+		QueryTemplate: `type {{.QueryStructName}} struct{ gen.DO }
+func new{{.ModelStructName}}(db *gorm.DB, opts ...gen.DOOption) {{.QueryStructName}} { return {{.QueryStructName}}{} }`,
+	})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("orders"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	expects := map[string]string{
+		"model/orders.gen.go": "package model\n\nconst TableNameOrder = \"orders\"\n\n// Order of orders\ntype Order struct {\n\tID     int64\n\tUserID int64\n}\n",
+		"query/orders.gen.go": "type order struct{ gen.DO }\n\nfunc newOrder(db *gorm.DB, opts ...gen.DOOption) order { return order{} }\n",
+	}
+	for _, f := range files {
+		expect, ok := expects[filepath.Base(filepath.Dir(f.Path))+"/"+filepath.Base(f.Path)]
+		if ok && !bytes.Contains(f.Content, []byte(expect)) {
+			t.Errorf("expect formatted output of custom template %s, got:\n%s", expect, f.Content)
+		}
+		delete(expects, filepath.Base(filepath.Dir(f.Path))+"/"+filepath.Base(f.Path))
+	}
+	if len(expects) > 0 {
+		t.Errorf("expect files generated by custom template: %v", expects)
+	}
+
+	// methods of built-in query code can't be generated with query template
+	g = NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), WithOrderBy: true,
+		QueryTemplate: `type {{.QueryStructName}} struct{ gen.DO }`})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("orders"))
+	if _, err := g.Plan(); err == nil || !strings.Contains(err.Error(), "query of Order: WithOrderBy can't be generated with QueryTemplate") {
+		t.Errorf("expect WithOrderBy rejected with query template, got %v", err)
+	}
+
+	defer func() {
+		if err := recover(); err == nil || !strings.Contains(fmt.Sprint(err), "model template is invalid") {
+			t.Errorf("expect parse error of model template, got %v", err)
+		}
+	}()
+	NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), ModelTemplate: "{{.ModelStructName"})
+}
+
+type sqlserverDialector struct{ tests.DummyDialector }
+
+func (sqlserverDialector) Name() string { return "sqlserver" }
+
+func TestGenerator_WithUpsert(t *testing.T) {
+	sqlserverDB, _ := gorm.Open(sqlserverDialector{}, nil)
+	for _, tc := range []struct {
+		db  *gorm.DB
+		err string
+	}{
+		{nil, "no db connected"},
+		{sqlserverDB, `unsupported dialect "sqlserver"`},
+		{db, ""},
+	} {
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), WithUpsert: true, Mode: WithQueryInterface})
+		g.UseDB(tc.db)
+		g.UseTableInfo(shopTableInfo{})
+		g.ApplyBasic(g.GenerateModel("users"))
+
+		files, err := g.Plan()
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expect error %q, got %v", tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		for _, f := range files {
+			if filepath.Base(f.Path) != "users.gen.go" || filepath.Base(filepath.Dir(f.Path)) != "query" {
+				continue
+			}
+			if n := bytes.Count(f.Content, []byte("UpsertByColumns(conflictCols []field.Expr, updateCols []field.Expr, values ...*model.User) error")); n != 2 {
+				t.Errorf("expect UpsertByColumns in interface and query struct, got %d in:\n%s", n, f.Content)
+			}
+		}
+	}
+}
+
+// uniqueTableInfo shop tables with unique index on orders
+type uniqueTableInfo struct{ shopTableInfo }
+
+func (uniqueTableInfo) GetTableIndex(_ string, tableName string) ([]gorm.Index, error) {
+	if tableName != "orders" {
+		return nil, nil
+	}
+	return []gorm.Index{
+		&migrator.Index{TableName: "orders", NameValue: "uk_user_order", ColumnList: []string{"user_id", "id"}, UniqueValue: sql.NullBool{Bool: true, Valid: true}},
+		&migrator.Index{TableName: "orders", NameValue: "idx_user", ColumnList: []string{"user_id"}},
+	}, nil
+}
+
+func TestGenerator_Upsert(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), WithUpsert: true, FieldWithIndexTag: true, Mode: WithQueryInterface})
+	g.UseTableInfo(uniqueTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"), g.GenerateModel("orders"))
+	g.UseDB(db) // connected after introspection, index column sequences are not read from the dummy db
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	contents := make(map[string]string)
+	for _, f := range files {
+		contents[f.Path] = string(f.Content)
+	}
+	for _, name := range []string{"users", "orders"} {
+		content := contents[filepath.Join(g.OutPath, name+".gen.go")]
+		signature := fmt.Sprintf("Upsert(conflictColumns []clause.Column, doUpdates clause.Set, values ...*model.%s) error", db.NamingStrategy.SchemaName(name))
+		if n := strings.Count(content, signature); n != 2 {
+			t.Errorf("expect Upsert in interface and query struct of %s, got %d in:\n%s", name, n, content)
+		}
+		if !strings.Contains(content, "onConflict := clause.OnConflict{Columns: conflictColumns, DoUpdates: doUpdates, DoNothing: len(doUpdates) == 0}") {
+			t.Errorf("expect Upsert of %s built by clause.OnConflict, got:\n%s", name, content)
+		}
+	}
+	if orders := contents[filepath.Join(g.OutPath, "orders.gen.go")]; !strings.Contains(orders, `conflictColumns = []clause.Column{{Name: "user_id"}, {Name: "id"}}`) {
+		t.Errorf("expect unique index columns as default conflict columns of orders, got:\n%s", orders)
+	}
+	if users := contents[filepath.Join(g.OutPath, "users.gen.go")]; strings.Contains(users, "conflictColumns = ") {
+		t.Errorf("expect no default conflict columns of users without unique index, got:\n%s", users)
+	}
+}
+
+// lookupTableInfo shop tables with unique indexes on id and user_id of orders
+type lookupTableInfo struct{ shopTableInfo }
+
+func (lookupTableInfo) GetTableIndex(_ string, tableName string) ([]gorm.Index, error) {
+	if tableName != "orders" {
+		return nil, nil
+	}
+	unique := sql.NullBool{Bool: true, Valid: true}
+	return []gorm.Index{
+		&migrator.Index{TableName: "orders", NameValue: "uk_id", ColumnList: []string{"id"}, UniqueValue: unique},
+		&migrator.Index{TableName: "orders", NameValue: "PRIMARY", ColumnList: []string{"id"}, UniqueValue: unique},
+		&migrator.Index{TableName: "orders", NameValue: "uk_user_order", ColumnList: []string{"user_id", "id"}, UniqueValue: unique},
+		&migrator.Index{TableName: "orders", NameValue: "idx_user", ColumnList: []string{"user_id"}},
+	}, nil
+}
+
+func TestGenerator_GenerateIndexLookups(t *testing.T) {
+	for _, mode := range []GenerateMode{WithQueryInterface, WithGeneric} {
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), GenerateIndexLookups: true, Mode: mode})
+		g.UseTableInfo(lookupTableInfo{})
+		g.ApplyBasic(g.GenerateModel("users"), g.GenerateModel("orders"))
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		contents := make(map[string]string)
+		for _, f := range files {
+			contents[f.Path] = string(f.Content)
+		}
+		orders := contents[filepath.Join(g.OutPath, "orders.gen.go")]
+		if n := strings.Count(orders, "WhereByID(id int64) IOrderDo"); n != 2 {
+			t.Errorf("expect one WhereByID in interface and query struct of orders in mode %d, got %d in:\n%s", mode, n, orders)
+		}
+		for _, line := range []string{
+			"// WhereByID filter records whose id equals id, id is backed by unique index PRIMARY",
+			`return o.Where(field.NewInt64(o.TableName(), "id").Eq(id))`,
+		} {
+			if !strings.Contains(orders, line) {
+				t.Errorf("expect %s in query of orders in mode %d, got:\n%s", line, mode, orders)
+			}
+		}
+		if strings.Contains(orders, "WhereByUserID") {
+			t.Errorf("expect no lookup of user_id backing composite or non-unique index, got:\n%s", orders)
+		}
+		if users := contents[filepath.Join(g.OutPath, "users.gen.go")]; strings.Contains(users, "WhereBy") {
+			t.Errorf("expect no lookup of users without index, got:\n%s", users)
+		}
+		if model := contents[filepath.Join(filepath.Dir(g.OutPath), "model", "orders.gen.go")]; strings.Contains(model, "index:") {
+			t.Errorf("expect no index tag without FieldWithIndexTag, got:\n%s", model)
+		}
+	}
+}
+
+// versionTableInfo table metadata of users and orders, orders has version column of optimistic locking
+type versionTableInfo struct{ shopTableInfo }
+
+func (versionTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	column := func(name string) *Column {
+		return &Column{ColumnType: migrator.ColumnType{
+			NameValue:     sql.NullString{String: name, Valid: true},
+			DataTypeValue: sql.NullString{String: "bigint", Valid: true},
+			NullableValue: sql.NullBool{Bool: false, Valid: true},
+		}, TableName: tableName}
+	}
+	if tableName == "orders" {
+		return []*Column{column("id"), column("version")}, nil
+	}
+	return []*Column{column("id")}, nil
+}
+
+func TestGenerator_OptimisticLockField(t *testing.T) {
+	for _, mode := range []GenerateMode{WithQueryInterface, WithGeneric} {
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), OptimisticLockField: "version", Mode: mode})
+		g.UseTableInfo(versionTableInfo{})
+		g.ApplyBasic(g.GenerateModel("users"), g.GenerateModel("orders"))
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		contents := make(map[string]string)
+		for _, f := range files {
+			contents[f.Path] = string(f.Content)
+		}
+		orders := contents[filepath.Join(g.OutPath, "orders.gen.go")]
+		if n := strings.Count(orders, "UpdateWithVersion(version int64, columns ...field.AssignExpr) (rowsAffected int64, err error)"); n != 2 {
+			t.Errorf("expect UpdateWithVersion in interface and query struct of orders in mode %d, got %d in:\n%s", mode, n, orders)
+		}
+		for _, line := range []string{
+			`versionField := field.NewInt64(o.TableName(), "version")`,
+			"info, err := o.Where(versionField.Eq(version)).UpdateSimple(append(columns, versionField.Add(1))...)",
+			"return 0, gen.ErrStaleVersion",
+		} {
+			if !strings.Contains(orders, line) {
+				t.Errorf("expect %s in query of orders in mode %d, got:\n%s", line, mode, orders)
+			}
+		}
+		if users := contents[filepath.Join(g.OutPath, "users.gen.go")]; strings.Contains(users, "UpdateWithVersion") {
+			t.Errorf("expect no UpdateWithVersion of users without version column, got:\n%s", users)
+		}
+	}
+
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), OptimisticLockField: "version"})
+	g.UseTableInfo(versionTableInfo{})
+	g.ApplyBasic(g.GenerateModel("orders", FieldType("version", "optimisticlock.Version")))
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	for _, f := range files {
+		if bytes.Contains(f.Content, []byte("UpdateWithVersion")) {
+			t.Errorf("expect no UpdateWithVersion of non-integer version column, got in %s:\n%s", f.Path, f.Content)
+		}
+	}
+}
+
+func TestGenerator_BuildTags(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), BuildTags: []string{"integration", "linux || darwin"}, WithSchemaFingerprint: true})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"), g.GenerateModel("orders"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, ".go") {
+			continue
+		}
+		lines := strings.Split(string(f.Content), "\n")
+		if !generatedHeader.MatchString(lines[0]) {
+			t.Errorf("expect DO NOT EDIT header as first line of %s, got %q", f.Path, lines[0])
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, f.Path, f.Content, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("generated file %s is invalid: %s", f.Path, err)
+		}
+		var goBuild []string
+		for _, group := range file.Comments {
+			if group.Pos() > file.Package {
+				break
+			}
+			for _, c := range group.List {
+				if constraint.IsGoBuild(c.Text) {
+					goBuild = append(goBuild, c.Text)
+				}
+			}
+		}
+		if len(goBuild) != 1 || goBuild[0] != "//go:build integration && (linux || darwin)" {
+			t.Errorf("expect one build constraint of build tags in %s, got %q", f.Path, goBuild)
+		}
+	}
+
+	g = NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), BuildTags: []string{"linux ||"}})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"))
+	if _, err := g.Plan(); err == nil || !strings.Contains(err.Error(), "invalid build tag") {
+		t.Errorf("expect invalid build tag error, got %v", err)
+	}
+}
+
+func TestGenerator_Clean(t *testing.T) {
+	dir := t.TempDir()
+	newGenerator := func(tables ...string) *Generator {
+		g := NewGenerator(Config{OutPath: filepath.Join(dir, "query")})
+		g.UseTableInfo(shopTableInfo{})
+		for _, table := range tables {
+			g.ApplyBasic(g.GenerateModel(table))
+		}
+		return g
+	}
+
+	files, err := newGenerator("users", "orders").Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+			t.Fatalf("create dir fail: %s", err)
+		}
+		if err := os.WriteFile(f.Path, f.Content, 0o644); err != nil {
+			t.Fatalf("write generated file fail: %s", err)
+		}
+	}
+	handWritten := map[string]string{
+		filepath.Join(dir, "model", "orders_ext.gen.go"): "package model\n\n// Code generated by hand. DO NOT EDIT.\n",
+		filepath.Join(dir, "query", "orders_ext.go"):     "// Code generated by gorm.io/gen. DO NOT EDIT.\n\npackage query\n",
+	}
+	for path, content := range handWritten {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write hand-written file fail: %s", err)
+		}
+	}
+
+	if err := newGenerator("users").Clean(); err != nil {
+		t.Fatalf("clean fail: %s", err)
+	}
+	for _, path := range []string{filepath.Join(dir, "query", "orders.gen.go"), filepath.Join(dir, "model", "orders.gen.go")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expect stale file %s removed, got %v", path, err)
+		}
+	}
+	kept := []string{filepath.Join(dir, "query", "users.gen.go"), filepath.Join(dir, "model", "users.gen.go"), filepath.Join(dir, "query", "gen.go")}
+	for path := range handWritten {
+		kept = append(kept, path)
+	}
+	for _, path := range kept {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expect file %s kept, got %v", path, err)
+		}
+	}
+
+	if err := NewGenerator(Config{OutPath: filepath.Join(dir, "query")}).Clean(); err == nil {
+		t.Errorf("expect error when no model is generated")
+	}
+}
+
+func TestGenerator_CleanModelPkgPerTable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n"), 0640); err != nil {
+		t.Fatalf("write go.mod fail: %s", err)
+	}
+	newGenerator := func(tables ...string) *Generator {
+		g := NewGenerator(Config{OutPath: filepath.Join(dir, "query"), ModelPkgPerTable: true})
+		g.UseTableInfo(shopTableInfo{})
+		for _, table := range tables {
+			g.ApplyBasic(g.GenerateModel(table))
+		}
+		return g
+	}
+
+	files, err := newGenerator("users", "orders").Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var modelFiles []string
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+			t.Fatalf("create dir fail: %s", err)
+		}
+		if err := os.WriteFile(f.Path, f.Content, 0o644); err != nil {
+			t.Fatalf("write generated file fail: %s", err)
+		}
+		if filepath.Dir(filepath.Dir(f.Path)) == filepath.Join(dir, "model") {
+			modelFiles = append(modelFiles, f.Path)
+		}
+	}
+	sort.Strings(modelFiles)
+	ordersModel, usersModel := filepath.Join(dir, "model", "orders", "orders.gen.go"), filepath.Join(dir, "model", "users", "users.gen.go")
+	if !reflect.DeepEqual(modelFiles, []string{ordersModel, usersModel}) {
+		t.Fatalf("expect models in packages of tables, got %v", modelFiles)
+	}
+
+	if err := newGenerator("users").Clean(); err != nil {
+		t.Fatalf("clean fail: %s", err)
+	}
+	if _, err := os.Stat(ordersModel); !os.IsNotExist(err) {
+		t.Errorf("expect stale model %s in package of dropped table removed, got %v", ordersModel, err)
+	}
+	if _, err := os.Stat(usersModel); err != nil {
+		t.Errorf("expect model %s kept, got %v", usersModel, err)
+	}
+}
+
+func TestGenerator_AssociationMethods(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), Mode: WithQueryInterface})
+	g.UseTableInfo(shopTableInfo{})
+	orders := g.GenerateModel("orders")
+	tag := field.GormTag{}.Set(field.TagKeyGormForeignKey, "user_id")
+	g.ApplyBasic(orders, g.GenerateModel("users",
+		FieldRelate(field.HasMany, "Orders", orders, &field.RelateConfig{RelateSlicePointer: true, GORMTag: tag}),
+	))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	contents := make(map[string]string)
+	for _, f := range files {
+		contents[f.Path] = string(f.Content)
+	}
+	users := contents[filepath.Join(g.OutPath, "users.gen.go")]
+	for _, signature := range []string{
+		"CountAssociationOrders(m *model.User) (int64, error)",
+		"ClearOrders(m *model.User) error",
+	} {
+		if n := strings.Count(users, signature); n != 2 {
+			t.Errorf("expect %s in interface and query struct of users, got %d in:\n%s", signature, n, users)
+		}
+	}
+	if !strings.Contains(users, `.Model(m).Association("Orders").Clear()`) {
+		t.Errorf("expect ClearOrders wrapping gorm association, got:\n%s", users)
+	}
+	if orders := contents[filepath.Join(g.OutPath, "orders.gen.go")]; strings.Contains(orders, "CountAssociation") || strings.Contains(orders, "Association(") {
+		t.Errorf("expect no association helpers of orders without relation, got:\n%s", orders)
+	}
+}
+
+func TestGenerator_ScopeColumns(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), ScopeColumns: map[string]string{"user_id": "", "tenant_id": "ByTenant"}})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"), g.GenerateModel("orders"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var content string
+	for _, f := range files {
+		if f.Path == filepath.Join(g.OutPath, "scopes.go") {
+			content = string(f.Content)
+		}
+	}
+	if !strings.Contains(content, "func ByUser(userID int64) func(*gorm.DB) *gorm.DB {") ||
+		!strings.Contains(content, `db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "user_id"}, Value: userID})`) {
+		t.Errorf("expect scope ByUser of user_id, got:\n%s", content)
+	}
+	if strings.Contains(content, "ByTenant") {
+		t.Errorf("expect scope of tenant_id skipped as no table has it, got:\n%s", content)
+	}
+
+	g = NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), ScopeColumns: map[string]string{"tenant_id": ""}})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"))
+	if files, err = g.Plan(); err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	for _, f := range files {
+		if filepath.Base(f.Path) == "scopes.go" {
+			t.Errorf("expect scopes.go skipped when no table has scope columns")
+		}
+	}
+
+	for name, expect := range map[string]string{"TenantID": "tenantID", "ID": "id", "URLPath": "urlPath", "Name": "name"} {
+		if got := lowerCamel(name); got != expect {
+			t.Errorf("lower camel of %s expect %s, got %s", name, expect, got)
+		}
+	}
+}
+
+func TestCompareVersion(t *testing.T) {
+	for version, expect := range map[string]int{"3.24.0": 0, "3.45.1": 1, "3.8.11": -1, "3.24": 0, "4": 1, "": -1} {
+		if got := generate.CompareVersion(version, minSQLiteUpsertVersion); got != expect {
+			t.Errorf("compare version %q expect %d, got %d", version, expect, got)
+		}
+	}
+}
+
+func TestGenerator_SingleQueryFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/shop\n"), 0640); err != nil {
+		t.Fatalf("write go.mod fail: %s", err)
+	}
+	fs := &memFileSystem{files: make(map[string][]byte)} // model package path is resolved by go.mod
+	cfg := Config{OutPath: filepath.Join(dir, "query"), SingleQueryFile: true, Mode: WithDefaultQuery | WithQueryInterface}
+	cfg.WithFileSystem(fs)
+	g := NewGenerator(cfg)
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"), g.GenerateModel("orders"), g.GenerateModel("products"))
+	g.Execute()
+
+	var queryFiles []string
+	for path := range fs.files {
+		if filepath.Dir(path) == g.OutPath {
+			queryFiles = append(queryFiles, path)
+		}
+	}
+	if len(queryFiles) != 1 || queryFiles[0] != g.OutFile {
+		t.Fatalf("expect single query file %s, got %v", g.OutFile, queryFiles)
+	}
+
+	content := fs.files[g.OutFile]
+	file, err := parser.ParseFile(token.NewFileSet(), g.OutFile, content, 0)
+	if err != nil {
+		t.Fatalf("generated query is invalid: %s", err)
+	}
+
+	// imports of all models are merged, every path is imported once
+	imports := make(map[string]int)
+	for _, spec := range file.Imports {
+		imports[spec.Path.Value]++
+	}
+	for path, n := range imports {
+		if n != 1 {
+			t.Errorf("expect import %s once, got %d", path, n)
+		}
+	}
+	for _, path := range []string{`"gorm.io/gen"`, `"gorm.io/gen/field"`, `"gorm.io/gorm"`, `"example.com/shop/model"`} {
+		if imports[path] != 1 {
+			t.Errorf("expect import %s, got %v", path, imports)
+		}
+	}
+
+	// code of all models is declared once in the same package
+	decls := make(map[string]int)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					decls[ts.Name.Name]++
+				}
+			}
+		case *ast.FuncDecl:
+			name := decl.Name.Name
+			if decl.Recv != nil {
+				name = types.ExprString(decl.Recv.List[0].Type) + "." + name
+			}
+			decls[name]++
+		}
+	}
+	for name, n := range decls {
+		if n != 1 {
+			t.Errorf("expect %s declared once, got %d", name, n)
+		}
+	}
+	for _, name := range []string{"Query", "queryCtx", "user", "userDo", "IUserDo", "order", "orderDo", "IOrderDo", "product", "productDo", "IProductDo",
+		"newUser", "newOrder", "newProduct", "userDo.First", "orderDo.First", "productDo.First"} {
+		if decls[name] != 1 {
+			t.Errorf("expect %s declared in query file", name)
+		}
+	}
+
+	// Query wires up all tables
+	for _, line := range []string{
+		"User    *user", "User = &Q.User",
+		"Order:   newOrder(db, opts...)", "Product: newProduct(db, opts...)", "User:    newUser(db, opts...)",
+		"Order   order", "Product product", "User    user",
+		"Order:   q.Order.clone(db)", "Product: q.Product.clone(db)", "User:    q.User.clone(db)",
+		"Order   IOrderDo", "Product IProductDo", "User    IUserDo",
+		"User:    q.User.WithContext(ctx)",
+	} {
+		if !bytes.Contains(content, []byte(line)) {
+			t.Errorf("expect %s in query file, got:\n%s", line, content)
+		}
+	}
+}
+
+func TestGenerator_BatchCreateWithAssociations(t *testing.T) {
+	omitted := "return u.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error"
+	testcases := []struct {
+		mode             GenerateMode
+		withAssociations bool
+		expect           string
+	}{
+		{WithDefaultQuery, false, "func (u userDo) CreateInBatches(values []*model.User, batchSize int) error {\n\t" + omitted},
+		{WithQueryInterface, false, "func (u userDo) CreateInBatches(values []*model.User, batchSize int) error {\n\t" + omitted},
+		{WithGeneric, false, "func (u *userDo) CreateInBatches(values []*model.User, batchSize int) error {\n\t" + omitted},
+		{WithQueryInterface, true, "func (u userDo) CreateInBatches(values []*model.User, batchSize int) error {\n\treturn u.DO.CreateInBatches(values, batchSize)"},
+	}
+	for _, tc := range testcases {
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), Mode: tc.mode, BatchCreateWithAssociations: tc.withAssociations})
+		g.UseTableInfo(shopTableInfo{})
+		g.ApplyBasic(g.GenerateModel("users"))
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		for _, f := range files {
+			if f.Path != filepath.Join(g.OutPath, "users.gen.go") {
+				continue
+			}
+			if !bytes.Contains(f.Content, []byte(tc.expect)) {
+				t.Errorf("expect %s in mode %d with associations %t, got:\n%s", tc.expect, tc.mode, tc.withAssociations, f.Content)
+			}
+			if tc.withAssociations && bytes.Contains(f.Content, []byte("Omit(clause.Associations)")) {
+				t.Errorf("expect CreateInBatches saving associations, got:\n%s", f.Content)
+			}
+		}
+	}
+}
+
+func TestGenerator_DefaultBatchSize(t *testing.T) {
+	for _, batchSize := range []int{0, 1000} {
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), DefaultBatchSize: batchSize, Mode: WithQueryInterface})
+		g.UseTableInfo(shopTableInfo{})
+		g.ApplyBasic(g.GenerateModel("users"))
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		for _, f := range files {
+			if f.Path != filepath.Join(g.OutPath, "users.gen.go") {
+				continue
+			}
+			expect := 0
+			if batchSize > 0 {
+				expect = 2
+				if !bytes.Contains(f.Content, []byte("batchSize = 1000")) {
+					t.Errorf("expect batch size fall back to 1000, got:\n%s", f.Content)
+				}
+			}
+			if n := bytes.Count(f.Content, []byte("BulkInsert(records []*model.User, batchSize int) (rowsAffected int64, err error)")); n != expect {
+				t.Errorf("expect %d BulkInsert in interface and query struct with default batch size %d, got %d", expect, batchSize, n)
+			}
+			if n := bytes.Count(f.Content, []byte("u.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(records, batchSize)")); n != expect/2 {
+				t.Errorf("expect BulkInsert omitting associations with default batch size %d, got:\n%s", batchSize, f.Content)
+			}
+		}
+	}
+
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), DefaultBatchSize: 1000, BatchCreateWithAssociations: true})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"))
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	for _, f := range files {
+		if f.Path == filepath.Join(g.OutPath, "users.gen.go") && !bytes.Contains(f.Content, []byte("u.DO.UnderlyingDB().CreateInBatches(records, batchSize)")) {
+			t.Errorf("expect BulkInsert saving associations, got:\n%s", f.Content)
+		}
+	}
+}
+
+func TestGenerator_ContextFirstArg(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), ContextFirstArg: true, Mode: WithDefaultQuery | WithQueryInterface})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	expects := map[string][]string{
+		g.OutFile: {
+			"func (q *Query) Transaction(ctx context.Context, fc func(tx *Query) error, opts ...*sql.TxOptions) error {",
+			"tx := q.db.WithContext(ctx).Begin(opts...)",
+		},
+		filepath.Join(g.OutPath, "users.gen.go"): {
+			"Where(conds ...gen.Condition) IUserDo",
+			"First(ctx context.Context) (*model.User, error)",
+			"Delete(ctx context.Context, models ...*model.User) (info gen.ResultInfo, err error)",
+			"func (u userDo) Count(ctx context.Context) (count int64, err error) {",
+			"if result, err := u.DO.WithContext(ctx).First(); err != nil {",
+			"count, err = u.Offset(-1).Limit(-1).Count(ctx)",
+			"CountBy(ctx context.Context, conds ...gen.Condition) (count int64, err error)",
+			"return u.Where(conds...).Count(ctx)",
+			"FindPage(ctx context.Context, offset int, limit int, conds ...gen.Condition) (result []*model.User, count int64, err error)",
+			"return u.Where(conds...).FindByPage(ctx, offset, limit)",
+		},
+	}
+	for _, f := range files {
+		lines, ok := expects[f.Path]
+		if !ok {
+			continue
+		}
+		delete(expects, f.Path)
+		if _, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, 0); err != nil {
+			t.Fatalf("generated query is invalid: %s", err)
+		}
+		for _, line := range lines {
+			if !bytes.Contains(f.Content, []byte(line)) {
+				t.Errorf("expect %s in %s, got:\n%s", line, f.Path, f.Content)
+			}
+		}
+	}
+	if len(expects) > 0 {
+		t.Errorf("expect files are not generated: %v", expects)
+	}
+
+	g = NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), ContextFirstArg: true, Mode: WithGeneric})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"))
+	if _, err := g.Plan(); err == nil || !strings.Contains(err.Error(), "ContextFirstArg is not supported") {
+		t.Errorf("expect ContextFirstArg rejected in generic mode, got %v", err)
+	}
+}
+
+func TestGenerator_ContextFirstArgOfInterfaceMethods(t *testing.T) {
+	ifaceFile := filepath.Join(t.TempDir(), "querier.go")
+	ifaceCode := `package diy
+
+import (
+	"database/sql"
+
+	"gorm.io/gen"
+)
+
+type Querier interface {
+	// select * from @@table where id=@id
+	FindByID(id int64) (gen.T, error)
+	// delete from @@table
+	DeleteAll() (sql.Result, error)
+	// select count(*) from @@table
+	CountRows() *sql.Row
+}
+
+type CtxQuerier interface {
+	// select * from @@table where id=@id
+	FindByIDCtx(ctx context.Context, id int64) (gen.T, error)
+}
+`
+	if err := os.WriteFile(ifaceFile, []byte(ifaceCode), 0o644); err != nil {
+		t.Fatalf("write interface file fail: %s", err)
+	}
+	applyIface := func(g *Generator, name string) {
+		users := g.GenerateModel("users")
+		readInterface := new(genparser.InterfaceSet)
+		err := readInterface.ParseFile([]*genparser.InterfacePath{{Name: name, FullName: "diy." + name, Files: []string{ifaceFile}}}, []string{users.ModelStructName})
+		if err != nil {
+			t.Fatalf("parse interface fail: %s", err)
+		}
+		g.ApplyBasic(users)
+		g.applyInterfaceSet(readInterface, []*generate.QueryStructMeta{users})
+	}
+
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), ContextFirstArg: true, Mode: WithDefaultQuery | WithQueryInterface})
+	g.UseTableInfo(shopTableInfo{})
+	applyIface(g, "Querier")
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var found bool
+	for _, f := range files {
+		if f.Path != filepath.Join(g.OutPath, "users.gen.go") {
+			continue
+		}
+		found = true
+		if _, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, 0); err != nil {
+			t.Fatalf("generated query is invalid: %s", err)
+		}
+		for _, line := range []string{
+			"FindByID(ctx context.Context, id int64) (result model.User, err error)",
+			"DeleteAll(ctx context.Context) (result sql.Result, err error)",
+			"func (u userDo) FindByID(ctx context.Context, id int64) (result model.User, err error) {",
+			"executeSQL = u.UnderlyingDB().WithContext(ctx).Raw(generateSQL.String(), params...).Take(&result)",
+			"result, err = stmt.ConnPool.ExecContext(ctx, generateSQL.String())",
+			"row = u.UnderlyingDB().WithContext(ctx).Raw(generateSQL.String()).Row()",
+		} {
+			if !bytes.Contains(f.Content, []byte(line)) {
+				t.Errorf("expect %s in %s, got:\n%s", line, f.Path, f.Content)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("expect users.gen.go generated")
+	}
+
+	g = NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), ContextFirstArg: true, Mode: WithDefaultQuery | WithQueryInterface})
+	g.UseTableInfo(shopTableInfo{})
+	applyIface(g, "CtxQuerier")
+	if _, err := g.Plan(); err == nil || !strings.Contains(err.Error(), "conflicts with the ctx added by ContextFirstArg") {
+		t.Errorf("expect param ctx rejected, got %v", err)
+	}
+}
+
+func TestGenerator_QueryInterfaceMethods(t *testing.T) {
+	parseOrders := func(cfg Config) *ast.File {
+		cfg.OutPath = filepath.Join(t.TempDir(), "query")
+		g := NewGenerator(cfg)
+		g.UseDB(db)
+		g.UseTableInfo(shopTableInfo{})
+		g.ApplyBasic(g.GenerateModel("orders"))
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		for _, f := range files {
+			if f.Path == filepath.Join(g.OutPath, "orders.gen.go") {
+				file, err := parser.ParseFile(token.NewFileSet(), f.Path, f.Content, 0)
+				if err != nil {
+					t.Fatalf("parse query file fail: %s", err)
+				}
+				return file
+			}
+		}
+		t.Fatal("query file of orders is not generated")
+		return nil
+	}
+
+	file := parseOrders(Config{Mode: WithQueryInterface})
+	if file.Scope.Lookup("IOrderDo") == nil {
+		t.Errorf("expect IOrderDo in WithQueryInterface mode")
+	}
+	for _, decl := range file.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.VAR {
+			t.Errorf("expect no assertion of orderDo without GenerateQueryInterfaces")
+		}
+	}
+	file = parseOrders(Config{WithUpsert: true, DefaultBatchSize: 100, GenerateQueryInterfaces: true})
+
+	ifaceMethods, doMethods := map[string]bool{}, map[string]bool{}
+	var asserted bool
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.TypeSpec:
+			if iface, ok := n.Type.(*ast.InterfaceType); ok && n.Name.Name == "IOrderDo" {
+				for _, m := range iface.Methods.List {
+					for _, name := range m.Names {
+						ifaceMethods[name.Name] = true
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			if n.Recv == nil || !n.Name.IsExported() {
+				return false
+			}
+			recv := n.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok && ident.Name == "orderDo" {
+				doMethods[n.Name.Name] = true
+			}
+		case *ast.ValueSpec: // var _ IOrderDo = (*orderDo)(nil)
+			if ident, ok := n.Type.(*ast.Ident); ok && ident.Name == "IOrderDo" && n.Names[0].Name == "_" {
+				asserted = true
+			}
+		}
+		return true
+	})
+	if !asserted {
+		t.Errorf("expect compile time assertion of orderDo implementing IOrderDo")
+	}
+	for name := range doMethods {
+		if !ifaceMethods[name] {
+			t.Errorf("exported method %s of orderDo is missing in IOrderDo", name)
+		}
+	}
+	for _, name := range []string{"Where", "Order", "Find", "First", "Create", "UpsertByColumns", "BulkInsert"} {
+		if !ifaceMethods[name] || !doMethods[name] {
+			t.Errorf("expect %s in both orderDo and IOrderDo", name)
+		}
+	}
+}
+
+func TestGenerator_ModelOnly(t *testing.T) {
+	dir := t.TempDir()
+	g := NewGenerator(Config{OutPath: filepath.Join(dir, "query"), Mode: WithDefaultQuery})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users", WithMode(ModelOnly)), g.GenerateModel("orders", WithMode(ModelWithQuery)))
+	g.Execute()
+
+	for file, expect := range map[string]bool{
+		"model/users.gen.go":  true,
+		"model/orders.gen.go": true,
+		"query/orders.gen.go": true,
+		"query/users.gen.go":  false,
+	} {
+		if _, err := os.Stat(filepath.Join(dir, file)); (err == nil) != expect {
+			t.Errorf("expect %s generated %t, got err: %v", file, expect, err)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "query", "gen.go"))
+	if err != nil {
+		t.Fatalf("read query file fail: %s", err)
+	}
+	if !bytes.Contains(content, []byte("Order *order")) || bytes.Contains(content, []byte("User ")) {
+		t.Errorf("expect query of orders only, got:\n%s", content)
+	}
+}
+
+func TestGenerator_WithColumnNames(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), WithColumnNames: true})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("orders"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var content string
+	for _, f := range files {
+		if filepath.Base(filepath.Dir(f.Path)) == "model" && filepath.Base(f.Path) == "orders.gen.go" {
+			content = string(f.Content)
+		}
+	}
+	expect := "// OrderColumns column names of table orders\n" +
+		"var OrderColumns = struct {\n" +
+		"\tID     string\n" +
+		"\tUserID string\n" +
+		"}{\n" +
+		"\tID:     \"id\",\n" +
+		"\tUserID: \"user_id\",\n" +
+		"}\n"
+	if !strings.Contains(content, expect) {
+		t.Errorf("expect column names in orders model:\n%s\ngot:\n%s", expect, content)
+	}
+	columns, _ := shopTableInfo{}.GetTableColumns("", "orders")
+	for _, col := range columns {
+		name := "OrderColumn" + db.NamingStrategy.SchemaName(col.Name())
+		if !regexp.MustCompile(fmt.Sprintf(`\t%s += %q\n`, name, col.Name())).MatchString(content) {
+			t.Errorf("expect constant %s of column %s in orders model, got:\n%s", name, col.Name(), content)
+		}
+	}
+
+	meta := &generate.QueryStructMeta{ModelStructName: "User", Fields: []*model.Field{
+		{Name: "Columns", ColumnName: "columns", Enum: model.NewEnum("UserColumns", []string{"a"})},
+	}}
+	if name := meta.ColumnNamesVar(); name != "UserColumnNames" {
+		t.Errorf("expect UserColumnNames when UserColumns is enum type, got %s", name)
+	}
+}
+
+func TestGenerator_TableNameInComment(t *testing.T) {
+	orderModel := func(cfg Config) string {
+		cfg.OutPath = filepath.Join(t.TempDir(), "query")
+		g := NewGenerator(cfg)
+		g.UseTableInfo(shopTableInfo{})
+		g.ApplyBasic(g.GenerateModel("orders"))
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		for _, f := range files {
+			if filepath.Base(filepath.Dir(f.Path)) == "model" && filepath.Base(f.Path) == "orders.gen.go" {
+				return string(f.Content)
+			}
+		}
+		return ""
+	}
+
+	cfg := Config{TableNameInComment: true}
+	cfg.WithDbNameOpts(func(*gorm.DB) string { return "shop" })
+	expect := "// Order mapped from table <orders>\n//\n// Order maps table \"shop\".\"orders\"\ntype Order struct {"
+	if content := orderModel(cfg); !strings.Contains(content, expect) {
+		t.Errorf("expect model doc comment:\n%s\ngot:\n%s", expect, content)
+	}
+	expect = "// Order mapped from table <orders>\ntype Order struct {"
+	if content := orderModel(Config{}); !strings.Contains(content, expect) {
+		t.Errorf("expect model doc comment unchanged:\n%s\ngot:\n%s", expect, content)
+	}
+}
+
+func TestGenerator_WithModelInterface(t *testing.T) {
+	cfg := Config{OutPath: filepath.Join(t.TempDir(), "query")}
+	cfg.WithModelInterface("GetID", "github.com/acme/repo", "Identifiable")
+	g := NewGenerator(cfg)
+	g.UseTableInfo(pkTableInfo{})
+	g.GenerateModel("members")
+	g.GenerateModel("user_roles")
+	g.GenerateModel("logs")
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	contents := make(map[string]string)
+	for _, f := range files {
+		contents[filepath.Base(f.Path)] = string(f.Content)
+	}
+	for file, expects := range map[string][]string{
+		"members.gen.go": {
+			"// GetID primary key value of Member\nfunc (m *Member) GetID() interface{} {\n\treturn m.ID\n}\n",
+			"var _ repo.Identifiable = (*Member)(nil)\n",
+			`"github.com/acme/repo"`,
+		},
+		"user_roles.gen.go": {
+			"func (u *UserRole) GetID() interface{} {\n\treturn []interface{}{u.UserID, u.RoleID}\n}\n",
+			"var _ repo.Identifiable = (*UserRole)(nil)\n",
+		},
+	} {
+		for _, expect := range expects {
+			if !strings.Contains(contents[file], expect) {
+				t.Errorf("expect %s contains:\n%s\ngot:\n%s", file, expect, contents[file])
+			}
+		}
+	}
+	if content := contents["logs.gen.go"]; strings.Contains(content, "GetID") || strings.Contains(content, "Identifiable") {
+		t.Errorf("expect no primary key method for table without primary key, got:\n%s", content)
+	}
+}
+
+func TestColumnConsts(t *testing.T) {
+	meta := &generate.QueryStructMeta{ModelStructName: "User", Fields: []*model.Field{
+		{Name: "Kind", ColumnName: "kind", Enum: model.NewEnum("UserColumnKind", []string{"a"})},
+		{Name: "KindA", ColumnName: "kind_a"},
+		{Name: "KindAlias", ColumnName: "kind"},
+	}}
+	expects := []generate.ColumnConst{{Name: "UserColumnKind2", Value: "kind"}, {Name: "UserColumnKindA2", Value: "kind_a"}}
+	if consts := meta.ColumnConsts(); !reflect.DeepEqual(consts, expects) {
+		t.Errorf("expect deduplicated constants %+v, got %+v", expects, consts)
+	}
+}
+
+func TestFilterTables(t *testing.T) {
+	tables := []string{"users", "orders", "schema_migrations", "orders_tmp", "events", "events_2023_01", "events_2024_01"}
+	fieldOpt := FieldIgnore("deleted_at")
+	testcases := []struct {
+		name   string
+		opts   []ModelOpt
+		expect []string
+	}{
+		{"none", nil, tables},
+		{"exclude", []ModelOpt{ExcludeTables("_migrations$", "_tmp$"), ExcludeTables(`^events_2023_`)},
+			[]string{"users", "orders", "events", "events_2024_01"}},
+		{"include", []ModelOpt{IncludeTables("^orders"), IncludeTables("^users$")}, []string{"users", "orders", "orders_tmp"}},
+		{"exclude wins", []ModelOpt{IncludeTables("^events"), ExcludeTables(`^events_\d{4}_`)}, []string{"events"}},
+		{"nothing included", []ModelOpt{IncludeTables("^products$")}, nil},
+	}
+	for _, tc := range testcases {
+		got, modelOpts := filterTables(tables, append(tc.opts, fieldOpt))
+		if !reflect.DeepEqual(got, tc.expect) {
+			t.Errorf("%s: expect tables %v, got %v", tc.name, tc.expect, got)
+		}
+		if len(modelOpts) != 1 {
+			t.Errorf("%s: expect only field option left, got %d options", tc.name, len(modelOpts))
+		}
+	}
+}
+
+// tablesDialector mysql dialector listing tables from memory
+type tablesDialector struct {
+	mysqlDialectors
+	tables []string
+}
+
+func (d tablesDialector) Migrator(*gorm.DB) gorm.Migrator { return tablesMigrator{tables: d.tables} }
+
+type tablesMigrator struct {
+	gorm.Migrator
+	tables []string
+}
+
+func (m tablesMigrator) GetTables() ([]string, error) { return m.tables, nil }
+
+func (tablesMigrator) TableType(interface{}) (gorm.TableType, error) {
+	return nil, errors.New("table type unsupported")
+}
+
+// recordTableInfo shop table metadata recording introspected tables
+type recordTableInfo struct {
+	shopTableInfo
+	tables *sync.Map
+}
+
+func (r recordTableInfo) GetTableColumns(schemaName string, tableName string) ([]*Column, error) {
+	r.tables.Store(tableName, true)
+	return r.shopTableInfo.GetTableColumns(schemaName, tableName)
+}
+
+func TestConfig_WithTableFilter(t *testing.T) {
+	tables := []string{"users", "orders", "flyway_schema_history", "schema_migrations", "orders_tmp"}
+	tableDB, err := gorm.Open(tablesDialector{tables: tables}, &gorm.Config{Logger: db.Logger})
+	if err != nil {
+		t.Fatalf("open db fail: %s", err)
+	}
+	cfg := Config{OutPath: filepath.Join(t.TempDir(), "query")}
+	cfg.WithTableFilter(regexp.MustCompile(`^(users|orders|flyway_)`), regexp.MustCompile(`^flyway_|_migrations$|_tmp$`))
+	g := NewGenerator(cfg)
+	g.UseDB(tableDB)
+	introspected := new(sync.Map)
+	g.UseTableInfo(recordTableInfo{tables: introspected})
+	g.ApplyBasic(g.GenerateAllTable()...)
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	generated := make(map[string]bool)
+	for _, f := range files {
+		generated[strings.TrimSuffix(filepath.Base(f.Path), ".gen.go")] = true
+	}
+	for _, table := range tables {
+		_, got := introspected.Load(table)
+		expect := table == "users" || table == "orders"
+		if got != expect || generated[table] != expect {
+			t.Errorf("expect table %s introspected and generated %t, got introspected %t and generated %t", table, expect, got, generated[table])
+		}
+	}
+}
+
+// failTableInfo shop tables failing to read columns of tables in fail
+type failTableInfo struct {
+	shopTableInfo
+	fail  map[string]bool
+	calls *int32
+}
+
+func (f failTableInfo) GetTableColumns(schemaName string, tableName string) ([]*Column, error) {
+	atomic.AddInt32(f.calls, 1)
+	if f.fail[tableName] {
+		return nil, errors.New("read columns of " + tableName + " fail")
+	}
+	time.Sleep(time.Millisecond) // let workers interleave
+	return f.shopTableInfo.GetTableColumns(schemaName, tableName)
+}
+
+func TestGenerator_IntrospectConcurrency(t *testing.T) {
+	var tables []string
+	for i := 0; i < 20; i++ {
+		tables = append(tables, fmt.Sprintf("table_%02d", i))
+	}
+	introspect := func(conf Config, fail map[string]bool) (metas []*generate.QueryStructMeta, calls int32, panicked interface{}) {
+		defer func() { panicked = recover() }()
+		g := NewGenerator(conf)
+		g.UseTableInfo(failTableInfo{fail: fail, calls: &calls})
+		_, metas = g.introspectTables(tables, nil)
+		return metas, calls, nil
+	}
+
+	metas, _, p := introspect(Config{IntrospectConcurrency: 4}, nil)
+	if p != nil {
+		t.Fatalf("introspect tables fail: %v", p)
+	}
+	for i, meta := range metas {
+		if meta == nil || meta.TableName != tables[i] {
+			t.Fatalf("expect table %s at %d, got %+v", tables[i], i, meta)
+		}
+	}
+
+	fail := map[string]bool{"table_03": true, "table_11": true}
+	_, calls, p := introspect(Config{IntrospectConcurrency: 4}, fail)
+	if p != "generate struct of 2 tables fail" || calls != int32(len(tables)) {
+		t.Errorf("expect errors of all tables reported after introspecting %d tables, got %v after %d", len(tables), p, calls)
+	}
+	_, calls, p = introspect(Config{IntrospectFailFast: true}, fail)
+	if p != "generate struct fail" || calls != 4 {
+		t.Errorf("expect fail fast after introspecting 4 tables, got %v after %d", p, calls)
+	}
+}
+
+func TestGenerator_IntrospectConcurrencyOutput(t *testing.T) {
+	var tables []string
+	for i := 0; i < 8; i++ {
+		tables = append(tables, fmt.Sprintf("table_%02d", i))
+	}
+	outPath := filepath.Join(t.TempDir(), "query")
+	plan := func(concurrency int) map[string][]byte {
+		g := NewGenerator(Config{OutPath: outPath, IntrospectConcurrency: concurrency, Mode: WithQueryInterface})
+		g.UseTableInfo(failTableInfo{calls: new(int32)})
+		confs, metas := g.introspectTables(tables, nil)
+		for i, meta := range metas {
+			g.ApplyBasic(g.addModel(confs[i], meta))
+		}
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files with concurrency %d fail: %s", concurrency, err)
+		}
+		contents := make(map[string][]byte, len(files))
+		for _, f := range files {
+			contents[f.Path] = f.Content
+		}
+		return contents
+	}
+
+	expects, got := plan(1), plan(4)
+	if len(expects) != 2*len(tables)+1 || len(got) != len(expects) {
+		t.Fatalf("expect %d files, got %d and %d", 2*len(tables)+1, len(expects), len(got))
+	}
+	for path, content := range expects {
+		if !bytes.Equal(got[path], content) {
+			t.Errorf("expect %s generated with concurrency 4 is the same as concurrency 1, got:\n%s\nexpect:\n%s", path, got[path], content)
+		}
+	}
+}
+
+// pluginFunc ModelPlugin of func
+type pluginFunc func(m *ModelMeta) error
+
+func (f pluginFunc) Apply(m *ModelMeta) error { return f(m) }
+
+func TestGenerator_Use(t *testing.T) {
+	var calls []string
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query")})
+	g.UseTableInfo(shopTableInfo{})
+	g.Use(pluginFunc(func(m *ModelMeta) error {
+		calls = append(calls, "validate:"+m.TableName)
+		for _, f := range m.Fields {
+			if f.ColumnName == "id" {
+				f.Tag.Set("validate", "required")
+			}
+		}
+		m.Fields = append(m.Fields, &ModelField{Name: "Score", Type: "decimal.Decimal", Tag: field.Tag{field.TagKeyJson: "score"}})
+		m.ImportPkgPaths = append(m.ImportPkgPaths, "github.com/shopspring/decimal")
+		return nil
+	}), pluginFunc(func(m *ModelMeta) error {
+		calls = append(calls, "drop:"+m.TableName)
+		fields := m.Fields[:0]
+		for _, f := range m.Fields {
+			if f.ColumnName != "user_id" {
+				fields = append(fields, f)
+			}
+		}
+		m.Fields = fields
+		return nil
+	}))
+	g.ApplyBasic(g.GenerateModel("orders"))
+
+	if expects := []string{"validate:orders", "drop:orders"}; !reflect.DeepEqual(calls, expects) {
+		t.Errorf("expect plugins applied in order %v, got %v", expects, calls)
+	}
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var content string
+	for _, f := range files {
+		if filepath.Base(filepath.Dir(f.Path)) == "model" && filepath.Base(f.Path) == "orders.gen.go" {
+			content = string(f.Content)
+		}
+	}
+	for _, expect := range []string{`validate:"required"`, `"github.com/shopspring/decimal"`, "Score decimal.Decimal"} {
+		if !regexp.MustCompile(strings.ReplaceAll(regexp.QuoteMeta(expect), " ", `\s+`)).MatchString(content) {
+			t.Errorf("expect %s in orders model, got:\n%s", expect, content)
+		}
+	}
+	if strings.Contains(content, "UserID") {
+		t.Errorf("expect field UserID removed by plugin, got:\n%s", content)
+	}
+
+	g = NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query")})
+	g.UseTableInfo(shopTableInfo{})
+	var applied bool
+	g.Use(pluginFunc(func(*ModelMeta) error { return errors.New("unsupported table") }),
+		pluginFunc(func(*ModelMeta) error { applied = true; return nil }))
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expect generating model panic when plugin returns error")
+			}
+		}()
+		g.GenerateModel("users")
+	}()
+	if applied {
+		t.Errorf("expect plugins after failed one not applied")
+	}
+
+	meta := &generate.QueryStructMeta{ModelStructName: "User", Fields: []*model.Field{{Name: "ID"}}}
+	if err := meta.ApplyPlugin(func(m *model.Meta) error {
+		m.Fields = append(m.Fields, &model.Field{Name: "ID"})
+		return nil
+	}); err == nil {
+		t.Errorf("expect error of duplicate field name")
+	}
+}
+
+// checkTableInfo shop tables with check constraint on orders
+type checkTableInfo struct{ shopTableInfo }
+
+func (checkTableInfo) GetTableCheckConstraints(_ string, tableName string) ([]*CheckConstraint, error) {
+	if tableName != "orders" {
+		return nil, nil
+	}
+	return []*CheckConstraint{
+		{Name: "chk_orders_user", Expression: "(`user_id` > 0)"},
+		{Name: "chk_orders_id", Expression: "((id >= 1) AND (id < 1000000))"},
+	}, nil
+}
+
+func TestGenerator_FieldWithCheckConstraints(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), FieldWithCheckConstraints: enabled})
+		g.UseTableInfo(checkTableInfo{})
+		g.ApplyBasic(g.GenerateModel("orders"), g.GenerateModel("users"))
+
+		files, err := g.Plan()
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		contents := make(map[string]string, len(files))
+		for _, f := range files {
+			contents[filepath.Base(filepath.Dir(f.Path))+"/"+filepath.Base(f.Path)] = string(f.Content)
+		}
+
+		expect := "// Order mapped from table <orders>\n" +
+			"//\n" +
+			"// Check constraints:\n" +
+			"//   - chk_orders_user: (`user_id` > 0)\n" +
+			"//   - chk_orders_id: ((id >= 1) AND (id < 1000000))\n" +
+			"type Order struct {"
+		if !enabled {
+			expect = "// Order mapped from table <orders>\ntype Order struct {"
+		}
+		if content := contents["model/orders.gen.go"]; !strings.Contains(content, expect) {
+			t.Errorf("FieldWithCheckConstraints=%t: expect %q in orders model, got:\n%s", enabled, expect, content)
+		}
+		if content := contents["model/users.gen.go"]; !strings.Contains(content, "// User mapped from table <users>\ntype User struct {") {
+			t.Errorf("FieldWithCheckConstraints=%t: expect users model without check constraints, got:\n%s", enabled, content)
+		}
+	}
+}
+
+// viewTableInfo table metadata of view order_summaries, index of view must not be read
+type viewTableInfo struct{ t *testing.T }
+
+func (viewTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	return []*Column{{ColumnType: migrator.ColumnType{
+		NameValue:       sql.NullString{String: "user_id", Valid: true},
+		DataTypeValue:   sql.NullString{String: "bigint", Valid: true},
+		NullableValue:   sql.NullBool{Bool: false, Valid: true},
+		PrimaryKeyValue: sql.NullBool{Bool: true, Valid: true},
+		ScanTypeValue:   reflect.TypeOf(int64(0)),
+	}, TableName: tableName}}, nil
+}
+
+func (v viewTableInfo) GetTableIndex(string, string) ([]gorm.Index, error) {
+	v.t.Errorf("index of view is read")
+	return nil, nil
+}
+
+func TestGenerator_GenerateModelFromView(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), FieldWithIndexTag: true, Mode: WithQueryInterface})
+	g.UseTableInfo(viewTableInfo{t})
+	g.ApplyBasic(g.GenerateModelFromView("order_summaries"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	for _, f := range files {
+		switch filepath.Base(filepath.Dir(f.Path)) {
+		case "model":
+			if line := "UserID int64 `gorm:\"column:user_id;type:bigint;not null\" json:\"user_id\"`"; !bytes.Contains(f.Content, []byte(line)) {
+				t.Errorf("expect field %s without primary key in view model, got:\n%s", line, f.Content)
+			}
+		case "query":
+			if filepath.Base(f.Path) != "order_summaries.gen.go" {
+				continue
+			}
+			if !bytes.Contains(f.Content, []byte("Find() ([]*model.OrderSummary, error)")) {
+				t.Errorf("expect Find in view query, got:\n%s", f.Content)
+			}
+			for _, method := range []string{"Create(", "Save(", "Delete(", "Update(", "Updates(", "FirstOrCreate("} {
+				if bytes.Contains(f.Content, []byte(method)) {
+					t.Errorf("expect no %s in read-only view query, got:\n%s", method, f.Content)
+				}
+			}
+		}
+	}
+
+	for _, mode := range []GenerateMode{WithDefaultQuery, WithGeneric} { // mutation methods are promoted by gen.DO or gen.IGenericsDo
+		g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), Mode: mode})
+		g.UseTableInfo(viewTableInfo{t})
+		g.ApplyBasic(g.GenerateModelFromView("order_summaries"))
+		if _, err := g.Plan(); err == nil || !strings.Contains(err.Error(), "view order_summaries is read-only only in WithQueryInterface mode") {
+			t.Errorf("expect view rejected in mode %d, got %v", mode, err)
+		}
+	}
+}
+
+func TestGenerator_EmbedBaseModel(t *testing.T) {
+	g := NewGenerator(Config{
+		OutPath:        filepath.Join(t.TempDir(), "query"),
+		EmbedBaseModel: BaseModelSpec{Type: "example.com/shop/base.Entity", Columns: []string{"id", "user_id"}},
+	})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"), g.GenerateModel("orders"))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	contents := make(map[string]string, len(files))
+	for _, f := range files {
+		contents[filepath.Base(filepath.Dir(f.Path))+"/"+filepath.Base(f.Path)] = string(f.Content)
+	}
+	testcases := []struct {
+		file          string
+		expect, avoid []string
+	}{
+		{"model/orders.gen.go", []string{`"example.com/shop/base"`, "type Order struct {\n\tbase.Entity\n}"}, []string{"ID int64", "UserID int64"}},
+		{"model/users.gen.go", []string{"ID int64 `gorm:\"column:id;type:bigint;not null\" json:\"id\"`"}, []string{"base.Entity"}}, // users has no user_id column
+		{"query/orders.gen.go", []string{"ID     field.Int64", "UserID field.Int64"}, nil},
+	}
+	for _, tc := range testcases {
+		content, ok := contents[tc.file]
+		if !ok {
+			t.Fatalf("%s is not generated", tc.file)
+		}
+		for _, s := range tc.expect {
+			if !strings.Contains(content, s) {
+				t.Errorf("expect %s in %s, got:\n%s", s, tc.file, content)
+			}
+		}
+		for _, s := range tc.avoid {
+			if strings.Contains(content, s) {
+				t.Errorf("expect no %s in %s, got:\n%s", s, tc.file, content)
+			}
+		}
+	}
+}
+
+func TestGenerator_FieldGormType(t *testing.T) {
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), FieldWithTypeTag: true})
+	g.UseTableInfo(shopTableInfo{})
+	g.ApplyBasic(g.GenerateModel("orders",
+		FieldType("user_id", "types.UserRef"), FieldGormType("user_id", "json"),
+		FieldType("id", "uint64"),
+	))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var content string
+	for _, f := range files {
+		if filepath.Base(filepath.Dir(f.Path)) == "model" && filepath.Base(f.Path) == "orders.gen.go" {
+			content = string(f.Content)
+		}
+	}
+	for _, expect := range []string{
+		"UserID types.UserRef `gorm:\"column:user_id;type:json;not null\" json:\"user_id\"`",
+		"ID     uint64        `gorm:\"column:id;type:bigint;not null\" json:\"id\"`", // FieldType only keeps db type
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("expect %s in orders model, got:\n%s", expect, content)
+		}
+	}
+}
+
+func TestConfig_WithFileModifier(t *testing.T) {
+	marker := "// Code generated by gorm.io/gen. DO NOT EDIT."
+	testcases := []struct {
+		modifier func(path string, content []byte) ([]byte, error)
+		err      string
+	}{
+		{func(_ string, content []byte) ([]byte, error) {
+			return bytes.ReplaceAll(content, []byte(marker), []byte(strings.ToUpper(marker))), nil
+		}, ""},
+		{func(string, []byte) ([]byte, error) { return nil, errors.New("license not found") }, "license not found"},
+		{func(_ string, content []byte) ([]byte, error) { return append(content, "func {"...), nil }, "returned by file modifier"},
+	}
+	for _, tc := range testcases {
+		cfg := Config{OutPath: filepath.Join(t.TempDir(), "query")}
+		cfg.WithFileModifier(tc.modifier)
+		g := NewGenerator(cfg)
+		g.UseTableInfo(shopTableInfo{})
+		g.ApplyBasic(g.GenerateModel("users"))
+
+		files, err := g.Plan()
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expect error %q, got %v", tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("plan generated files fail: %s", err)
+		}
+		for _, f := range files {
+			if bytes.Contains(f.Content, []byte(marker)) || !bytes.Contains(f.Content, []byte(strings.ToUpper(marker))) {
+				t.Errorf("expect marker of %s modified, got:\n%s", f.Path, f.Content)
+			}
+		}
+	}
+}
+
+func TestConfig_WithFileModifierDryRun(t *testing.T) {
+	license := "// Copyright Acme Inc.\n\n"
+	modifier := func(_ string, src []byte) ([]byte, error) { return append([]byte(license), src...), nil }
+	newGenerator := func(outPath string, modifier func(string, []byte) ([]byte, error)) *Generator {
+		cfg := Config{OutPath: outPath}
+		cfg.WithFileModifier(modifier)
+		g := NewGenerator(cfg)
+		g.UseTableInfo(shopTableInfo{})
+		g.ApplyBasic(g.GenerateModel("users"))
+		return g
+	}
+
+	outPath := filepath.Join(t.TempDir(), "query")
+	newGenerator(outPath, modifier).Execute()
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(outPath), "model", "users.gen.go"))
+	if err != nil {
+		t.Fatalf("read generated model fail: %s", err)
+	}
+	if !bytes.HasPrefix(content, []byte(license)) {
+		t.Errorf("expect modified content written, got:\n%s", content)
+	}
+
+	changes, err := newGenerator(outPath, modifier).ExecuteDryRun()
+	if err != nil || len(changes) != 0 {
+		t.Errorf("expect file modifier applied in dry run without change, got %+v %v", changes, err)
+	}
+
+	_, err = newGenerator(outPath, func(path string, src []byte) ([]byte, error) {
+		return nil, errors.New("license not found")
+	}).ExecuteDryRun()
+	if err == nil || !strings.Contains(err.Error(), "license not found") || !strings.Contains(err.Error(), ".gen.go") {
+		t.Errorf("expect file modifier error with file path, got %v", err)
+	}
+}
+
+func TestConfig_WithInitialisms(t *testing.T) {
+	cfg := Config{}
+	cfg.WithInitialisms("OAuth2")
+	g := NewGenerator(cfg)
+	g.UseTableInfo(shopTableInfo{})
+
+	if meta := g.GenerateModel("oauth2_tokens"); meta.ModelStructName != "OAuth2Token" {
+		t.Errorf("expect model name OAuth2Token, got %s", meta.ModelStructName)
+	}
+}
+
+func TestConfig_FieldTypeByName(t *testing.T) {
+	cfg := Config{}
+	cfg.FieldTypeByName(regexp.MustCompile(`_cents$`), "*github.com/acme/money/v2.Money")
+	cfg.FieldTypeByName(regexp.MustCompile(`^price`), "gopkg.in/decimal.v1.Decimal")
+	cfg.FieldTypeByName(regexp.MustCompile(`_at$`), "time.Time")
+
+	expectTypes := []string{"*money.Money", "decimal.Decimal", "time.Time"}
+	for i, rule := range cfg.fieldTypeRules {
+		if rule.GoType != expectTypes[i] {
+			t.Errorf("rule %d expects type %s, got %s", i, expectTypes[i], rule.GoType)
+		}
+	}
+	expectImports := []string{`"github.com/acme/money/v2"`, `"gopkg.in/decimal.v1"`}
+	if !reflect.DeepEqual(cfg.importPkgPaths, expectImports) {
+		t.Errorf("expect imports %v, got %v", expectImports, cfg.importPkgPaths)
+	}
+}
+
+func TestConfig_DateType(t *testing.T) {
+	testcases := []struct {
+		goType, importPath string
+		expectType         string
+		expectImports      []string
+	}{
+		{"civil.Date", "cloud.google.com/go/civil", "civil.Date", []string{`"cloud.google.com/go/civil"`}},
+		{"*Date", "cloud.google.com/go/civil", "*civil.Date", []string{`"cloud.google.com/go/civil"`}},
+		{"github.com/acme/date/v2.Date", "", "date.Date", []string{`"github.com/acme/date/v2"`}},
+		{"LocalDate", "", "LocalDate", nil},
+	}
+	for _, tc := range testcases {
+		cfg := Config{}
+		cfg.DateType(tc.goType, tc.importPath)
+		if cfg.dateType != tc.expectType {
+			t.Errorf("DateType(%q, %q) expects type %s, got %s", tc.goType, tc.importPath, tc.expectType, cfg.dateType)
+		}
+		if !reflect.DeepEqual(cfg.importPkgPaths, tc.expectImports) {
+			t.Errorf("DateType(%q, %q) expects imports %v, got %v", tc.goType, tc.importPath, tc.expectImports, cfg.importPkgPaths)
+		}
+	}
+}
+
+func TestConfig_WithValueObjectType(t *testing.T) {
+	cfg := Config{OutPath: filepath.Join(t.TempDir(), "query")}
+	cfg.WithValueObjectType(regexp.MustCompile(`^user_id$`), "UserID", "github.com/acme/domain")
+	cfg.WithValueObjectType(regexp.MustCompile(`id$`), "*domain.ID", "github.com/acme/domain")
+	g := NewGenerator(cfg)
+	g.UseTableInfo(shopTableInfo{})
+	g.GenerateModel("orders")
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var content string
+	for _, f := range files {
+		if filepath.Base(filepath.Dir(f.Path)) == "model" {
+			content = string(f.Content)
+		}
+	}
+	for _, expect := range []string{`UserID\s+domain\.UserID\s`, `ID\s+\*domain\.ID\s`} {
+		if !regexp.MustCompile(expect).MatchString(content) {
+			t.Errorf("expect field matching %s, got:\n%s", expect, content)
+		}
+	}
+	if n := strings.Count(content, `"github.com/acme/domain"`); n != 1 {
+		t.Errorf("expect import of value object package once, got %d in:\n%s", n, content)
+	}
+}
+
+func TestConfig_SoftDeleteFields(t *testing.T) {
+	testcases := []struct {
+		cfg    Config
+		expect []string
+	}{
+		{Config{}, []string{"deleted_at"}},
+		{Config{SoftDeleteField: "removed_at", SoftDeleteFields: []string{"archived_at"}}, []string{"removed_at"}},
+		{Config{DetectSoftDelete: true}, []string{"deleted_at"}},
+		{Config{DetectSoftDelete: true, SoftDeleteFields: []string{"removed_at", "archived_at"}}, []string{"deleted_at", "removed_at", "archived_at"}},
+	}
+	for _, tc := range testcases {
+		g := NewGenerator(tc.cfg)
+		if names := g.genModelConfig("users", "User", nil).FieldSoftDeleteNames; !reflect.DeepEqual(names, tc.expect) {
+			t.Errorf("soft delete fields expect %v, got %v", tc.expect, names)
+		}
+	}
+}
+
+// postgresTablesDialector postgres dialector listing tables from memory
+type postgresTablesDialector struct{ tablesDialector }
+
+func (postgresTablesDialector) Name() string { return "postgres" }
+
+// arrayTableInfo table metadata of tables with id and tags array column
+type arrayTableInfo struct{ shopTableInfo }
+
+func (arrayTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	column := func(name, dataType, columnType string) *Column {
+		return &Column{ColumnType: migrator.ColumnType{
+			NameValue:       sql.NullString{String: name, Valid: true},
+			DataTypeValue:   sql.NullString{String: dataType, Valid: true},
+			ColumnTypeValue: sql.NullString{String: columnType, Valid: true},
+			NullableValue:   sql.NullBool{Bool: false, Valid: true},
+		}, TableName: tableName}
+	}
+	return []*Column{column("id", "bigint", "bigint"), column("tags", "_varchar", "character varying(32)[]")}, nil
+}
+
+func TestGenerator_FromDB(t *testing.T) {
+	oltp, _ := gorm.Open(tablesDialector{tables: []string{"users"}}, nil)
+	analytics, _ := gorm.Open(postgresTablesDialector{tablesDialector{tables: []string{"events", "visits"}}}, nil)
+
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), PostgresArrayLib: ArrayLibPQ})
+	g.UseDB(oltp)
+	g.UseNamedDB("analytics", analytics)
+	g.UseTableInfo(arrayTableInfo{})
+	g.ApplyBasic(g.GenerateModel("users"))
+	g.ApplyBasic(g.FromDB("analytics").GenerateAllTable()...)
+	if g.db != oltp {
+		t.Errorf("expect db of generator restored after generating from data source")
+	}
+
+	for name, expect := range map[string]string{"User": "string", "Event": "pq.StringArray", "Visit": "pq.StringArray"} {
+		meta := g.models[name]
+		if meta == nil {
+			t.Errorf("model %s is not generated", name)
+			continue
+		}
+		if typ := meta.Fields[1].Type; typ != expect {
+			t.Errorf("model %s expects tags of type %s, got %s", name, expect, typ)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expect panic for data source not registered")
+		}
+	}()
+	g.FromDB("reporting")
+}
+
+// jsonTableInfo table metadata of tables with id and metadata jsonb column
+type jsonTableInfo struct{ shopTableInfo }
+
+func (jsonTableInfo) GetTableColumns(_ string, tableName string) ([]*Column, error) {
+	column := func(name, dataType string) *Column {
+		return &Column{ColumnType: migrator.ColumnType{
+			NameValue:     sql.NullString{String: name, Valid: true},
+			DataTypeValue: sql.NullString{String: dataType, Valid: true},
+			NullableValue: sql.NullBool{Bool: false, Valid: true},
+		}, TableName: tableName}
+	}
+	return []*Column{column("id", "bigint"), column("metadata", "jsonb")}, nil
+}
+
+func TestGenerator_FieldJSONPath(t *testing.T) {
+	postgresDB, _ := gorm.Open(postgresTablesDialector{}, nil)
+	g := NewGenerator(Config{OutPath: filepath.Join(t.TempDir(), "query"), Mode: WithQueryInterface})
+	g.UseDB(postgresDB)
+	g.UseTableInfo(jsonTableInfo{})
+	g.ApplyBasic(g.GenerateModel("events", FieldJSONPath("metadata", "tenant_id", "TenantID", "string")))
+
+	files, err := g.Plan()
+	if err != nil {
+		t.Fatalf("plan generated files fail: %s", err)
+	}
+	var content string
+	for _, f := range files {
+		if f.Path == filepath.Join(g.OutPath, "events.gen.go") {
+			content = string(f.Content)
+		}
+	}
+	if n := strings.Count(content, "WhereTenantID(value string) IEventDo"); n != 2 {
+		t.Errorf("expect WhereTenantID in interface and query struct, got %d in:\n%s", n, content)
+	}
+	expect := `return e.Where(gen.Cond(clause.Expr{SQL: "?->>'tenant_id' = ?", Vars: []interface{}{clause.Column{Table: e.TableName(), Name: "metadata"}, value}})...)`
+	if !strings.Contains(content, expect) {
+		t.Errorf("expect %s in query of events, got:\n%s", expect, content)
+	}
+}
+
 // test data
 type mysqlDialectors struct{ tests.DummyDialector }
 
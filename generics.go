@@ -358,6 +358,24 @@ func (b GenericsDo[T, E]) FirstOrCreate() (E, error) {
 	return v, nil
 }
 
+// FirstOrInitWith ...
+func (b GenericsDo[T, E]) FirstOrInitWith(seed E) (E, error) {
+	if err := b.DO.FirstOrInitWith(seed); err != nil {
+		var e E
+		return e, err
+	}
+	return seed, nil
+}
+
+// FirstOrCreateWith ...
+func (b GenericsDo[T, E]) FirstOrCreateWith(seed E) (E, error) {
+	if err := b.DO.FirstOrCreateWith(seed); err != nil {
+		var e E
+		return e, err
+	}
+	return seed, nil
+}
+
 // FindByPage ...
 func (b GenericsDo[T, E]) FindByPage(offset int, limit int) (result []E, count int64, err error) {
 	result, err = b.Offset(offset).Limit(limit).Find()
@@ -374,6 +392,21 @@ func (b GenericsDo[T, E]) FindByPage(offset int, limit int) (result []E, count i
 	return
 }
 
+// FindPage find records matching conds in page and count all of them, soft deleted records are excluded unless Unscoped.
+// All matching records are returned without paging when limit <= 0, offset is ignored
+func (b GenericsDo[T, E]) FindPage(offset int, limit int, conds ...Condition) (result []E, count int64, err error) {
+	if limit <= 0 {
+		result, err = b.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return b.Where(conds...).FindByPage(offset, limit)
+}
+
+// CountBy count records matching conds, soft deleted records are excluded unless Unscoped
+func (b GenericsDo[T, E]) CountBy(conds ...Condition) (count int64, err error) {
+	return b.Where(conds...).Count()
+}
+
 // ScanByPage ...
 func (b GenericsDo[T, E]) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = b.Count()
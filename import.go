@@ -1,6 +1,10 @@
 package gen
 
-import "strings"
+import (
+	"path"
+	"regexp"
+	"strings"
+)
 
 var (
 	importList = new(importPkgS).Add(
@@ -65,3 +69,28 @@ func (ip importPkgS) Add(paths ...string) *importPkgS {
 }
 
 func (ip importPkgS) Paths() []string { return ip.paths }
+
+var majorVersionReg = regexp.MustCompile(`^v[0-9]+$`)
+
+// splitTypeImport split import path qualified type, e.g. *github.com/acme/money.Money => (github.com/acme/money, *money.Money)
+// pkgPath is empty when goType is not qualified with import path
+func splitTypeImport(goType string) (pkgPath string, typ string) {
+	rest := strings.TrimLeft(goType, "*[]")
+	prefix := goType[:len(goType)-len(rest)]
+
+	slash, dot := strings.LastIndex(rest, "/"), strings.LastIndex(rest, ".")
+	if slash < 0 || dot < slash {
+		return "", goType
+	}
+	pkgPath = rest[:dot]
+
+	pkgName := path.Base(pkgPath)
+	if majorVersionReg.MatchString(pkgName) { // e.g. github.com/acme/money/v2
+		pkgName = path.Base(path.Dir(pkgPath))
+	}
+	if i := strings.Index(pkgName, "."); i > 0 { // e.g. gopkg.in/yaml.v3
+		pkgName = pkgName[:i]
+	}
+	pkgName = strings.ReplaceAll(pkgName, "-", "_")
+	return pkgPath, prefix + pkgName + rest[dot:]
+}
@@ -160,8 +160,10 @@ func (s TrimClause) Finish(name string) string {
 type ForClause struct {
 	clause
 	Value    []Clause
+	Empty    []Clause // clauses written when range list is empty
 	ForRange ForRange
 	forSlice section
+	hasEmpty bool
 }
 
 func (f ForClause) String() string {
@@ -173,6 +175,20 @@ func (f ForClause) Create() string {
 	return f.String()
 }
 
+// appendClause append clause to range loop, or to empty clauses after {{else}}
+func (f *ForClause) appendClause(c Clause) {
+	if f.hasEmpty {
+		f.Empty = append(f.Empty, c)
+	} else {
+		f.Value = append(f.Value, c)
+	}
+}
+
+// CreateEmpty create clause written when range list is empty, it follows the range loop
+func (f ForClause) CreateEmpty() string {
+	return fmt.Sprintf("}\nif len(%s) == 0 {", f.forSlice.ForRange.rangeList)
+}
+
 // Finish finish clause
 func (f ForClause) Finish() string {
 	return "}"
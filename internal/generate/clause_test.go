@@ -1,8 +1,10 @@
 package generate
 
 import (
+	"strings"
 	"testing"
 
+	"gorm.io/gen/internal/model"
 	"gorm.io/gen/internal/parser"
 )
 
@@ -158,6 +160,125 @@ func TestClause(t *testing.T) {
 	}
 }
 
+func TestClauseNamedParams(t *testing.T) {
+	method := func() *InterfaceMethod {
+		return &InterfaceMethod{MethodName: "FindByNameAge", Table: "users", Params: []parser.Param{
+			{Name: "name", Type: "string"},
+			{Name: "age", Type: "int"},
+		}}
+	}
+
+	checkBuildExpr(t, "SELECT * FROM @@table WHERE name = @name AND age > @age OR nick = @name",
+		[]string{`"SELECT * FROM "`, `"users"`, `" WHERE name = "`, "name", `" AND age > "`, "age", `" OR nick = "`, "name"},
+		[]string{
+			"params = append(params,name)",
+			"params = append(params,age)",
+			"params = append(params,name)",
+			`generateSQL.WriteString("SELECT * FROM users WHERE name = ? AND age > ? OR nick = ? ")`,
+		}, method())
+
+	i := method()
+	i.SQLString = "SELECT * FROM @@table WHERE name = @name AND email = @email"
+	if err := i.sqlStateCheckAndSplit(); err == nil || !strings.Contains(err.Error(), "email") {
+		t.Errorf("expect error of unknown param email, got %v", err)
+	}
+}
+
+func TestClauseNestedIf(t *testing.T) {
+	checkBuildExpr(t, "select * from @@table {{if id > 0}}a {{if name != \"\"}}b{{else if len(names) > 0}}c{{if id > 1}}d{{end}}{{else}}e{{end}}{{end}}",
+		[]string{`"select * from "`, `"users"`, "if id > 0", `"a "`, `if name != ""`, `"b"`, "else if len(names) > 0", `"c"`, "if id > 1", `"d"`, "end", "else", `"e"`, "end", "end"},
+		[]string{
+			`generateSQL.WriteString("select * from users ")`,
+			"if id > 0 {",
+			`generateSQL.WriteString("a ")`,
+			`if name != "" {`,
+			`generateSQL.WriteString("b ")`,
+			"} else if len(names) > 0 {",
+			`generateSQL.WriteString("c ")`,
+			"if id > 1 {",
+			`generateSQL.WriteString("d ")`,
+			"}",
+			"} else {",
+			`generateSQL.WriteString("e ")`,
+			"}",
+			"}",
+		}, m())
+}
+
+func TestClauseForEmpty(t *testing.T) {
+	checkBuildExpr(t, "select * from @@table {{where}}{{for _,name:=range names}} or name=@name{{else}}1=0{{end}}{{end}}",
+		[]string{`"select * from "`, `"users"`, "where", "for _,name:=range names", `" or name="`, "name", "else", `"1=0"`, "end", "end"},
+		[]string{
+			`generateSQL.WriteString("select * from users ")`,
+			"var whereSQL0 strings.Builder",
+			"for _,name:=range names{",
+			"params = append(params,name)",
+			`whereSQL0.WriteString("or name=? ")`,
+			"}\nif len(names) == 0 {",
+			`whereSQL0.WriteString("1=0 ")`,
+			"}",
+			"helper.JoinWhereBuilder(&generateSQL,whereSQL0)",
+		}, m())
+
+	i := m()
+	i.SQLString = "select * from @@table where {{for _,name:=range names}}name=@name{{else if id > 0}}id=@id{{end}}"
+	if err := i.sqlStateCheckAndSplit(); err != nil {
+		t.Fatalf("split sql fail: %s", err)
+	}
+	if _, err := i.Section.BuildSQL(); err == nil || !strings.Contains(err.Error(), "{{else if id > 0}} at line 1") {
+		t.Errorf("expect error of else if in for range, got %v", err)
+	}
+
+	testcases := []struct {
+		SQL string
+		Err string
+	}{
+		{"select * from @@table where {{for _,id:=range ids}}{{if id > 0}}a{{else}}id=@id{{end}}{{end}}", ""},
+		{"select * from @@table where {{for _,id:=range ids}}id=@id{{else}}x=@id{{end}}", "unknown variable param: id"},
+		{"select * from @@table where {{for i,id:=range ids}}id=@id{{else}}x=@i{{end}}", "unknown variable param: i"},
+		{"select * from @@table where {{for _,id:=range ids}}id=@id{{end}} and x=@id", "unknown variable param: id"},
+	}
+	for _, testcase := range testcases {
+		i := &InterfaceMethod{MethodName: "FindByIDs", Table: "users", Params: []parser.Param{{Name: "ids", Type: "int", IsArray: true}}}
+		i.SQLString = testcase.SQL
+		err := i.sqlStateCheckAndSplit()
+		if err == nil {
+			_, err = i.Section.BuildSQL()
+		}
+		if testcase.Err == "" && err != nil {
+			t.Errorf("SQL %q expects no error, got %s", testcase.SQL, err)
+		}
+		if testcase.Err != "" && (err == nil || !strings.Contains(err.Error(), testcase.Err)) {
+			t.Errorf("SQL %q expects error %q, got %v", testcase.SQL, testcase.Err, err)
+		}
+	}
+}
+
+func TestClauseNotClosed(t *testing.T) {
+	testcases := []struct {
+		SQL string
+		Err string
+	}{
+		{"select * from @@table\n{{if id > 0}}\nid=@id", "{{if id > 0}} at line 2 is not closed by {{end}}"},
+		{"select * from @@table\n{{where}}\n{{if id > 0}}id=@id{{end}}", "{{where}} at line 2 is not closed by {{end}}"},
+		{"select * from @@table\n{{for _,name:=range names}}\nname=@name", "{{for _,name:=range names}} at line 2 is not closed by {{end}}"},
+		{"select * from @@table {{if id > 0}}\n{{if name != \"\"}}\nname=@name\n{{end}}", "{{if id > 0}} at line 1 is not closed by {{end}}"},
+		{"select * from @@table\n{{if id > 0}}id=@id{{end}}\n{{end}}", "unexpected {{end}} at line 3"},
+		{"select * from @@table\nwhere {{if id > 0}} id=@id {{end}\nlimit 1", "{{ at line 2 is not closed by }}: where {{if id > 0}} id=@id {{end}"},
+	}
+	for _, testcase := range testcases {
+		i := m()
+		i.SQLString = testcase.SQL
+		err := i.sqlStateCheckAndSplit()
+		if err == nil {
+			_, err = i.Section.BuildSQL()
+		}
+		if err == nil || !strings.Contains(err.Error(), testcase.Err) {
+			t.Errorf("SQL %q expects error %q, got %v", testcase.SQL, testcase.Err, err)
+		}
+	}
+}
+
 var m = func() *InterfaceMethod {
 	var m = new(InterfaceMethod)
 	m.Table = "users"
@@ -180,3 +301,51 @@ var m = func() *InterfaceMethod {
 	return m
 
 }
+
+func TestRenameDIYMethod(t *testing.T) {
+	s := &QueryStructMeta{S: "u", ModelStructName: "User", QueryStructName: "user", TableName: "users",
+		StructInfo: parser.Param{Type: "User", Package: "model"}, Fields: []*model.Field{{Name: "Name"}}}
+	method := func(name, doc string) *parser.Method {
+		return &parser.Method{MethodName: name, Doc: doc,
+			Params: []parser.Param{{Name: "name", Type: "string"}},
+			Result: []parser.Param{{Type: "T", Package: "gen"}, {Type: "error"}}}
+	}
+	build := func(methods ...*parser.Method) []*InterfaceMethod {
+		set := &parser.InterfaceSet{Interfaces: []parser.InterfaceInfo{{Name: "Querier", Methods: methods, ApplyStruct: []string{"User"}}}}
+		result, err := BuildDIYMethod(set, s, nil)
+		if err != nil {
+			t.Fatalf("build diy method fail: %s", err)
+		}
+		return result
+	}
+	trimGet := func(name string) string { return strings.TrimPrefix(name, "Get") }
+
+	methods := build(method("GetByName", "GetByName SELECT * FROM @@table WHERE name=@name"))
+	if err := RenameDIYMethod(methods, s, nil, trimGet); err != nil {
+		t.Fatalf("rename diy method fail: %s", err)
+	}
+	m := methods[0]
+	if m.MethodName != "ByName" || !strings.HasPrefix(m.DocComment(), "ByName ") {
+		t.Errorf("expect method and doc renamed to ByName, got %s: %s", m.MethodName, m.DocComment())
+	}
+	if m.SQLString != "SELECT * FROM @@table WHERE name=@name" {
+		t.Errorf("expect sql parsed with original name, got %s", m.SQLString)
+	}
+
+	testcases := []struct {
+		methods []*InterfaceMethod
+		ns      func(string) string
+		err     string
+	}{
+		{build(method("GetByName", "SELECT * FROM @@table WHERE name=@name"),
+			method("ByName", "SELECT * FROM @@table WHERE name=@name")), trimGet, "same name ByName"},
+		{build(method("GetName", "SELECT * FROM @@table WHERE name=@name")), trimGet, "same name with struct field"},
+		{build(method("GetFirst", "SELECT * FROM @@table WHERE name=@name")), trimGet, "keyword"},
+		{build(method("GetByName", "SELECT * FROM @@table WHERE name=@name")), strings.ToLower, "invalid name"},
+	}
+	for _, tc := range testcases {
+		if err := RenameDIYMethod(tc.methods, s, nil, tc.ns); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("expect error %q, got %v", tc.err, err)
+		}
+	}
+}
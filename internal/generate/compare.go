@@ -0,0 +1,24 @@
+package generate
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersion compare dotted version with minVersion, missing or invalid parts are treated as 0
+func CompareVersion(version string, minVersion []int) int {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	for i, m := range minVersion {
+		var v int
+		if i < len(parts) {
+			v, _ = strconv.Atoi(parts[i])
+		}
+		if v != m {
+			if v < m {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -3,12 +3,13 @@ package generate
 import (
 	"context"
 	"fmt"
+	"go/token"
 	"reflect"
+	"sort"
 	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
-	"gorm.io/gorm/utils/tests"
 
 	"gorm.io/gen/field"
 	"gorm.io/gen/helper"
@@ -18,8 +19,8 @@ import (
 
 // GetQueryStructMeta generate db model by table name
 func GetQueryStructMeta(db *gorm.DB, conf *model.Config) (*QueryStructMeta, error) {
-	if _, ok := db.Config.Dialector.(tests.DummyDialector); ok {
-		return nil, fmt.Errorf("UseDB() is necessary to generate model struct [%s] from database table [%s]", conf.ModelName, conf.TableName)
+	if conf.TableInfo == nil && !hasConn(db) {
+		return nil, fmt.Errorf("UseDB() or UseTableInfo() is necessary to generate model struct [%s] from database table [%s]", conf.ModelName, conf.TableName)
 	}
 
 	conf = conf.Preprocess()
@@ -31,24 +32,95 @@ func GetQueryStructMeta(db *gorm.DB, conf *model.Config) (*QueryStructMeta, erro
 		return nil, fmt.Errorf("model name %q is invalid: %w", structName, err)
 	}
 
-	columns, err := getTableColumns(db, conf.GetSchemaName(db), tableName, conf.FieldWithIndexTag)
+	if err := checkJSONTypes(conf.FieldJSONTypes); err != nil {
+		return nil, fmt.Errorf("table %s: %w", tableName, err)
+	}
+
+	schemaName := conf.GetSchemaName(db)
+	columns, err := getTableColumns(db, conf, schemaName, tableName)
 	if err != nil {
 		return nil, err
 	}
 
+	fields := getFields(db, conf, columns)
+	if err := applyTagProviders(conf.FieldTagProviders, fields); err != nil {
+		return nil, fmt.Errorf("table %s: %w", tableName, err)
+	}
+	fields = embedBaseModel(conf.FieldEmbedBaseModel, fields)
+	var foreignKeys []*model.ForeignKey
+	if conf.FieldWithForeignKeyRelations || conf.FieldWithReverseRelations {
+		foreignKeys, err = getTableForeignKeys(db, conf, schemaName, tableName)
+		if err != nil { // ignore find foreign key err
+			if conf.Context.Err() != nil {
+				return nil, conf.Context.Err()
+			}
+			db.Logger.Warn(conf.Context, "GetTableForeignKeys for %s,err=%s", tableName, err.Error())
+		}
+	}
+	if conf.FieldWithForeignKeyRelations {
+		fields = append(fields, getForeignKeyFields(db, conf, foreignKeys, fields)...)
+	}
+	var checks []*model.CheckConstraint
+	if conf.FieldWithCheckConstraints {
+		checks, err = getTableCheckConstraints(db, conf, schemaName, tableName)
+		if err != nil { // ignore find check constraint err
+			if conf.Context.Err() != nil {
+				return nil, conf.Context.Err()
+			}
+			db.Logger.Warn(conf.Context, "GetTableCheckConstraints for %s,err=%s", tableName, err.Error())
+		}
+	}
+	importPkgPaths := conf.ImportPkgPaths
+	if path := conf.FieldArrayLib.ImportPath(); path != "" && hasArrayField(conf.FieldArrayLib, fields) {
+		importPkgPaths = append(importPkgPaths[:len(importPkgPaths):len(importPkgPaths)], `"`+path+`"`)
+	}
+	if paths := jsonTypeImports(conf.FieldJSONTypes, fields); len(paths) > 0 {
+		importPkgPaths = append(importPkgPaths[:len(importPkgPaths):len(importPkgPaths)], paths...)
+	}
+	setEnumTypes(structName, fields)
+	setSelfRelations(conf.ModelPkg, structName, fields)
+
+	jsonPathConds, err := getJSONPathConds(db, model.GetJSONPaths(conf.ModelOpts), fields)
+	if err != nil {
+		return nil, fmt.Errorf("table %s: %w", tableName, err)
+	}
+
+	var sourceSchema string
+	if conf.TableNameInComment {
+		sourceSchema = getSourceSchema(db, schemaName)
+	}
+
+	var tableSchema string
+	switch {
+	case conf.TableNameSchema != "":
+		tableSchema = conf.TableNameSchema
+	case conf.TableNameWithSchema && !isDefaultSchema(db, schemaName):
+		tableSchema = schemaName
+	}
+
 	return (&QueryStructMeta{
-		db:              db,
-		Source:          model.Table,
-		Generated:       true,
-		FileName:        fileName,
-		TableName:       tableName,
-		TableComment:    getTableComment(db, tableName),
-		ModelStructName: structName,
-		QueryStructName: uncaptialize(structName),
-		S:               strings.ToLower(structName[0:1]),
-		StructInfo:      parser.Param{Type: structName, Package: conf.ModelPkg},
-		ImportPkgPaths:  conf.ImportPkgPaths,
-		Fields:          getFields(db, conf, columns),
+		db:                 db,
+		Source:             model.Table,
+		Generated:          true,
+		FileName:           fileName,
+		TableName:          tableName,
+		TableSchema:        tableSchema,
+		SourceSchema:       sourceSchema,
+		TableComment:       getTableComment(db.WithContext(conf.Context), tableName),
+		ModelStructName:    structName,
+		QueryStructName:    uncaptialize(structName),
+		S:                  strings.ToLower(structName[0:1]),
+		StructInfo:         parser.Param{Type: structName, Package: conf.ModelPkg},
+		ImportPkgPaths:     importPkgPaths,
+		Fields:             fields,
+		Columns:            columns,
+		ForeignKeys:        foreignKeys,
+		CheckConstraints:   checks,
+		ReadOnly:           conf.View,
+		TableNameInComment: conf.TableNameInComment,
+		NoPrimaryKey:       !hasPrimaryKey(columns),
+		ModelOnly:          model.GetModelMode(conf.ModelOpts) == model.ModelOnly,
+		JSONPathConds:      jsonPathConds,
 	}).addMethodFromAddMethodOpt(conf.GetModelMethods()...), nil
 }
 
@@ -218,6 +290,78 @@ func BuildDIYMethod(f *parser.InterfaceSet, s *QueryStructMeta, data []*Interfac
 	return
 }
 
+// RenameDIYMethod rename methods built by BuildDIYMethod with ns, sql of methods is parsed with original names already.
+// it returns error when renamed method conflicts with another method or field of struct
+func RenameDIYMethod(methods []*InterfaceMethod, s *QueryStructMeta, data []*InterfaceMethod, ns func(methodName string) string) error {
+	origins := make(map[string]string, len(methods)) // renamed => interface.original name
+	for _, m := range methods {
+		origin := m.InterfaceName + "." + m.MethodName
+		if name := ns(m.MethodName); name != m.MethodName {
+			if !token.IsIdentifier(name) || !token.IsExported(name) {
+				return fmt.Errorf("method [%s] is renamed to invalid name %q", origin, name)
+			}
+			if doc := strings.TrimSpace(m.Doc); strings.HasPrefix(doc, m.MethodName) {
+				m.Doc = name + strings.TrimPrefix(doc, m.MethodName)
+			}
+			m.MethodName = name
+			if err := m.checkMethod(data, s); err != nil {
+				return fmt.Errorf("method [%s] is renamed to %s: %w", origin, name, err)
+			}
+		}
+		if other, ok := origins[m.MethodName]; ok {
+			return fmt.Errorf("methods [%s] and [%s] have the same name %s after renaming", other, origin, m.MethodName)
+		}
+		origins[m.MethodName] = origin
+	}
+	return nil
+}
+
+// FillReverseRelations add has-one or has-many relation fields to models referenced by other models' foreign keys,
+// has-one is used when foreign key columns are backed by a unique index
+func FillReverseRelations(db *gorm.DB, metas []*QueryStructMeta) {
+	sort.Slice(metas, func(i, j int) bool { return metas[i].ModelStructName < metas[j].ModelStructName })
+
+	parents := make(map[string]*QueryStructMeta, len(metas))
+	for _, meta := range metas {
+		if meta.Source == model.Table {
+			parents[meta.TableName] = meta
+		}
+	}
+	for _, child := range metas {
+		refCount := make(map[string]int, len(child.ForeignKeys))
+		for _, fk := range child.ForeignKeys {
+			refCount[fk.ReferencedTable]++
+		}
+		for _, fk := range child.ForeignKeys {
+			if parent := parents[fk.ReferencedTable]; parent != nil && len(fk.Columns) > 0 && len(fk.Columns) == len(fk.ReferencedColumns) {
+				parent.appendReverseRelation(db, child, fk, refCount[fk.ReferencedTable] > 1)
+			}
+		}
+	}
+}
+
+// PruneForeignKeyRelations remove belongs-to relation fields built from foreign keys referencing tables
+// which are not generated, e.g. foreign keys to tables outside of GenerateAllTable selection
+func PruneForeignKeyRelations(db *gorm.DB, metas []*QueryStructMeta) {
+	tables := make(map[string]bool, len(metas))
+	for _, meta := range metas {
+		if meta.Source == model.Table && meta.Generated {
+			tables[meta.TableName] = true
+		}
+	}
+	for _, meta := range metas {
+		fields := meta.Fields[:0]
+		for _, f := range meta.Fields {
+			if f.ForeignKey != nil && !tables[f.ForeignKey.ReferencedTable] {
+				db.Logger.Info(context.Background(), "ignore foreign key %s of %s: table %s is not generated", f.ForeignKey.Name, meta.TableName, f.ForeignKey.ReferencedTable)
+				continue
+			}
+			fields = append(fields, f)
+		}
+		meta.Fields = fields
+	}
+}
+
 // ParseStructRelationShip parse struct's relationship
 // No one should use it directly in project
 func ParseStructRelationShip(relationship *schema.Relationships) []field.Relation {
@@ -0,0 +1,77 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FilterField field of generated model filter struct, nil value is ignored
+type FilterField struct {
+	Name    string // field name in filter struct
+	Type    string // go type of filter field without pointer
+	Field   string // field name in query struct
+	Method  string // condition method of query field, e.g. Eq, IsNull
+	Convert string // conversion of filter value passed to Method, e.g. string for enum type
+	Comment string
+}
+
+// filterMethods comparison of filter fields generated for base type of model field
+var filterMethods = map[string][]struct{ suffix, method, comment string }{
+	"time.Time": {
+		{"From", "Gte", ">= %s"},
+		{"To", "Lt", "< %s"},
+	},
+}
+
+// filterTypes base types of model field supported by filter, other fields are skipped
+var filterTypes = map[string]bool{
+	"string": true, "bool": true, "float32": true, "float64": true, "time.Time": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+}
+
+// FilterFields fields of model filter struct, scalar fields are matched by equality, time fields by range.
+// nullable columns get an extra IsNull field, true matches IS NULL and false matches IS NOT NULL
+func (b *QueryStructMeta) FilterFields() (fields []FilterField) {
+	for _, f := range b.Fields {
+		if f.IsRelation() || f.ColumnName == "" {
+			continue
+		}
+
+		typ, convert := strings.TrimPrefix(f.Type, "*"), ""
+		switch {
+		case f.Enum != nil:
+			typ, convert = b.StructInfo.Package+"."+f.Enum.TypeName, "string"
+		case !filterTypes[typ]:
+			continue
+		}
+
+		if methods, ok := filterMethods[typ]; ok {
+			for _, m := range methods {
+				fields = append(fields, FilterField{
+					Name:    f.Name + m.suffix,
+					Type:    typ,
+					Field:   f.Name,
+					Method:  m.method,
+					Comment: f.ColumnName + " " + fmt.Sprintf(m.comment, f.Name+m.suffix),
+				})
+			}
+		} else {
+			fields = append(fields, FilterField{Name: f.Name, Type: typ, Field: f.Name, Method: "Eq", Convert: convert, Comment: f.ColumnName + " = " + f.Name})
+		}
+
+		if f.Column == nil {
+			continue
+		}
+		if nullable, ok := f.Column.Nullable(); ok && nullable {
+			fields = append(fields, FilterField{
+				Name:    f.Name + "IsNull",
+				Type:    "bool",
+				Field:   f.Name,
+				Method:  "IsNull",
+				Comment: f.ColumnName + " IS NULL when true, IS NOT NULL when false",
+			})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,56 @@
+package generate
+
+import (
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// flavor database server behind a dialector, empty for the server the dialector is named after
+type flavor string
+
+const (
+	flavorMariaDB     flavor = "mariadb"     // mysql dialector
+	flavorTiDB        flavor = "tidb"        // mysql dialector
+	flavorCockroachDB flavor = "cockroachdb" // postgres dialector
+)
+
+// server flavor and version of connected database server
+type server struct {
+	Flavor  flavor
+	Version string // dotted version prefix of server version, e.g. 10.6.16 of MariaDB, empty when there is none
+}
+
+// servers server of db keyed by its connection pool, shared by sessions of the same gorm.Open
+var servers sync.Map
+
+// serverFlavor flavor and version of server connected by mysql or postgres dialector, read by SELECT VERSION() once per db,
+// whose result is like 10.6.16-MariaDB-1:10.6.16+maria~ubu2004, 8.0.11-TiDB-v7.5.1 or CockroachDB CCL v23.1.11
+func serverFlavor(db *gorm.DB) server {
+	if name := db.Dialector.Name(); name != "mysql" && name != "postgres" {
+		return server{}
+	}
+	if s, ok := servers.Load(db.ConnPool); ok {
+		return s.(server)
+	}
+
+	var version string
+	if err := db.Raw("SELECT VERSION()").Scan(&version).Error; err != nil { // not cached, query may succeed later
+		return server{}
+	}
+	s := server{Version: version}
+	if i := strings.IndexFunc(version, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		s.Version = version[:i]
+	}
+	switch {
+	case strings.Contains(version, "MariaDB"):
+		s.Flavor = flavorMariaDB
+	case strings.Contains(version, "TiDB"):
+		s.Flavor = flavorTiDB
+	case strings.Contains(version, "CockroachDB"):
+		s.Flavor = flavorCockroachDB
+	}
+	servers.Store(db.ConnPool, s)
+	return s
+}
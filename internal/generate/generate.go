@@ -1,13 +1,17 @@
 package generate
 
 import (
+	"context"
 	"fmt"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 
+	"gorm.io/gen/field"
 	"gorm.io/gen/internal/model"
 )
 
@@ -17,11 +21,40 @@ import (
  */
 
 func getFields(db *gorm.DB, conf *model.Config, columns []*model.Column) (fields []*model.Field) {
+	names := make(map[string]int, len(columns))
 	for _, col := range columns {
 		col.SetDataTypeMap(conf.DataTypeMap)
+		col.SetFieldTypeRules(conf.FieldTypeRules)
 		col.WithNS(conf.FieldJSONTagNS)
+		col.UseJSONType = conf.FieldUseDatatypesJSON && supportDatatypesJSON(db)
+		if db.Dialector.Name() == "postgres" {
+			col.ArrayLib = conf.FieldArrayLib
+		}
+		col.BitAsBool = db.Dialector.Name() == "sqlserver" // BIT of sqlserver is boolean, its scan type may be integer
+		// INTEGER of sqlite is 64-bit whatever its declared type
+		col.PreciseIntegers = conf.FieldPreciseIntegerTypes && db.Dialector.Name() != "sqlite"
+		col.TinyIntAsBool = conf.FieldPreciseTinyIntAsBool
+		col.TinyIntUnsigned = db.Dialector.Name() == "sqlserver"
+		if supportDateType(db) {
+			col.DateType = conf.FieldDateType
+		}
+		col.QuoteDefault = conf.FieldWithDefaultValueTag
+		col.InvisibleIndexes = conf.FieldWithInvisibleIndexes
+		col.WithoutIndexTag = !conf.FieldWithIndexTag
+		col.NullableExceptDefaulted = conf.FieldNullableExceptDefaulted
+		col.JSONType = conf.FieldJSONTypes[col.Name()].GoType
+		col.SetSoftDeleteFields(conf.FieldSoftDeleteNames)
+		if useScanType, ok := conf.UseScanTypeDialects[db.Dialector.Name()]; ok { // explicit config over dialect defaults
+			col.UseScanType = useScanType
+		}
 
 		m := col.ToField(conf.FieldNullable, conf.FieldCoverable, conf.FieldSignable)
+		m.DocComment = conf.FieldWithComment
+		if conf.Naming != nil {
+			if name := conf.Naming.ColumnName(col.TableName, col.Name()); name != "" {
+				m.Name = name
+			}
+		}
 
 		if filterField(m, conf.FilterOpts) == nil {
 			continue
@@ -37,6 +70,15 @@ func getFields(db *gorm.DB, conf *model.Config, columns []*model.Column) (fields
 		} else if db.NamingStrategy != nil {
 			m.Name = db.NamingStrategy.SchemaName(m.Name)
 		}
+		m.Name = model.ApplyInitialisms(m.Name, conf.Initialisms)
+		if n := names[m.Name]; n > 0 { // e.g. user_name and userName are both UserName
+			name := m.Name
+			for i := n + 1; names[m.Name] > 0; i++ {
+				m.Name = name + strconv.Itoa(i)
+			}
+			db.Logger.Warn(context.Background(), "field %s of column %s is renamed to %s: field name already exists", name, col.Name(), m.Name)
+		}
+		names[m.Name]++
 
 		fields = append(fields, m)
 	}
@@ -58,6 +100,185 @@ func getFields(db *gorm.DB, conf *model.Config, columns []*model.Column) (fields
 	return fields
 }
 
+// setSelfRelations complete type of relation fields targeting the model being generated
+func setSelfRelations(modelPkg, structName string, fields []*model.Field) {
+	for _, f := range fields {
+		if !f.SelfRelation || f.Relation == nil {
+			continue
+		}
+		f.Type += structName
+		f.Relation = field.NewRelationWithType(f.Relation.Relationship(), f.Relation.Name(), modelPkg+"."+structName)
+	}
+}
+
+// getForeignKeyFields build belongs-to relation fields from table's foreign keys,
+// referenced tables' models are expected to be generated in the same model package
+func getForeignKeyFields(db *gorm.DB, conf *model.Config, foreignKeys []*model.ForeignKey, fields []*model.Field) (relations []*model.Field) {
+	names := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		names[f.Name] = true
+	}
+	for _, fk := range foreignKeys {
+		if fk == nil || len(fk.Columns) == 0 || len(fk.Columns) != len(fk.ReferencedColumns) {
+			continue
+		}
+
+		structName := conf.DefaultModelName(db, fk.ReferencedTable)
+		if conf.ModelNameNS != nil {
+			structName = conf.ModelNameNS(fk.ReferencedTable)
+		}
+		name := foreignKeyColumnName(db, fk) // e.g. parent_id => Parent
+		if name == "" {
+			name = structName
+		}
+		if names[name] { // fallback to constraint name when field name is taken
+			name = db.NamingStrategy.SchemaName(fk.Name)
+		}
+		if names[name] {
+			db.Logger.Warn(context.Background(), "ignore foreign key %s: field %s already exists", fk.Name, name)
+			continue
+		}
+		names[name] = true
+
+		relations = append(relations, &model.Field{
+			Name: name,
+			Type: "*" + structName, // pointer type is required for self-referential association
+			Tag:  (&field.RelateConfig{}).GetTag(name),
+			GORMTag: field.GormTag{
+				field.TagKeyGormForeignKey: []string{strings.Join(fk.Columns, ",")},
+				field.TagKeyGormReferences: []string{strings.Join(fk.ReferencedColumns, ",")},
+			},
+			Relation:   field.NewRelationWithType(field.BelongsTo, name, conf.ModelPkg+"."+structName),
+			ForeignKey: fk,
+		})
+	}
+	return relations
+}
+
+// embedBaseModel embed base struct at the position of the first base column when table has all base columns,
+// fields of base columns are kept for query code but not declared in model struct
+func embedBaseModel(base model.BaseModel, fields []*model.Field) []*model.Field {
+	if base.Type == "" || len(base.Columns) == 0 {
+		return fields
+	}
+	index := make(map[string]int, len(fields))
+	for i, f := range fields {
+		if f.Column != nil {
+			index[f.ColumnName] = i
+		}
+	}
+	first := len(fields)
+	for _, col := range base.Columns {
+		i, ok := index[col]
+		if !ok { // fallback to inline fields when any base column is missing
+			return fields
+		}
+		if i < first {
+			first = i
+		}
+	}
+	for _, col := range base.Columns {
+		fields[index[col]].BaseEmbedded = true
+	}
+	embedded := &model.Field{Type: base.Type, Embedded: true}
+	return append(fields[:first:first], append([]*model.Field{embedded}, fields[first:]...)...)
+}
+
+// setEnumTypes use generated enum type for string fields of enum columns, e.g. User.Status => UserStatus,
+// field of nullable column is a pointer to enum type
+func setEnumTypes(structName string, fields []*model.Field) {
+	for _, f := range fields {
+		if f.Column == nil || len(f.Column.EnumValues) == 0 || strings.TrimPrefix(f.Type, "*") != "string" {
+			continue
+		}
+		f.Enum = model.NewEnum(structName+f.Name, f.Column.EnumValues)
+		f.Type = f.Enum.TypeName
+		if n, ok := f.Column.Nullable(); ok && n {
+			f.Type = "*" + f.Type
+		}
+		f.CustomGenType = "String"
+	}
+}
+
+// foreignKeyColumnName field name derived from single foreign key column with _id suffix, e.g. parent_id => Parent
+// return empty string for other foreign keys
+func foreignKeyColumnName(db *gorm.DB, fk *model.ForeignKey) string {
+	if len(fk.Columns) != 1 {
+		return ""
+	}
+	col := fk.Columns[0]
+	if len(col) <= 3 || !strings.EqualFold(col[len(col)-3:], "_id") {
+		return ""
+	}
+	return db.NamingStrategy.SchemaName(col[:len(col)-3])
+}
+
+// hasPrimaryKey check if any column is part of primary key, including columns of composite primary key,
+// columns are assumed to have primary key when dialect can't tell
+func hasPrimaryKey(columns []*model.Column) bool {
+	for _, col := range columns {
+		if pk, ok := col.PrimaryKey(); pk || !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeReg valid go type of json column, e.g. Payload, *mypkg.Payload
+var jsonTypeReg = regexp.MustCompile(`^\*?([A-Za-z_]\w*\.)?[A-Za-z_]\w*$`)
+
+// checkJSONTypes check go types of json columns are valid qualified identifiers
+func checkJSONTypes(types map[string]model.JSONType) error {
+	for column, typ := range types {
+		if !jsonTypeReg.MatchString(typ.GoType) {
+			return fmt.Errorf("type %q of json column %s is not a valid qualified identifier", typ.GoType, column)
+		}
+	}
+	return nil
+}
+
+// jsonTypeImports import paths of go types used by json fields
+func jsonTypeImports(types map[string]model.JSONType, fields []*model.Field) (paths []string) {
+	for _, f := range fields {
+		if f.Column == nil || f.Column.JSONType == "" || f.Type != f.Column.JSONType {
+			continue
+		}
+		if path := types[f.ColumnName].PkgPath; path != "" {
+			paths = append(paths, `"`+path+`"`)
+		}
+	}
+	return paths
+}
+
+// hasArrayField check if any field is generated as array type of lib
+func hasArrayField(lib model.ArrayLib, fields []*model.Field) bool {
+	prefix := path.Base(lib.ImportPath()) + "."
+	for _, f := range fields {
+		if strings.HasPrefix(strings.TrimPrefix(f.Type, "*"), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// supportDatatypesJSON check if json columns of db can be generated as datatypes.JSON
+func supportDatatypesJSON(db *gorm.DB) bool {
+	switch db.Dialector.Name() {
+	case "mysql", "postgres":
+		return true
+	}
+	return false
+}
+
+// supportDateType check if DATE columns of db store date without time of day, e.g. DATE of oracle has time of day
+func supportDateType(db *gorm.DB) bool {
+	switch db.Dialector.Name() {
+	case "mysql", "postgres", "sqlite", "sqlserver":
+		return true
+	}
+	return false
+}
+
 func filterField(m *model.Field, opts []model.FieldOption) *model.Field {
 	for _, opt := range opts {
 		if opt.Operator()(m) == nil {
@@ -74,6 +295,43 @@ func modifyField(m *model.Field, opts []model.FieldOption) *model.Field {
 	return m
 }
 
+// applyTagProviders set tags of providers on fields of columns, tags already set by field options are kept
+func applyTagProviders(providers []model.TagProvider, fields []*model.Field) error {
+	if len(providers) == 0 {
+		return nil
+	}
+	for _, m := range fields {
+		if m.Column == nil {
+			continue
+		}
+		provided := make(map[string]bool, len(providers))
+		for _, provide := range providers {
+			key, value, ok := provide(m.Column)
+			if !ok {
+				continue
+			}
+			switch {
+			case key == "" || strings.ContainsAny(key, ": \t\"`"):
+				return fmt.Errorf("tag key %q of column %s is invalid", key, m.ColumnName)
+			case strings.ContainsAny(value, "\"`"):
+				return fmt.Errorf("tag %s of column %s has invalid value %q", key, m.ColumnName, value)
+			case key == field.TagKeyGorm || key == field.TagKeyJson:
+				return fmt.Errorf("tag %s of column %s is generated by gen, it can't be provided", key, m.ColumnName)
+			case provided[key]:
+				return fmt.Errorf("tag %s of column %s is provided more than once", key, m.ColumnName)
+			}
+			provided[key] = true
+			if m.Tag == nil {
+				m.Tag = make(field.Tag)
+			}
+			if _, ok := m.Tag[key]; !ok {
+				m.Tag.Set(key, value)
+			}
+		}
+	}
+	return nil
+}
+
 // get mysql db' name
 var modelNameReg = regexp.MustCompile(`^\w+$`)
 
@@ -0,0 +1,982 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"go/format"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/model"
+	"gorm.io/gen/internal/parser"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// databases of dialects without connection shared by tests
+var (
+	dummyDB, _     = gorm.Open(tests.DummyDialector{})
+	mysqlDB, _     = gorm.Open(mysqlDialector{})
+	postgresDB, _  = gorm.Open(postgresDialector{})
+	sqliteDB, _    = gorm.Open(sqliteDialector{})
+	sqlserverDB, _ = gorm.Open(sqlserverDialector{})
+)
+
+// testColumn metadata of column in tests, column type, comment and default value are unknown when empty
+type testColumn struct {
+	table, name, dataType, columnType, comment  string
+	nullable, primaryKey, unsigned, useScanType bool
+	defaultValue                                sql.NullString
+	scanType                                    reflect.Type
+	ordinal                                     int
+}
+
+func (c testColumn) column() *model.Column {
+	return &model.Column{ColumnType: migrator.ColumnType{
+		NameValue:         sql.NullString{String: c.name, Valid: true},
+		DataTypeValue:     sql.NullString{String: c.dataType, Valid: true},
+		ColumnTypeValue:   sql.NullString{String: c.columnType, Valid: c.columnType != ""},
+		CommentValue:      sql.NullString{String: c.comment, Valid: c.comment != ""},
+		PrimaryKeyValue:   sql.NullBool{Bool: c.primaryKey, Valid: c.primaryKey},
+		NullableValue:     sql.NullBool{Bool: c.nullable, Valid: true},
+		DefaultValueValue: c.defaultValue,
+		ScanTypeValue:     c.scanType,
+	}, TableName: c.table, Unsigned: c.unsigned, UseScanType: c.useScanType, Ordinal: c.ordinal}
+}
+
+// columns columns of table named names, the others are the same as c
+func (c testColumn) columns(table string, names ...string) []*model.Column {
+	columns := make([]*model.Column, len(names))
+	for i, name := range names {
+		c.table, c.name = table, name
+		columns[i] = c.column()
+	}
+	return columns
+}
+
+func TestGetFieldsWithComment(t *testing.T) {
+	comment := "user's `nick` name \"alias\"\nC:\\path */ end"
+	columns := []*model.Column{testColumn{table: "users", name: "nick_name", dataType: "varchar", comment: comment, scanType: reflect.TypeOf("")}.column()}
+	conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldWithComment: true}}
+
+	fields := getFields(dummyDB, conf, columns)
+	if len(fields) != 1 {
+		t.Fatalf("expect 1 field, got %d", len(fields))
+	}
+	f := fields[0]
+
+	gormTag, ok := reflect.StructTag(f.Tags()).Lookup("gorm")
+	if !ok {
+		t.Fatalf("gorm tag cannot be parsed: %s", f.Tags())
+	}
+	if !strings.HasSuffix(gormTag, "comment:"+comment) {
+		t.Errorf("gorm tag comment expects %q, got %q", comment, gormTag)
+	}
+
+	var buf bytes.Buffer
+	meta := &QueryStructMeta{
+		TableName:       "users",
+		ModelStructName: "User",
+		StructInfo:      parser.Param{Type: "User", Package: "model"},
+		Fields:          fields,
+	}
+	if err := template.Must(template.New("model").Parse(tmpl.Model)).Execute(&buf, meta); err != nil {
+		t.Fatalf("render model fail: %s", err)
+	}
+	result, err := format.Source(buf.Bytes())
+	if err != nil {
+		t.Fatalf("generated model cannot be formatted: %s\n%s", err, buf.String())
+	}
+	for _, line := range []string{"// user's `nick` name \"alias\"", "// C:\\path */ end"} {
+		if !bytes.Contains(result, []byte(line)) {
+			t.Errorf("generated model expects doc comment %q, got:\n%s", line, result)
+		}
+	}
+}
+
+func TestGetFieldsWithCommentTag(t *testing.T) {
+	comment := "status \"on\" or `off`\nC:\\path"
+	columns := []*model.Column{testColumn{table: "users", name: "status", dataType: "varchar", comment: comment, scanType: reflect.TypeOf("")}.column()}
+
+	// comment tag used to only escape newlines, quotes ended the tag value early and backticks the raw string of the tag
+	legacy := `gorm:"column:status;comment:` + strings.ReplaceAll(comment, "\n", "\\n") + `"`
+	if value, ok := reflect.StructTag(legacy).Lookup("gorm"); ok && strings.HasSuffix(value, "comment:"+comment) {
+		t.Fatalf("legacy comment tag expects broken, got %q", value)
+	}
+	if _, err := format.Source([]byte("package model\n\ntype User struct {\n\tStatus string `" + legacy + "`\n}\n")); err == nil {
+		t.Fatalf("legacy comment tag expects broken generated file")
+	}
+
+	tags := getFields(dummyDB, &model.Config{ModelPkg: "model"}, columns)[0].Tags()
+	gormTag, ok := reflect.StructTag(tags).Lookup("gorm")
+	if !ok || gormTag != "column:status;type:varchar;not null;comment:"+comment {
+		t.Errorf("gorm tag comment expects %q, got %q", comment, tags)
+	}
+	if _, err := format.Source([]byte("package model\n\ntype User struct {\n\tStatus string `" + tags + "`\n}\n")); err != nil {
+		t.Errorf("comment tag expects generated file compiled, got %s", err)
+	}
+}
+
+func TestIndexLookupsParam(t *testing.T) {
+	lookupField := func(name, column string) *model.Field {
+		return &model.Field{Name: name, Type: "string", ColumnName: column, Column: &model.Column{
+			Indexes: []*model.Index{{Index: &migrator.Index{
+				NameValue:   "uk_" + column,
+				ColumnList:  []string{column},
+				UniqueValue: sql.NullBool{Bool: true, Valid: true},
+			}}},
+		}}
+	}
+	meta := &QueryStructMeta{S: "u", Fields: []*model.Field{
+		lookupField("Field", "field"),
+		lookupField("Gen", "gen"),
+		lookupField("Clause", "clause"),
+		lookupField("U", "u"),
+		lookupField("Type", "type"),
+		lookupField("Email", "email"),
+	}}
+	var params []string
+	for _, lookup := range meta.IndexLookups() {
+		params = append(params, lookup.Param)
+	}
+	if expect := []string{"value", "value", "value", "value", "value", "email"}; !reflect.DeepEqual(params, expect) {
+		t.Errorf("expect lookup params %v, got %v", expect, params)
+	}
+}
+
+type catalogTableInfo map[string][]*model.Column
+
+func (c catalogTableInfo) GetTableColumns(_ string, tableName string) ([]*model.Column, error) {
+	return c[tableName], nil
+}
+
+func (catalogTableInfo) GetTableIndex(string, string) ([]gorm.Index, error) { return nil, nil }
+
+func TestGetQueryStructMetaWithTableInfo(t *testing.T) {
+	info := catalogTableInfo{"users": testColumn{dataType: "bigint", primaryKey: true, scanType: reflect.TypeOf(int64(0))}.columns("users", "id")}
+
+	if _, err := GetQueryStructMeta(dummyDB, &model.Config{TableName: "users", ModelName: "User"}); err == nil {
+		t.Errorf("generate model without db and table info expects error")
+	}
+
+	meta, err := GetQueryStructMeta(dummyDB, &model.Config{TableName: "users", ModelName: "User", TableInfo: info})
+	if err != nil {
+		t.Fatalf("generate model with table info fail: %s", err)
+	}
+	if len(meta.Fields) != 1 || meta.Fields[0].Name != "ID" || meta.Fields[0].Type != "int64" {
+		t.Errorf("generate model with table info got unexpected fields: %+v", meta.Fields)
+	}
+}
+
+// indexedTableInfo catalogTableInfo with table indexes
+type indexedTableInfo struct {
+	catalogTableInfo
+	indexes map[string][]gorm.Index
+}
+
+func (i indexedTableInfo) GetTableIndex(_ string, tableName string) ([]gorm.Index, error) {
+	return i.indexes[tableName], nil
+}
+
+func TestGetQueryStructMetaWithCompositePrimaryKey(t *testing.T) {
+	key := testColumn{dataType: "bigint", primaryKey: true, scanType: reflect.TypeOf(int64(0))}
+	primaryKey := func(table string, columns ...string) []gorm.Index {
+		return []gorm.Index{&migrator.Index{
+			TableName:       table,
+			NameValue:       "PRIMARY",
+			ColumnList:      columns,
+			PrimaryKeyValue: sql.NullBool{Bool: true, Valid: true},
+			UniqueValue:     sql.NullBool{Bool: true, Valid: true},
+		}}
+	}
+	info := indexedTableInfo{
+		catalogTableInfo: catalogTableInfo{
+			"memberships": key.columns("memberships", "user_id", "org_id"),
+			"user_roles":  key.columns("user_roles", "user_id", "role_id"),
+		},
+		indexes: map[string][]gorm.Index{
+			"memberships": primaryKey("memberships", "org_id", "user_id"), // declared key order differs from column order
+			"user_roles":  primaryKey("user_roles", "user_id", "role_id"),
+		},
+	}
+
+	testcases := []struct {
+		table string
+		tags  []string
+	}{
+		{"memberships", []string{"column:user_id;type:bigint;primaryKey;priority:2", "column:org_id;type:bigint;primaryKey;priority:1"}},
+		{"user_roles", []string{"column:user_id;type:bigint;primaryKey", "column:role_id;type:bigint;primaryKey"}}, // column order is key order
+	}
+	for _, tc := range testcases {
+		for _, withIndexTag := range []bool{false, true} {
+			meta, err := GetQueryStructMeta(dummyDB, &model.Config{TableName: tc.table, ModelName: "Model", TableInfo: info,
+				FieldConfig: model.FieldConfig{FieldWithIndexTag: withIndexTag}})
+			if err != nil {
+				t.Fatalf("generate model fail: %s", err)
+			}
+			for i, expect := range tc.tags {
+				if f := meta.Fields[i]; f.GORMTag.Build() != expect {
+					t.Errorf("%s FieldWithIndexTag=%t: field %s expects gorm tag %q, got %q", tc.table, withIndexTag, f.Name, expect, f.GORMTag.Build())
+				}
+			}
+		}
+	}
+}
+
+func TestGetForeignKeyFields(t *testing.T) {
+	conf := &model.Config{ModelPkg: "model"}
+	fields := []*model.Field{{Name: "ID"}, {Name: "ParentID"}, {Name: "OrgID"}, {Name: "UserID"}, {Name: "Org"}}
+	foreignKeys := []*model.ForeignKey{
+		{Name: "fk_parent", Columns: []string{"parent_id"}, ReferencedTable: "categories", ReferencedColumns: []string{"id"}},
+		{Name: "fk_org_member", Columns: []string{"org_id", "user_id"}, ReferencedTable: "org_members", ReferencedColumns: []string{"org_id", "user_id"}},
+		{Name: "fk_org", Columns: []string{"org_id"}, ReferencedTable: "orgs", ReferencedColumns: []string{"id"}},
+	}
+
+	relations := getForeignKeyFields(dummyDB, conf, foreignKeys, fields)
+	expects := []struct{ name, typ, tag string }{
+		{"Parent", "*Category", `foreignKey:parent_id;references:id`},
+		{"OrgMember", "*OrgMember", `foreignKey:org_id,user_id;references:org_id,user_id`},
+		{"FkOrg", "*Org", `foreignKey:org_id;references:id`},
+	}
+	if len(relations) != len(expects) {
+		t.Fatalf("expect %d relations, got %d", len(expects), len(relations))
+	}
+	for i, exp := range expects {
+		r := relations[i]
+		if r.Name != exp.name || r.Type != exp.typ || r.GORMTag.Build() != exp.tag {
+			t.Errorf("relation expects %+v, got name=%s type=%s tag=%s", exp, r.Name, r.Type, r.GORMTag.Build())
+		}
+		if r.Relation == nil || r.Relation.Relationship() != field.BelongsTo || r.Relation.Type() != "model."+exp.typ[1:] {
+			t.Errorf("relation %s expects belongs-to model.%s, got %+v", r.Name, exp.typ[1:], r.Relation)
+		}
+	}
+}
+
+func TestGetFieldsWithTypeRules(t *testing.T) {
+	conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{
+		DataTypeMap: map[string]func(gorm.ColumnType) string{"int": func(gorm.ColumnType) string { return "int64" }},
+		FieldTypeRules: []model.FieldTypeRule{
+			{Pattern: regexp.MustCompile(`_cents$`), GoType: "money.Money"},
+			{Pattern: regexp.MustCompile(`^price`), GoType: "money.Price"},
+		},
+	}}
+
+	testcases := []struct {
+		name   string
+		expect string
+	}{
+		{"price_cents", "money.Money"},
+		{"price", "money.Price"},
+		{"count", "int64"},
+	}
+	for _, tc := range testcases {
+		c := testColumn{name: tc.name, dataType: "int", scanType: reflect.TypeOf(int32(0))}
+		if typ := getFields(dummyDB, conf, []*model.Column{c.column()})[0].Type; typ != tc.expect {
+			t.Errorf("field %s expects type %s, got %s", tc.name, tc.expect, typ)
+		}
+	}
+}
+
+func TestFillReverseRelations(t *testing.T) {
+	meta := func(table, structName string, fks ...*model.ForeignKey) *QueryStructMeta {
+		return &QueryStructMeta{
+			TableName:       table,
+			ModelStructName: structName,
+			StructInfo:      parser.Param{Type: structName, Package: "model"},
+			Source:          model.Table,
+			ForeignKeys:     fks,
+		}
+	}
+	users := meta("users", "User")
+	orders := meta("orders", "Order",
+		&model.ForeignKey{Name: "fk_buyer", Columns: []string{"buyer_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}},
+		&model.ForeignKey{Name: "fk_seller", Columns: []string{"seller_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}},
+	)
+	profiles := meta("profiles", "Profile",
+		&model.ForeignKey{Name: "fk_user", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}, Unique: true},
+	)
+
+	FillReverseRelations(dummyDB, []*QueryStructMeta{users, orders, profiles})
+
+	expects := []struct {
+		name, typ, tag string
+		relationship   field.RelationshipType
+	}{
+		{"BuyerOrders", "[]*Order", `foreignKey:buyer_id;references:id`, field.HasMany},
+		{"SellerOrders", "[]*Order", `foreignKey:seller_id;references:id`, field.HasMany},
+		{"Profile", "*Profile", `foreignKey:user_id;references:id`, field.HasOne},
+	}
+	if len(users.Fields) != len(expects) {
+		t.Fatalf("expect %d reverse relations, got %d", len(expects), len(users.Fields))
+	}
+	for i, exp := range expects {
+		f := users.Fields[i]
+		if f.Name != exp.name || f.Type != exp.typ || f.GORMTag.Build() != exp.tag {
+			t.Errorf("relation expects %+v, got name=%s type=%s tag=%s", exp, f.Name, f.Type, f.GORMTag.Build())
+		}
+		if f.Relation == nil || f.Relation.Relationship() != exp.relationship {
+			t.Errorf("relation %s expects %s, got %+v", f.Name, exp.relationship, f.Relation)
+		}
+	}
+	if len(orders.Fields) != 0 || len(profiles.Fields) != 0 {
+		t.Errorf("child models expect no reverse relations")
+	}
+}
+
+func TestGetFieldsWithDatatypesJSON(t *testing.T) {
+	jsonConf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldNullable: true, FieldUseDatatypesJSON: true}}
+	mappedConf := *jsonConf
+	mappedConf.DataTypeMap = map[string]func(gorm.ColumnType) string{"json": func(gorm.ColumnType) string { return "json.RawMessage" }}
+
+	testcases := []struct {
+		db     *gorm.DB
+		conf   *model.Config
+		column testColumn
+		expect string
+	}{
+		{mysqlDB, jsonConf, testColumn{name: "attrs", dataType: "json"}, "datatypes.JSON"},
+		{mysqlDB, jsonConf, testColumn{name: "extra", dataType: "jsonb", nullable: true}, "*datatypes.JSON"},
+		{mysqlDB, jsonConf, testColumn{name: "name", dataType: "varchar"}, "string"},
+		{dummyDB, jsonConf, testColumn{name: "attrs", dataType: "json"}, "string"}, // unsupported dialect
+		{mysqlDB, &mappedConf, testColumn{name: "attrs", dataType: "json"}, "json.RawMessage"},
+	}
+	for _, tc := range testcases {
+		tc.column.scanType = reflect.TypeOf("")
+		if typ := getFields(tc.db, tc.conf, []*model.Column{tc.column.column()})[0].Type; typ != tc.expect {
+			t.Errorf("%s: field %s expects type %s, got %s", tc.db.Dialector.Name(), tc.column.name, tc.expect, typ)
+		}
+	}
+}
+
+func TestGetFieldsWithUseScanTypeDialects(t *testing.T) {
+	c := testColumn{name: "id", dataType: "bigint", useScanType: true, scanType: reflect.TypeOf((*interface{})(nil)).Elem()}
+
+	testcases := []struct {
+		dialects map[string]bool
+		expect   string
+	}{
+		{nil, "interface {}"},
+		{map[string]bool{"mysql": false}, "interface {}"},
+		{map[string]bool{dummyDB.Dialector.Name(): false}, "int64"},
+	}
+	for _, tc := range testcases {
+		conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{UseScanTypeDialects: tc.dialects}}
+		if fields := getFields(dummyDB, conf, []*model.Column{c.column()}); fields[0].Type != tc.expect {
+			t.Errorf("UseScanTypeDialects %v expects type %s, got %s", tc.dialects, tc.expect, fields[0].Type)
+		}
+	}
+	if !useScanType("postgres") || useScanType("mysql") || useScanType("sqlite") {
+		t.Errorf("built-in scan type defaults changed")
+	}
+}
+
+func TestParseEnumValues(t *testing.T) {
+	testcases := []struct {
+		columnType string
+		values     []string
+		ok         bool
+	}{
+		{`ENUM('north','south-east','it''s')`, []string{"north", "south-east", "it's"}, true},
+		{`enum('a,b', 'c\'d', '')`, []string{"a,b", "c'd", ""}, true},
+		{`enum('a'`, nil, false},
+		{`enum('a',)`, nil, false},
+		{`enum('a)`, nil, false},
+		{`varchar(10)`, nil, false},
+	}
+	for _, tc := range testcases {
+		values, ok := model.ParseEnumValues(tc.columnType)
+		if ok != tc.ok || !reflect.DeepEqual(values, tc.values) {
+			t.Errorf("parse %s expects %q %t, got %q %t", tc.columnType, tc.values, tc.ok, values, ok)
+		}
+	}
+}
+
+func TestGetQueryStructMetaWithEnumTypes(t *testing.T) {
+	info := catalogTableInfo{"maps": testColumn{dataType: "enum", columnType: `ENUM('north','south-east','it''s')`, nullable: true,
+		scanType: reflect.TypeOf("")}.columns("maps", "direction")}
+	conf := &model.Config{TableName: "maps", ModelName: "Map", ModelPkg: "model", TableInfo: info,
+		FieldConfig: model.FieldConfig{FieldWithEnumTypes: true}}
+
+	meta, err := GetQueryStructMeta(dummyDB, conf)
+	if err != nil {
+		t.Fatalf("generate model with enum column fail: %s", err)
+	}
+	f := meta.Fields[0]
+	if f.Type != "*MapDirection" || f.GenType() != "String" {
+		t.Errorf("enum field expects type *MapDirection and gen type String, got %s %s", f.Type, f.GenType())
+	}
+
+	var buf bytes.Buffer
+	if err := template.Must(template.New("model").Parse(tmpl.Model)).Execute(&buf, meta); err != nil {
+		t.Fatalf("render model fail: %s", err)
+	}
+	result, err := format.Source(buf.Bytes())
+	if err != nil {
+		t.Fatalf("generated model cannot be formatted: %s\n%s", err, buf.String())
+	}
+	for _, line := range []string{
+		"type MapDirection string",
+		`MapDirectionNorth     MapDirection = "north"`,
+		`MapDirectionSouthEast MapDirection = "south-east"`,
+		`MapDirectionItS       MapDirection = "it's"`,
+		"func (v MapDirection) Valid() bool {\n\tswitch v {\n\tcase MapDirectionNorth, MapDirectionSouthEast, MapDirectionItS:\n\t\treturn true\n\t}\n\treturn false\n}",
+	} {
+		if !bytes.Contains(result, []byte(line)) {
+			t.Errorf("generated model expects %q, got:\n%s", line, result)
+		}
+	}
+}
+
+type postgresDialector struct{ tests.DummyDialector }
+
+func (postgresDialector) Name() string { return "postgres" }
+
+type sqlserverDialector struct{ tests.DummyDialector }
+
+func (sqlserverDialector) Name() string { return "sqlserver" }
+
+type sqliteDialector struct{ tests.DummyDialector }
+
+func (sqliteDialector) Name() string { return "sqlite" }
+
+func TestGetFieldsWithSQLServerBit(t *testing.T) {
+	conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldNullable: true}}
+
+	testcases := []struct {
+		db     *gorm.DB
+		column testColumn
+		expect string
+	}{
+		{sqlserverDB, testColumn{name: "active", dataType: "bit", scanType: reflect.TypeOf(int64(0))}, "bool"},
+		{sqlserverDB, testColumn{name: "verified", dataType: "BIT", nullable: true, scanType: reflect.TypeOf(sql.NullInt64{})}, "*bool"},
+		{sqlserverDB, testColumn{name: "flags", dataType: "bit", scanType: reflect.TypeOf(true)}, "bool"},
+		{sqlserverDB, testColumn{name: "level", dataType: "int", scanType: reflect.TypeOf(int64(0))}, "int64"},
+		{postgresDB, testColumn{name: "active", dataType: "bit", scanType: reflect.TypeOf(int64(0))}, "int64"},
+	}
+	for _, tc := range testcases {
+		tc.column.useScanType = true
+		if typ := getFields(tc.db, conf, []*model.Column{tc.column.column()})[0].Type; typ != tc.expect {
+			t.Errorf("%s: field %s expects type %s, got %s", tc.db.Dialector.Name(), tc.column.name, tc.expect, typ)
+		}
+	}
+}
+
+func TestGetFieldsWithPostgresArray(t *testing.T) {
+	columns := []testColumn{
+		{name: "ids", dataType: "_int4", columnType: "integer[]", scanType: reflect.TypeOf("")},
+		{name: "tags", dataType: "_varchar", columnType: "character varying(32)[]", scanType: reflect.TypeOf("")},
+		{name: "refs", dataType: "uuid[]", columnType: "uuid[]", scanType: reflect.TypeOf("")},
+		{name: "matrix", dataType: "_int8", columnType: "bigint[][]", scanType: reflect.TypeOf("")}, // multi-dimensional
+		{name: "points", dataType: "_point", columnType: "point[]", scanType: reflect.TypeOf([]byte{})},
+		{name: "id", dataType: "bigint", columnType: "bigint", scanType: reflect.TypeOf(int64(0))},
+	}
+	testcases := []struct {
+		db    *gorm.DB
+		lib   model.ArrayLib
+		types []string
+	}{
+		{postgresDB, model.ArrayLibPQ, []string{"pq.Int32Array", "pq.StringArray", "pq.StringArray", "string", "[]uint8", "int64"}},
+		{postgresDB, model.ArrayLibPgx, []string{"pgtype.Int4Array", "pgtype.VarcharArray", "pgtype.UUIDArray", "string", "[]uint8", "int64"}},
+		{postgresDB, "", []string{"string", "string", "string", "string", "string", "int64"}},
+		{mysqlDB, model.ArrayLibPQ, []string{"string", "string", "string", "string", "string", "int64"}},
+	}
+	for _, tc := range testcases {
+		conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldArrayLib: tc.lib}}
+		var fields []*model.Field
+		for i, c := range columns {
+			f := getFields(tc.db, conf, []*model.Column{c.column()})[0]
+			if f.Type != tc.types[i] {
+				t.Errorf("%s with array lib %q: field %s expects type %s, got %s", tc.db.Dialector.Name(), tc.lib, c.name, tc.types[i], f.Type)
+			}
+			fields = append(fields, f)
+		}
+		if expect := tc.lib != "" && tc.db.Dialector.Name() == "postgres"; hasArrayField(tc.lib, fields) != expect {
+			t.Errorf("%s with array lib %q: expect array field %t", tc.db.Dialector.Name(), tc.lib, expect)
+		}
+	}
+}
+
+func TestGetFieldsWithDateType(t *testing.T) {
+	columns := []testColumn{
+		{name: "birthday", dataType: "date"},
+		{name: "expired_on", dataType: "DATE", nullable: true},
+		{name: "created_at", dataType: "timestamp"},
+		{name: "updated_at", dataType: "datetime"},
+		{name: "opened_at", dataType: "time"},
+	}
+	testcases := []struct {
+		db       *gorm.DB
+		dateType string
+		types    []string
+	}{
+		{mysqlDB, "civil.Date", []string{"civil.Date", "*civil.Date", "time.Time", "time.Time", "time.Time"}},
+		{postgresDB, "civil.Date", []string{"civil.Date", "*civil.Date", "time.Time", "time.Time", "time.Time"}},
+		{mysqlDB, "", []string{"time.Time", "*time.Time", "time.Time", "time.Time", "time.Time"}},
+		{dummyDB, "civil.Date", []string{"time.Time", "*time.Time", "time.Time", "time.Time", "time.Time"}}, // DATE may have time of day
+	}
+	for _, tc := range testcases {
+		conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldDateType: tc.dateType, FieldNullable: true}}
+		for i, c := range columns {
+			if typ := getFields(tc.db, conf, []*model.Column{c.column()})[0].Type; typ != tc.types[i] {
+				t.Errorf("%s with date type %q: field %s expects type %s, got %s", tc.db.Dialector.Name(), tc.dateType, c.name, tc.types[i], typ)
+			}
+		}
+	}
+}
+
+func TestGetFieldsWithArraySerializer(t *testing.T) {
+	tags := testColumn{name: "tags", dataType: "_text", columnType: "text[]", scanType: reflect.TypeOf("")}
+	points := testColumn{name: "points", dataType: "_point", columnType: "point[]", scanType: reflect.TypeOf("")}
+	labels := testColumn{name: "labels", dataType: "jsonb", columnType: "jsonb", scanType: reflect.TypeOf("")}
+	jsonTypes := map[string]model.JSONType{"labels": {GoType: "[]string"}}
+	testcases := []struct {
+		lib        model.ArrayLib
+		column     testColumn
+		typ        string
+		serializer []string
+	}{
+		{model.ArrayLibPQ, tags, "pq.StringArray", nil},
+		// native array can't be written by json serializer
+		{model.ArrayLibJSON, tags, "pq.StringArray", nil},
+		{model.ArrayLibJSON, points, "string", nil},
+		{model.ArrayLibJSON, labels, "[]string", []string{"json"}},
+		{model.ArrayLibPQ, labels, "[]string", nil},
+	}
+	for _, tc := range testcases {
+		conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldArrayLib: tc.lib, FieldJSONTypes: jsonTypes}}
+		f := getFields(postgresDB, conf, []*model.Column{tc.column.column()})[0]
+		if f.Type != tc.typ {
+			t.Errorf("array lib %q: column %s expects type %s, got %s", tc.lib, f.ColumnName, tc.typ, f.Type)
+		}
+		if serializer := f.GORMTag[field.TagKeyGormSerializer]; !reflect.DeepEqual(serializer, tc.serializer) {
+			t.Errorf("array lib %q: column %s expects serializer %v, got %v", tc.lib, f.ColumnName, tc.serializer, serializer)
+		}
+	}
+
+	// value of generated field round trips through json text stored in jsonb column
+	conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldArrayLib: model.ArrayLibJSON, FieldJSONTypes: jsonTypes}}
+	tag := getFields(postgresDB, conf, []*model.Column{labels.column()})[0].GORMTag.Build()
+	if tag != "column:labels;not null;serializer:json" {
+		t.Fatalf("unexpected gorm tag: %s", tag)
+	}
+	typ := reflect.StructOf([]reflect.StructField{{Name: "Labels", Type: reflect.TypeOf([]string(nil)), Tag: reflect.StructTag(`gorm:"` + tag + `"`)}})
+	s, err := schema.Parse(reflect.New(typ).Interface(), &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("parse model fail: %s", err)
+	}
+	f := s.LookUpField("labels")
+	src := reflect.New(typ).Elem()
+	src.Field(0).Set(reflect.ValueOf([]string{"a", "b"}))
+	v, _ := f.ValueOf(context.Background(), src)
+	stored, err := v.(driver.Valuer).Value()
+	if err != nil || stored != `["a","b"]` {
+		t.Fatalf("expect json array written, got %v %v", stored, err)
+	}
+	scanned := f.NewValuePool.Get()
+	if err := scanned.(sql.Scanner).Scan([]byte(`["a","b"]`)); err != nil {
+		t.Fatalf("scan json array fail: %s", err)
+	}
+	dst := reflect.New(typ).Elem()
+	if err := f.Set(context.Background(), dst, scanned); err != nil {
+		t.Fatalf("set field fail: %s", err)
+	}
+	if got := dst.Field(0).Interface(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("expect [a b] read back, got %v", got)
+	}
+}
+
+func TestGetFieldsWithDefaultValueTag(t *testing.T) {
+	defaultValue := func(value string) sql.NullString { return sql.NullString{String: value, Valid: true} }
+	testcases := []struct {
+		column testColumn
+		quoted []string // default tag with FieldWithDefaultValueTag, nil if no default
+		raw    []string
+	}{
+		{testColumn{name: "status", dataType: "varchar", defaultValue: defaultValue("it's active"), scanType: reflect.TypeOf("")}, []string{"'it''s active'"}, []string{"it's active"}},
+		{testColumn{name: "quantity", dataType: "int", defaultValue: defaultValue("10"), scanType: reflect.TypeOf(int32(0))}, []string{"10"}, []string{"10"}},
+		{testColumn{name: "created_at", dataType: "datetime", defaultValue: defaultValue("CURRENT_TIMESTAMP"), scanType: reflect.TypeOf(time.Time{})}, []string{"CURRENT_TIMESTAMP"}, []string{"CURRENT_TIMESTAMP"}},
+		{testColumn{name: "code", dataType: "varchar", defaultValue: defaultValue("uuid()"), scanType: reflect.TypeOf("")}, []string{"uuid()"}, []string{"uuid()"}},
+		{testColumn{name: "updated_by", dataType: "varchar", defaultValue: defaultValue("current_user"), scanType: reflect.TypeOf("")}, []string{"current_user"}, []string{"current_user"}},
+		{testColumn{name: "id", dataType: "uuid", defaultValue: defaultValue("uuid_generate_v4()"), scanType: reflect.TypeOf("")}, []string{"uuid_generate_v4()"}, []string{"uuid_generate_v4()"}},
+		{testColumn{name: "note", dataType: "varchar", defaultValue: defaultValue(""), scanType: reflect.TypeOf("")}, []string{"''"}, []string{"''"}},
+		{testColumn{name: "remark", dataType: "varchar", scanType: reflect.TypeOf("")}, nil, nil}, // without default, not an empty string default
+	}
+	for _, tc := range testcases {
+		tc.column.nullable = true
+		for _, withDefaultValueTag := range []bool{true, false} {
+			expect := tc.raw
+			if withDefaultValueTag {
+				expect = tc.quoted
+			}
+			conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldWithDefaultValueTag: withDefaultValueTag}}
+			if value := getFields(mysqlDB, conf, []*model.Column{tc.column.column()})[0].GORMTag[field.TagKeyGormDefault]; !reflect.DeepEqual(value, expect) {
+				t.Errorf("FieldWithDefaultValueTag=%t: field %s expects default tag %v, got %v", withDefaultValueTag, tc.column.name, expect, value)
+			}
+		}
+	}
+}
+
+func TestGetFieldsWithNullableExceptDefaulted(t *testing.T) {
+	testcases := []struct {
+		name         string
+		defaultValue sql.NullString
+		defaulted    string // type with FieldNullableExceptDefaulted
+		nullable     string
+	}{
+		{"quantity", sql.NullString{String: "1", Valid: true}, "int32", "*int32"},
+		{"score", sql.NullString{}, "*int32", "*int32"},
+		{"rank", sql.NullString{String: "NULL", Valid: true}, "*int32", "*int32"},
+	}
+	for _, tc := range testcases {
+		c := testColumn{name: tc.name, dataType: "int", nullable: true, defaultValue: tc.defaultValue, scanType: reflect.TypeOf(int32(0))}
+		for _, exceptDefaulted := range []bool{true, false} {
+			expect := tc.nullable
+			if exceptDefaulted {
+				expect = tc.defaulted
+			}
+			conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldNullable: true, FieldNullableExceptDefaulted: exceptDefaulted}}
+			if typ := getFields(mysqlDB, conf, []*model.Column{c.column()})[0].Type; typ != expect {
+				t.Errorf("FieldNullableExceptDefaulted=%t: field %s expects type %s, got %s", exceptDefaulted, tc.name, expect, typ)
+			}
+		}
+	}
+}
+
+func TestGetFieldsWithNullable(t *testing.T) {
+	testcases := []struct {
+		dataType    string
+		columnType  string
+		scanType    reflect.Type
+		useScanType bool
+		base        string
+	}{
+		{"varchar", "varchar(64)", reflect.TypeOf(""), false, "string"},
+		{"int", "int", reflect.TypeOf(int32(0)), false, "int32"},
+		{"bigint", "bigint", reflect.TypeOf(int64(0)), false, "int64"},
+		{"float", "float", reflect.TypeOf(float32(0)), false, "float32"},
+		{"double", "double", reflect.TypeOf(float64(0)), false, "float64"},
+		{"tinyint", "tinyint(1)", reflect.TypeOf(false), false, "bool"},
+		{"blob", "blob", reflect.TypeOf([]byte{}), false, "[]byte"},
+		{"datetime", "datetime", reflect.TypeOf(time.Time{}), false, "time.Time"},
+		{"bytea", "bytea", reflect.TypeOf([]byte{}), true, "[]byte"},
+		{"timestamptz", "timestamptz", reflect.TypeOf(time.Time{}), true, "time.Time"},
+		{"text", "text", reflect.TypeOf(sql.NullString{}), true, "string"},
+		{"bool", "bool", reflect.TypeOf(sql.NullBool{}), true, "bool"},
+		{"int2", "int2", reflect.TypeOf(sql.NullInt16{}), true, "int16"},
+		{"int4", "int4", reflect.TypeOf(sql.NullInt32{}), true, "int32"},
+		{"int8", "int8", reflect.TypeOf(sql.NullInt64{}), true, "int64"},
+		{"float8", "float8", reflect.TypeOf(sql.NullFloat64{}), true, "float64"},
+		{"timestamp", "timestamp", reflect.TypeOf(sql.NullTime{}), true, "time.Time"},
+	}
+	for _, tc := range testcases {
+		for _, nullable := range []bool{true, false} {
+			c := testColumn{name: "value", dataType: tc.dataType, columnType: tc.columnType, nullable: nullable, useScanType: tc.useScanType, scanType: tc.scanType}
+			conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldNullable: true}}
+			expect := tc.base
+			if nullable {
+				expect = "*" + tc.base
+			}
+			if typ := getFields(mysqlDB, conf, []*model.Column{c.column()})[0].Type; typ != expect {
+				t.Errorf("%s nullable=%t expects type %s, got %s", tc.columnType, nullable, expect, typ)
+			}
+		}
+	}
+
+	// scan type is kept as is without FieldNullable
+	c := testColumn{name: "value", dataType: "bytea", nullable: true, useScanType: true, scanType: reflect.TypeOf([]byte{})}
+	if typ := getFields(mysqlDB, &model.Config{ModelPkg: "model"}, []*model.Column{c.column()})[0].Type; typ != "[]uint8" {
+		t.Errorf("bytea without FieldNullable expects type []uint8, got %s", typ)
+	}
+}
+
+func TestGetFieldsWithUnsigned(t *testing.T) {
+	conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldSignable: true}}
+
+	testcases := []struct {
+		column testColumn
+		expect string
+	}{
+		{testColumn{name: "big_unsigned", dataType: "bigint", columnType: "bigint", unsigned: true}, "uint64"},
+		{testColumn{name: "big_signed", dataType: "bigint", columnType: "bigint"}, "int64"},
+		{testColumn{name: "tiny_unsigned", dataType: "tinyint", columnType: "tinyint unsigned"}, "uint8"},
+		{testColumn{name: "int_unsigned", dataType: "int", columnType: "int(10) UNSIGNED"}, "uint32"},
+		{testColumn{name: "flag", dataType: "tinyint", columnType: "tinyint(1) unsigned"}, "bool"},
+	}
+	for _, tc := range testcases {
+		tc.column.scanType = reflect.TypeOf(int64(0))
+		if typ := getFields(mysqlDB, conf, []*model.Column{tc.column.column()})[0].Type; typ != tc.expect {
+			t.Errorf("field %s expects type %s, got %s", tc.column.name, tc.expect, typ)
+		}
+	}
+}
+
+func TestGetFieldsWithPreciseIntegerTypes(t *testing.T) {
+	testcases := []struct {
+		db            *gorm.DB
+		typ           string
+		columnType    string
+		tinyIntAsBool bool
+		expect        string
+	}{
+		{mysqlDB, "tinyint", "tinyint(4)", false, "int8"},
+		{mysqlDB, "tinyint", "tinyint(3) unsigned", false, "uint8"},
+		{mysqlDB, "TINYINT", "TINYINT UNSIGNED", false, "uint8"},
+		{mysqlDB, "tinyint", "tinyint(1)", false, "int8"},
+		{mysqlDB, "tinyint", "tinyint(1)", true, "bool"},
+		{mysqlDB, "tinyint", "tinyint(4)", true, "int8"},
+		{mysqlDB, "smallint", "smallint(6)", false, "int16"},
+		{mysqlDB, "smallint", "smallint(5) unsigned", false, "uint16"},
+		{mysqlDB, "mediumint", "mediumint(9)", false, "int32"},
+		{mysqlDB, "mediumint", "mediumint(8) unsigned", false, "uint32"},
+		{mysqlDB, "int", "int(11)", false, "int32"},
+		{mysqlDB, "int", "int(10) UNSIGNED", false, "uint32"},
+		{mysqlDB, "bigint", "bigint(20)", false, "int64"},
+		{mysqlDB, "bigint", "bigint(20) unsigned", false, "uint64"},
+		{mysqlDB, "varchar", "varchar(32)", false, "string"},
+		{postgresDB, "int2", "smallint", false, "int16"},
+		{postgresDB, "int4", "integer", false, "int32"},
+		{postgresDB, "int8", "bigint", false, "int64"},
+		{postgresDB, "integer", "integer", false, "int32"},
+		{sqliteDB, "tinyint", "tinyint", false, "int32"},    // kept as default mapping
+		{sqlserverDB, "tinyint", "tinyint", false, "uint8"}, // TINYINT of sqlserver is 0..255
+		{sqlserverDB, "smallint", "smallint", false, "int16"},
+		{sqlserverDB, "int", "int", false, "int32"},
+		{sqlserverDB, "bigint", "bigint", false, "int64"},
+	}
+	for _, tc := range testcases {
+		conf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{
+			FieldPreciseIntegerTypes: true, FieldPreciseTinyIntAsBool: tc.tinyIntAsBool,
+		}}
+		c := testColumn{name: "amount", dataType: tc.typ, columnType: tc.columnType, scanType: reflect.TypeOf(int64(0))}
+		if typ := getFields(tc.db, conf, []*model.Column{c.column()})[0].Type; typ != tc.expect {
+			t.Errorf("%s column %s (tinyint as bool: %t) expects type %s, got %s",
+				tc.db.Dialector.Name(), tc.columnType, tc.tinyIntAsBool, tc.expect, typ)
+		}
+	}
+}
+
+func TestGetFieldsWithSoftDelete(t *testing.T) {
+	testcases := []struct {
+		column     testColumn
+		expect     string
+		softDelete string // type with FieldSoftDeleteNames removed_at
+	}{
+		{testColumn{name: "deleted_at", dataType: "timestamp", nullable: true}, "gorm.DeletedAt", "time.Time"},
+		{testColumn{name: "removed_at", dataType: "timestamp", nullable: true}, "time.Time", "gorm.DeletedAt"},
+		{testColumn{name: "removed_at", dataType: "varchar", nullable: true}, "string", "string"},
+		{testColumn{name: "removed_at", dataType: "datetime"}, "time.Time", "time.Time"},
+		{testColumn{name: "deleted_at", dataType: "datetime"}, "gorm.DeletedAt", "time.Time"},
+	}
+	softDeleteConf := &model.Config{ModelPkg: "model", FieldConfig: model.FieldConfig{FieldSoftDeleteNames: []string{"removed_at"}}}
+	for _, tc := range testcases {
+		tc.column.scanType = reflect.TypeOf("")
+		if typ := getFields(dummyDB, &model.Config{ModelPkg: "model"}, []*model.Column{tc.column.column()})[0].Type; typ != tc.expect {
+			t.Errorf("field %s %s expects type %s, got %s", tc.column.name, tc.column.dataType, tc.expect, typ)
+		}
+		if typ := getFields(dummyDB, softDeleteConf, []*model.Column{tc.column.column()})[0].Type; typ != tc.softDelete {
+			t.Errorf("field %s %s with soft delete name removed_at expects type %s, got %s", tc.column.name, tc.column.dataType, tc.softDelete, typ)
+		}
+	}
+}
+
+func TestGetQueryStructMetaWithTableSchema(t *testing.T) {
+	info := catalogTableInfo{"events": testColumn{dataType: "bigint", scanType: reflect.TypeOf(int64(0))}.columns("events", "id")}
+
+	testcases := []struct {
+		schemaName string
+		withSchema bool
+		prefix     string
+		expect     string
+	}{
+		{"analytics", true, "", `const TableNameEvent = "analytics.events"`},
+		{"public", true, "", `const TableNameEvent = "events"`},
+		{"analytics", false, "", `const TableNameEvent = "events"`},
+		{"public", false, "analytics", `const TableNameEvent = "analytics.events"`},
+		{"staging", true, "analytics", `const TableNameEvent = "analytics.events"`},
+	}
+	for _, tc := range testcases {
+		schemaName := tc.schemaName
+		meta, err := GetQueryStructMeta(dummyDB, &model.Config{TableName: "events", ModelName: "Event", ModelPkg: "model", TableInfo: info,
+			NameStrategy: model.NameStrategy{
+				SchemaNameOpts:      []model.SchemaNameOpt{func(*gorm.DB) string { return schemaName }},
+				TableNameWithSchema: tc.withSchema,
+				TableNameSchema:     tc.prefix,
+			}})
+		if err != nil {
+			t.Fatalf("generate model fail: %s", err)
+		}
+
+		var buf bytes.Buffer
+		if err := template.Must(template.New("model").Parse(tmpl.Model)).Execute(&buf, meta); err != nil {
+			t.Fatalf("render model fail: %s", err)
+		}
+		for _, method := range meta.ModelMethods {
+			if method.MethodName == "TableName" {
+				buf.WriteString(method.Body)
+			}
+		}
+		if !strings.Contains(buf.String(), tc.expect) || !strings.Contains(buf.String(), "return TableNameEvent") {
+			t.Errorf("schema %s with schema %t prefix %q expects %q, got:\n%s", tc.schemaName, tc.withSchema, tc.prefix, tc.expect, buf.String())
+		}
+	}
+}
+
+func TestGetFieldsWithInitialisms(t *testing.T) {
+	conf := &model.Config{ModelPkg: "model", NameStrategy: model.NameStrategy{Initialisms: []string{"OAuth2", "SKU"}}}
+
+	testcases := []struct {
+		column string
+		expect string
+	}{
+		{"user_id", "UserID"},
+		{"oauth2_token", "OAuth2Token"},
+		{"user_sku_code", "UserSKUCode"},
+		{"item_sku", "ItemSKU"},
+		{"skull", "Skull"},
+	}
+	for _, tc := range testcases {
+		c := testColumn{name: tc.column, dataType: "varchar", scanType: reflect.TypeOf("")}
+		if name := getFields(dummyDB, conf, []*model.Column{c.column()})[0].Name; name != tc.expect {
+			t.Errorf("column %s expects field name %s, got %s", tc.column, tc.expect, name)
+		}
+	}
+}
+
+// houseNaming singular model names, columns with url suffix are named as Link
+type houseNaming struct{}
+
+func (houseNaming) TableName(tableName string) string { return "app_" + tableName }
+
+func (houseNaming) ModelName(tableName string) string {
+	return schema.NamingStrategy{SingularTable: true}.SchemaName(tableName)
+}
+
+func (houseNaming) ColumnName(_, columnName string) string {
+	if strings.HasSuffix(columnName, "_url") {
+		return strings.TrimSuffix(columnName, "_url") + "_link"
+	}
+	return ""
+}
+
+func TestGetQueryStructMetaWithNamingStrategy(t *testing.T) {
+	info := catalogTableInfo{"app_news": testColumn{dataType: "varchar", scanType: reflect.TypeOf("")}.columns("news", "id", "home_url", "user_name", "userName", "UserName")}
+	ns := model.NameStrategy{Naming: houseNaming{}, Initialisms: []string{"ID"}}
+
+	modelName := ns.DefaultModelName(dummyDB, "news")
+	if modelName != "News" {
+		t.Errorf("expect model name News, got %s", modelName)
+	}
+	meta, err := GetQueryStructMeta(dummyDB, &model.Config{TableName: "news", ModelName: modelName, TableInfo: info, NameStrategy: ns})
+	if err != nil {
+		t.Fatalf("generate model fail: %s", err)
+	}
+	if meta.TableName != "app_news" {
+		t.Errorf("expect table name app_news, got %s", meta.TableName)
+	}
+	var names []string
+	for _, f := range meta.Fields {
+		names = append(names, f.Name)
+	}
+	if expect := []string{"ID", "HomeLink", "UserName", "UserName2", "UserName3"}; !reflect.DeepEqual(names, expect) {
+		t.Errorf("expect field names %v, got %v", expect, names)
+	}
+}
+
+func TestGetQueryStructMetaWithJSONPaths(t *testing.T) {
+	info := catalogTableInfo{"events": {
+		testColumn{table: "events", name: "id", dataType: "bigint"}.column(),
+		testColumn{table: "events", name: "metadata", dataType: "jsonb"}.column(),
+	}}
+
+	testcases := []struct {
+		db     *gorm.DB
+		path   model.JSONPathOpt
+		expect string // sql of condition, or error
+	}{
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "tenant_id", Name: "TenantID", Type: "string"}, "?->>'tenant_id' = ?"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "owner.id", Name: "OwnerID", Type: "int64"}, "(?#>>'{owner,id}')::bigint = ?"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "size", Name: "Size", Type: "uint64"}, "(?->>'size')::numeric = ?"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "score", Name: "Score", Type: "float64"}, "(?->>'score')::double precision = ?"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "active", Name: "Active", Type: "bool"}, "(?->>'active')::boolean = ?"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "at", Name: "At", Type: "time.Time"}, "(?->>'at')::timestamptz = ?"},
+		{mysqlDB, model.JSONPathOpt{Column: "metadata", Path: "$.owner.id", Name: "OwnerID", Type: "int64"}, "CAST(?->>'$.owner.id' AS SIGNED) = ?"},
+		{mysqlDB, model.JSONPathOpt{Column: "metadata", Path: "size", Name: "Size", Type: "uint"}, "CAST(?->>'$.size' AS UNSIGNED) = ?"},
+		{mysqlDB, model.JSONPathOpt{Column: "metadata", Path: "score", Name: "Score", Type: "float32"}, "CAST(?->>'$.score' AS DOUBLE) = ?"},
+		{mysqlDB, model.JSONPathOpt{Column: "metadata", Path: "active", Name: "Active", Type: "bool"}, "?->>'$.active' = IF(?, 'true', 'false')"},
+		{mysqlDB, model.JSONPathOpt{Column: "metadata", Path: "tenant_id", Name: "TenantID", Type: "string"}, "?->>'$.tenant_id' = ?"},
+		{mysqlDB, model.JSONPathOpt{Column: "metadata", Path: "at", Name: "At", Type: "time.Time"}, "only supported by postgres"},
+		{sqliteDB, model.JSONPathOpt{Column: "metadata", Path: "owner.id", Name: "OwnerID", Type: "int64"}, "?->>'$.owner.id' = ?"},
+		{sqliteDB, model.JSONPathOpt{Column: "metadata", Path: "at", Name: "At", Type: "time.Time"}, "only supported by postgres"},
+		{sqlserverDB, model.JSONPathOpt{Column: "metadata", Path: "tenant_id", Name: "TenantID", Type: "string"}, "not supported by dialect sqlserver"},
+		{postgresDB, model.JSONPathOpt{Column: "meta", Path: "tenant_id", Name: "TenantID", Type: "string"}, "column meta is not found"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "tenant_id", Name: "tenantID", Type: "string"}, "not an exported identifier"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "tenant_id", Name: "TenantID", Type: "uuid.UUID"}, "not a builtin type"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "tenant_id", Name: "TenantID", Type: "append"}, "not a builtin type"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "tenant_id", Name: "TenantID", Type: "true"}, "not a builtin type"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "tenant_id", Name: "TenantID", Type: "any"}, "not a string, bool or number"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "tenant_id", Name: "TenantID", Type: "complex128"}, "not a string, bool or number"},
+		{postgresDB, model.JSONPathOpt{Column: "metadata", Path: "it's", Name: "TenantID", Type: "string"}, "is invalid"},
+	}
+	for _, tc := range testcases {
+		meta, err := GetQueryStructMeta(tc.db, &model.Config{TableName: "events", ModelName: "Event", TableInfo: info,
+			ModelOpts: []model.Option{tc.path}})
+		if err != nil {
+			if !strings.Contains(err.Error(), tc.expect) {
+				t.Errorf("json path %+v: expect %q, got error %s", tc.path, tc.expect, err)
+			}
+			continue
+		}
+		if len(meta.JSONPathConds) != 1 || meta.JSONPathConds[0].SQL != tc.expect || meta.JSONPathConds[0].Name != "Where"+tc.path.Name {
+			t.Errorf("json path %+v: expect condition %q, got %+v", tc.path, tc.expect, meta.JSONPathConds)
+		}
+	}
+}
+
+func TestGetQueryStructMetaWithTagProviders(t *testing.T) {
+	info := catalogTableInfo{"users": {
+		testColumn{table: "users", name: "name", dataType: "varchar"}.column(),
+		testColumn{table: "users", name: "nickname", dataType: "varchar", nullable: true}.column(),
+	}}
+	validate := func(c *model.Column) (string, string, bool) {
+		if nullable, ok := c.Nullable(); ok && !nullable {
+			return "validate", "required", true
+		}
+		return "", "", false
+	}
+	msgpack := func(c *model.Column) (string, string, bool) { return "msgpack", c.Name(), true }
+	jsonTag := func(c *model.Column) (string, string, bool) { return "json", c.Name(), true }
+
+	meta, err := GetQueryStructMeta(dummyDB, &model.Config{TableName: "users", ModelName: "User", TableInfo: info,
+		FieldConfig: model.FieldConfig{FieldTagProviders: []model.TagProvider{validate, msgpack}}})
+	if err != nil {
+		t.Fatalf("generate model with tag providers fail: %s", err)
+	}
+	expects := []string{
+		`gorm:"column:name;type:varchar;not null" json:"name" msgpack:"name" validate:"required"`,
+		`gorm:"column:nickname;type:varchar" json:"nickname" msgpack:"nickname"`,
+	}
+	for i, expect := range expects {
+		if tags := meta.Fields[i].Tags(); tags != expect {
+			t.Errorf("tags of field %s: expect %s, got %s", meta.Fields[i].Name, expect, tags)
+		}
+	}
+
+	for _, providers := range [][]model.TagProvider{{msgpack, validate, msgpack}, {jsonTag}} {
+		if _, err := GetQueryStructMeta(dummyDB, &model.Config{TableName: "users", ModelName: "User", TableInfo: info,
+			FieldConfig: model.FieldConfig{FieldTagProviders: providers}}); err == nil {
+			t.Errorf("generate model with conflicting tag providers expects error")
+		}
+	}
+}
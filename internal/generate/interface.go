@@ -11,27 +11,42 @@ import (
 
 // InterfaceMethod interface's method
 type InterfaceMethod struct { // feature will replace InterfaceMethod to parser.Method
-	Doc           string         // comment
-	S             string         // First letter of
-	OriginStruct  parser.Param   // origin struct name
-	TargetStruct  string         // generated query struct bane
-	MethodName    string         // generated function name
-	Params        []parser.Param // function input params
-	Result        []parser.Param // function output params
-	ResultData    parser.Param   // output data
-	Section       *Section       // Parse split SQL into sections
-	SQLParams     []parser.Param // variable in sql need function input
-	SQLString     string         // SQL
-	GormOption    string         // gorm execute method Find or Exec or Take
-	Table         string         // specified by user. if empty, generate it with gorm
-	InterfaceName string         // origin interface name
-	Package       string         // interface package name
-	HasForParams  bool           //
+	Doc             string         // comment
+	S               string         // First letter of
+	OriginStruct    parser.Param   // origin struct name
+	TargetStruct    string         // generated query struct bane
+	MethodName      string         // generated function name
+	Params          []parser.Param // function input params
+	Result          []parser.Param // function output params
+	ResultData      parser.Param   // output data
+	Section         *Section       // Parse split SQL into sections
+	SQLParams       []parser.Param // variable in sql need function input
+	SQLString       string         // SQL
+	GormOption      string         // gorm execute method Find or Exec or Take
+	Table           string         // specified by user. if empty, generate it with gorm
+	InterfaceName   string         // origin interface name
+	Package         string         // interface package name
+	HasForParams    bool           //
+	ContextFirstArg bool           // add ctx as first argument, set by ContextFirstArg of generator
 }
 
 // FuncSign function signature
 func (m *InterfaceMethod) FuncSign() string {
-	return fmt.Sprintf("%s(%s) (%s)", m.MethodName, m.GetParamInTmpl(), m.GetResultParamInTmpl())
+	params := m.GetParamInTmpl()
+	if m.ContextFirstArg {
+		params = strings.TrimSuffix("ctx context.Context,"+params, ",")
+	}
+	return fmt.Sprintf("%s(%s) (%s)", m.MethodName, params, m.GetResultParamInTmpl())
+}
+
+// HasParam method has input param named name
+func (m *InterfaceMethod) HasParam(name string) bool {
+	for _, param := range m.Params {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
 }
 
 // HasSQLData has variable or for params will creat params map
@@ -373,9 +388,10 @@ func (m *InterfaceMethod) sqlStateCheckAndSplit() error {
 				return fmt.Errorf("incomplete SQL:%s", sqlString)
 			}
 			if b == '{' && sqlString[i+1] == '{' {
+				line := strings.Count(sqlString[:i], "\n") + 1
 				for i += 2; ; i++ {
 					if strOutRange(i, sqlString) {
-						return fmt.Errorf("incomplete SQL:%s", sqlString)
+						return fmt.Errorf("incomplete SQL, {{ at line %d is not closed by }}: %s", line, sqlLine(sqlString, line))
 					}
 					if sqlString[i] == '"' {
 						_ = buf.WriteByte(sqlString[i])
@@ -392,7 +408,7 @@ func (m *InterfaceMethod) sqlStateCheckAndSplit() error {
 					}
 
 					if strOutRange(i+1, sqlString) {
-						return fmt.Errorf("incomplete SQL:%s", sqlString)
+						return fmt.Errorf("incomplete SQL, {{ at line %d is not closed by }}: %s", line, sqlLine(sqlString, line))
 					}
 					if sqlString[i] == '}' && sqlString[i+1] == '}' {
 						i++
@@ -401,6 +417,7 @@ func (m *InterfaceMethod) sqlStateCheckAndSplit() error {
 						if err != nil {
 							return fmt.Errorf("sql [%s] dynamic template %s err:%w", sqlString, sqlClause, err)
 						}
+						part.Line = line
 						m.Section.members = append(m.Section.members, part)
 						break
 					}
@@ -442,6 +459,15 @@ func (m *InterfaceMethod) sqlStateCheckAndSplit() error {
 	return nil
 }
 
+// sqlLine return trimmed content of line in sql, line starts from 1
+func sqlLine(sqlString string, line int) string {
+	lines := strings.Split(sqlString, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
+
 // checkSQLVarByParams return external parameters, table name
 func (m *InterfaceMethod) checkSQLVarByParams(param string, status model.Status) (result section, err error) {
 	for _, p := range m.Params {
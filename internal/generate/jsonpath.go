@@ -0,0 +1,121 @@
+package generate
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"gorm.io/gen/internal/model"
+)
+
+// JSONPathCond condition method of value extracted from json column, e.g. WhereTenantID(value string)
+type JSONPathCond struct {
+	Name   string // method name
+	Column string // json column name
+	Path   string // dotted path of value in json document
+	Type   string // go type of value
+	SQL    string // sql extracting value compared with value, column is the first var, e.g. ?->>'tenant_id' = ?
+}
+
+// getJSONPathConds build condition methods of json paths declared by FieldJSONPath, extracting operator follows
+// dialect of db: ->> '$.path' of mysql and sqlite, ->> 'key' or #>> '{path}' of postgres. Extracted text is cast
+// to goType, e.g. (?->>'id')::bigint of postgres and CAST(?->>'$.id' AS SIGNED) of mysql
+func getJSONPathConds(db *gorm.DB, paths []model.JSONPathOpt, fields []*model.Field) ([]JSONPathCond, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	dialect := db.Dialector.Name()
+	switch dialect {
+	case "mysql", "sqlite", "postgres":
+	default:
+		return nil, fmt.Errorf("json path is not supported by dialect %s", dialect)
+	}
+	columns := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if !f.IsRelation() && f.ColumnName != "" {
+			columns[f.ColumnName] = true
+		}
+	}
+
+	conds := make([]JSONPathCond, 0, len(paths))
+	for _, p := range paths {
+		switch {
+		case !columns[p.Column]:
+			return nil, fmt.Errorf("json path %s: column %s is not found", p.Path, p.Column)
+		case !token.IsExported(p.Name):
+			return nil, fmt.Errorf("json path %s of column %s: name %q is not an exported identifier", p.Path, p.Column, p.Name)
+		}
+		path := strings.TrimPrefix(p.Path, "$.")
+		if path == "" || strings.ContainsAny(path, "?'") {
+			return nil, fmt.Errorf("json path %q of column %s is invalid", p.Path, p.Column)
+		}
+
+		var extract string
+		switch dialect {
+		case "mysql", "sqlite":
+			extract = fmt.Sprintf("?->>'$.%s'", path)
+		default:
+			if keys := strings.Split(path, "."); len(keys) > 1 {
+				extract = fmt.Sprintf("?#>>'{%s}'", strings.Join(keys, ","))
+			} else {
+				extract = fmt.Sprintf("?->>'%s'", path)
+			}
+		}
+		condSQL, err := jsonPathCondSQL(dialect, extract, p.Type)
+		if err != nil {
+			return nil, fmt.Errorf("json path %s of column %s: %w", p.Path, p.Column, err)
+		}
+		conds = append(conds, JSONPathCond{Name: "Where" + p.Name, Column: p.Column, Path: path, Type: p.Type, SQL: condSQL})
+	}
+	return conds, nil
+}
+
+// jsonPathCondSQL compare text extracted by extract with value of goType, ->> of sqlite returns sql value of json
+// value instead of text, so only time.Time is rejected
+func jsonPathCondSQL(dialect, extract, goType string) (string, error) {
+	if goType == "time.Time" {
+		if dialect != "postgres" {
+			return "", fmt.Errorf("type time.Time is only supported by postgres, use string instead")
+		}
+		return "(" + extract + ")::timestamptz = ?", nil
+	}
+	obj, ok := types.Universe.Lookup(goType).(*types.TypeName)
+	if !ok {
+		return "", fmt.Errorf("type %q is not a builtin type or time.Time", goType)
+	}
+	basic, ok := obj.Type().(*types.Basic)
+	if !ok || basic.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsString) == 0 {
+		return "", fmt.Errorf("type %q is not a string, bool or number", goType)
+	}
+
+	info := basic.Info()
+	switch {
+	case info&types.IsString != 0 || dialect == "sqlite":
+		return extract + " = ?", nil
+	case dialect == "postgres":
+		cast := "double precision"
+		switch {
+		case info&types.IsBoolean != 0:
+			cast = "boolean"
+		case info&types.IsUnsigned != 0:
+			cast = "numeric"
+		case info&types.IsInteger != 0:
+			cast = "bigint"
+		}
+		return "(" + extract + ")::" + cast + " = ?", nil
+	default: // mysql extracts json true and false as text 'true' and 'false'
+		switch {
+		case info&types.IsBoolean != 0:
+			return extract + " = IF(?, 'true', 'false')", nil
+		case info&types.IsUnsigned != 0:
+			return "CAST(" + extract + " AS UNSIGNED) = ?", nil
+		case info&types.IsInteger != 0:
+			return "CAST(" + extract + " AS SIGNED) = ?", nil
+		default:
+			return "CAST(" + extract + " AS DOUBLE) = ?", nil
+		}
+	}
+}
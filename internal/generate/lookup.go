@@ -0,0 +1,78 @@
+package generate
+
+import (
+	"go/token"
+	"strings"
+)
+
+// IndexLookup lookup method of column backing a unique single-column index, e.g. WhereByEmail(email string)
+type IndexLookup struct {
+	Name    string // method name
+	Column  string // column name
+	Index   string // name of unique index backing column
+	Param   string // parameter name
+	Type    string // go type of parameter
+	GenType string // field type of condition, e.g. String for field.NewString
+	Convert string // conversion of parameter passed to Eq, e.g. string for enum type
+}
+
+// lookupPkgs packages imported by query file, parameter of the same name would shadow them
+var lookupPkgs = map[string]bool{"field": true, "gen": true, "clause": true}
+
+// IndexLookups lookup methods of columns backing a unique single-column index, primary key included.
+// Column backing multiple indexes gets one method, columns of types which filter doesn't support are skipped
+func (b *QueryStructMeta) IndexLookups() (lookups []IndexLookup) {
+	for _, f := range b.ColumnFields() {
+		if f.Column == nil {
+			continue
+		}
+
+		index := ""
+		for _, idx := range f.Column.Indexes {
+			if idx == nil || len(idx.Columns()) != 1 {
+				continue
+			}
+			if unique, _ := idx.Unique(); unique && (index == "" || idx.Name() < index) {
+				index = idx.Name()
+			}
+		}
+		if index == "" {
+			continue
+		}
+
+		typ, convert := strings.TrimPrefix(f.Type, "*"), ""
+		switch {
+		case f.Enum != nil:
+			typ, convert = b.StructInfo.Package+"."+f.Enum.TypeName, "string"
+		case !filterTypes[typ]:
+			continue
+		}
+
+		param := LowerCamel(f.Name)
+		if token.IsKeyword(param) || !token.IsIdentifier(param) || param == b.S || lookupPkgs[param] || param == convert {
+			param = "value"
+		}
+		lookups = append(lookups, IndexLookup{
+			Name:    "WhereBy" + f.Name,
+			Column:  f.ColumnName,
+			Index:   index,
+			Param:   param,
+			Type:    typ,
+			GenType: f.GenType(),
+			Convert: convert,
+		})
+	}
+	return lookups
+}
+
+// LowerCamel lower leading upper case letters of camel case name, e.g. TenantID => tenantID, ID => id, URLPath => urlPath
+func LowerCamel(name string) string {
+	n := 0
+	for n < len(name) && name[n] >= 'A' && name[n] <= 'Z' {
+		n++
+	}
+	if n > 1 && n < len(name) {
+		n-- // keep the first letter of next word, e.g. P of URLPath
+	}
+	return strings.ToLower(name[:n]) + name[n:]
+}
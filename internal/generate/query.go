@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/jinzhu/inflection"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 
@@ -22,6 +25,12 @@ type dummyFieldParser struct{}
 
 func (dummyFieldParser) GetFieldGenType(*schema.Field) string { return "" }
 
+// ModelInterface method returning primary key value generated for model, and interface asserted to be implemented by model
+type ModelInterface struct {
+	Method    string // name of method returning primary key value, e.g. GetID
+	Interface string // qualified interface name, e.g. repo.Identifiable, assertion is omitted when empty
+}
+
 // QueryStructMeta struct info in generated code
 type QueryStructMeta struct {
 	db *gorm.DB
@@ -32,16 +41,50 @@ type QueryStructMeta struct {
 	QueryStructName string // internal query struct name
 	ModelStructName string // origin/model struct name
 	TableName       string // table name in db server
+	TableSchema     string // non-default schema of table, prefixed to generated table name
+	SourceSchema    string // schema table is read from during introspection, empty when dialect has no schema
 	TableComment    string // table comment in db server
 	StructInfo      parser.Param
 	Fields          []*model.Field
+	Columns         []*model.Column // columns introspected from table, kept as is by field options and plugins
 	Source          model.SourceCode
 	ImportPkgPaths  []string
-	ModelMethods    []*parser.Method // user custom method bind to db base struct
+	ModelMethods    []*parser.Method    // user custom method bind to db base struct
+	ForeignKeys     []*model.ForeignKey // foreign keys of table, used to build reverse relations
+
+	CheckConstraints []*model.CheckConstraint // check constraints of table, documented above model struct
 
 	interfaceMode bool
 
 	UseGenericMode bool // use generic mode
+
+	ContextFirstArg bool // add ctx as first argument of DAO methods
+
+	DBResolver string // name of dbresolver resolver query code runs on, e.g. dbresolver.Use("analytics")
+
+	BatchCreateWithAssociations bool // CreateInBatches and BulkInsert save associations of records
+
+	AssertQueryInterface bool // assert DO implements its query interface
+
+	ReadOnly bool // generated from database view, query code excludes create, update and delete methods
+
+	ModelOnly bool // generate model struct only, skipped when applied to generate query code
+
+	SchemaFingerprint string // hash of table schema written into model file header
+
+	TableNameInComment bool // state source schema and table in model doc comment
+
+	WithColumnNames bool // generate variable and constants holding column names of model fields in model file
+
+	NoPrimaryKey bool // table has no primary key column
+
+	ModelInterface ModelInterface // primary key method and interface implemented by model
+
+	OrderByCaseInsensitive bool // match column names of generated <Model>OrderBy case-insensitively
+
+	JSONPathConds []JSONPathCond // condition methods of values extracted from json columns
+
+	WithoutPrimaryKeyMethods bool // query code excludes Save and rejects Delete by models, set when table has no primary key
 }
 
 // parseStruct get all elements of struct with gorm's Parse, ignore unexported elements
@@ -137,6 +180,40 @@ func (b *QueryStructMeta) appendOrUpdateField(f *model.Field) {
 
 func (b *QueryStructMeta) appendField(f *model.Field) { b.Fields = append(b.Fields, f) }
 
+// appendReverseRelation append has-one or has-many field for child's foreign key,
+// field is prefixed by foreign key column name to keep it distinct when child has multiple foreign keys to b
+func (b *QueryStructMeta) appendReverseRelation(db *gorm.DB, child *QueryStructMeta, fk *model.ForeignKey, distinct bool) {
+	relationship, typ := field.HasMany, "[]*"+child.ModelStructName
+	name := inflection.Plural(child.ModelStructName) // e.g. Order => Orders
+	if fk.Unique {
+		relationship, typ, name = field.HasOne, "*"+child.ModelStructName, child.ModelStructName
+	}
+	if distinct {
+		prefix := foreignKeyColumnName(db, fk) // e.g. buyer_id => BuyerOrders
+		if prefix == "" {
+			prefix = db.NamingStrategy.SchemaName(fk.Name)
+		}
+		name = prefix + name
+	}
+	for _, f := range b.Fields {
+		if f.Name == name {
+			db.Logger.Warn(context.Background(), "ignore reverse relation of foreign key %s: field %s.%s already exists", fk.Name, b.ModelStructName, name)
+			return
+		}
+	}
+
+	b.appendField(&model.Field{
+		Name: name,
+		Type: typ,
+		Tag:  (&field.RelateConfig{}).GetTag(name),
+		GORMTag: field.GormTag{
+			field.TagKeyGormForeignKey: []string{strings.Join(fk.Columns, ",")},
+			field.TagKeyGormReferences: []string{strings.Join(fk.ReferencedColumns, ",")},
+		},
+		Relation: field.NewRelationWithType(relationship, name, child.StructInfo.Package+"."+child.ModelStructName),
+	})
+}
+
 // HasField check if BaseStruct has fields
 func (b *QueryStructMeta) HasField() bool { return len(b.Fields) > 0 }
 
@@ -161,6 +238,16 @@ func (b *QueryStructMeta) Relations() (result []field.Relation) {
 	return result
 }
 
+// HasManyRelations has-many relations declared on model, DO has association helpers of them
+func (b *QueryStructMeta) HasManyRelations() (result []field.Relation) {
+	for _, r := range b.Relations() {
+		if r.Relationship() == field.HasMany {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
 // StructComment struct comment
 func (b *QueryStructMeta) StructComment() string {
 	if b.TableComment != "" {
@@ -172,6 +259,195 @@ func (b *QueryStructMeta) StructComment() string {
 	return `mapped from object`
 }
 
+// SourceTable quoted source schema and table, e.g. "public"."users"
+func (b *QueryStructMeta) SourceTable() string {
+	if b.SourceSchema == "" {
+		return strconv.Quote(b.TableName)
+	}
+	return strconv.Quote(b.SourceSchema) + "." + strconv.Quote(b.TableName)
+}
+
+// ColumnFields fields mapped to table columns, relation fields are excluded
+func (b *QueryStructMeta) ColumnFields() (fields []*model.Field) {
+	for _, f := range b.Fields {
+		if !f.IsRelation() && f.ColumnName != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// PrimaryKeyFields fields of primary key columns in column order
+func (b *QueryStructMeta) PrimaryKeyFields() (fields []*model.Field) {
+	for _, f := range b.ColumnFields() {
+		if f.Column == nil {
+			continue
+		}
+		if pk, _ := f.Column.PrimaryKey(); pk {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// OrderByColumn column allowed in generated <Model>OrderBy, Key is lower-cased when matched case-insensitively
+type OrderByColumn struct {
+	Key  string
+	Name string
+}
+
+// OrderByColumns columns allowed in generated <Model>OrderBy, the first column wins when columns differ only in case
+func (b *QueryStructMeta) OrderByColumns() (columns []OrderByColumn) {
+	seen := make(map[string]bool)
+	for _, f := range b.ColumnFields() {
+		key := f.ColumnName
+		if b.OrderByCaseInsensitive {
+			key = strings.ToLower(key)
+		}
+		if !seen[key] {
+			seen[key] = true
+			columns = append(columns, OrderByColumn{Key: key, Name: f.ColumnName})
+		}
+	}
+	return columns
+}
+
+// UpsertConflictColumns default conflict target of generated Upsert, columns of the first unique index by name,
+// or primary key columns when table has no unique index
+func (b *QueryStructMeta) UpsertConflictColumns() []string {
+	uniques := make(map[string][]*model.Index) // index name => index of each column
+	columns := make(map[*model.Index]string)
+	var pks []string
+	for _, f := range b.ColumnFields() {
+		if f.Column == nil {
+			continue
+		}
+		if pk, _ := f.Column.PrimaryKey(); pk {
+			pks = append(pks, f.ColumnName)
+		}
+		for _, idx := range f.Column.Indexes {
+			if idx == nil {
+				continue
+			}
+			if pk, _ := idx.PrimaryKey(); pk {
+				continue
+			}
+			if unique, _ := idx.Unique(); unique {
+				uniques[idx.Name()] = append(uniques[idx.Name()], idx)
+				columns[idx] = f.ColumnName
+			}
+		}
+	}
+	if len(uniques) == 0 {
+		return pks
+	}
+
+	names := make([]string, 0, len(uniques))
+	for name := range uniques {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	indexes := uniques[names[0]]
+	sort.SliceStable(indexes, func(i, j int) bool { return indexes[i].Priority < indexes[j].Priority })
+	result := make([]string, len(indexes))
+	for i, idx := range indexes {
+		result[i] = columns[idx]
+	}
+	return result
+}
+
+// ColumnNamesVar name of variable holding column names, e.g. UserColumns,
+// UserColumnNames when UserColumns is taken by enum type of column columns
+func (b *QueryStructMeta) ColumnNamesVar() string {
+	name := b.ModelStructName + "Columns"
+	for _, f := range b.Fields {
+		if f.Enum != nil && f.Enum.TypeName == name {
+			return b.ModelStructName + "ColumnNames"
+		}
+	}
+	return name
+}
+
+// ColumnConst constant holding column name, e.g. UserColumnCreatedAt = "created_at"
+type ColumnConst struct {
+	Name  string
+	Value string
+}
+
+// ColumnConsts constants of column names named by model and field, each column has one constant,
+// number suffix is added when the name is taken by model, enum or column names variable
+func (b *QueryStructMeta) ColumnConsts() (consts []ColumnConst) {
+	taken := map[string]bool{b.ModelStructName: true}
+	for _, f := range b.Fields {
+		if f.Enum == nil {
+			continue
+		}
+		taken[f.Enum.TypeName] = true
+		for _, v := range f.Enum.Values {
+			taken[v.Name] = true
+		}
+	}
+	if b.WithColumnNames {
+		taken[b.ColumnNamesVar()] = true
+	}
+
+	columns := make(map[string]bool)
+	for _, f := range b.ColumnFields() {
+		if columns[f.ColumnName] {
+			continue
+		}
+		columns[f.ColumnName] = true
+
+		name := b.ModelStructName + "Column" + f.Name
+		for i := 2; taken[name]; i++ {
+			name = b.ModelStructName + "Column" + f.Name + strconv.Itoa(i)
+		}
+		taken[name] = true
+		consts = append(consts, ColumnConst{Name: name, Value: f.ColumnName})
+	}
+	return consts
+}
+
+// ApplyPlugin run model plugin, changes of fields and import paths are kept when it succeeds,
+// nil fields are dropped and field names must be unique
+func (b *QueryStructMeta) ApplyPlugin(apply func(*model.Meta) error) error {
+	meta := &model.Meta{
+		TableName:       b.TableName,
+		ModelStructName: b.ModelStructName,
+		Fields:          append([]*model.Field(nil), b.Fields...),
+		ImportPkgPaths:  append([]string(nil), b.ImportPkgPaths...),
+	}
+	if err := apply(meta); err != nil {
+		return err
+	}
+
+	fields := make([]*model.Field, 0, len(meta.Fields))
+	names := make(map[string]bool, len(meta.Fields))
+	for _, f := range meta.Fields {
+		if f == nil {
+			continue
+		}
+		if f.Embedded {
+			fields = append(fields, f)
+			continue
+		}
+		if f.Name == "" || names[f.Name] {
+			return fmt.Errorf("field name %q of model %s is empty or duplicate", f.Name, b.ModelStructName)
+		}
+		names[f.Name] = true
+		fields = append(fields, f)
+	}
+	paths := make([]string, 0, len(meta.ImportPkgPaths))
+	for _, path := range meta.ImportPkgPaths {
+		if path = strings.TrimSpace(path); path != "" && !strings.HasPrefix(path, `"`) {
+			path = `"` + path + `"`
+		}
+		paths = append(paths, path)
+	}
+	b.Fields, b.ImportPkgPaths = fields, paths
+	return nil
+}
+
 // QueryStructComment query struct comment
 func (b *QueryStructMeta) QueryStructComment() string {
 	if b.TableComment != "" {
@@ -249,6 +525,12 @@ func (b QueryStructMeta) GenericMode(on bool) *QueryStructMeta {
 	return &b
 }
 
+// ContextMode object mode
+func (b QueryStructMeta) ContextMode(on bool) *QueryStructMeta {
+	b.ContextFirstArg = on
+	return &b
+}
+
 // ReturnObject return object in generated code
 func (b *QueryStructMeta) ReturnObject() string {
 	if b.interfaceMode {
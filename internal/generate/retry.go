@@ -0,0 +1,72 @@
+package generate
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+
+	"gorm.io/gen/internal/model"
+)
+
+// retryableMessages messages of connection-level errors which drivers report without typed error
+var retryableMessages = []string{
+	"invalid connection",           // go-sql-driver/mysql
+	"bad connection",               // database/sql
+	"server closed the connection", // postgres
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"i/o timeout",
+	"the database system is starting up",   // postgres during failover
+	"the database system is shutting down", // postgres during failover
+}
+
+// IsRetryable check if err of introspection query is transient, e.g. connection lost during failover.
+// Errors reported by database server such as sql syntax errors and context errors are not retryable
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range retryableMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// withRetry call fn, retryable errors are retried at most conf.IntrospectRetries times, waiting
+// conf.IntrospectBackoff before the first retry and doubling it after each one. Each retry is logged as warning
+func withRetry(db *gorm.DB, conf *model.Config, name string, tableName string, fn func() error) error {
+	ctx := conf.Context
+	backoff := conf.IntrospectBackoff
+	for retry := 1; ; retry++ {
+		err := fn()
+		if err == nil || retry > conf.IntrospectRetries || !IsRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+		db.Logger.Warn(ctx, "%s for %s,err=%s, retry %d/%d in %s", name, tableName, err.Error(), retry, conf.IntrospectRetries, backoff)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
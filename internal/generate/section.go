@@ -120,6 +120,7 @@ func (s *Section) BuildSQL() ([]Clause, error) {
 			res = append(res, forClause)
 			s.appendTmpl(forClause.Finish())
 		case model.END:
+			return nil, fmt.Errorf("unexpected {{end}} at line %d, no clause to close", c.Line)
 		default:
 			return nil, fmt.Errorf("unknow clause:%s", c.Value)
 		}
@@ -134,10 +135,12 @@ func (s *Section) BuildSQL() ([]Clause, error) {
 // parseIF parse if clause
 func (s *Section) parseIF(name string) (res IfClause, err error) {
 	c := s.current()
+	open := c
 	res.slice = c
 
 	s.appendTmpl(res.Create())
 	if !s.HasMore() {
+		err = open.notClosedErr()
 		return
 	}
 	c = s.next()
@@ -205,9 +208,8 @@ func (s *Section) parseIF(name string) (res IfClause, err error) {
 		}
 		c = s.next()
 	}
-	if c.isEnd() {
-		err = fmt.Errorf("incomplete SQL,if not end")
-	}
+	// reach the last section without end
+	err = open.notClosedErr()
 	return
 }
 
@@ -288,11 +290,13 @@ func (s *Section) parseElSE(name string) (res ElseClause, err error) {
 // parseWhere parse where clause, the clause' type must be one of if, SQL condition
 func (s *Section) parseWhere() (res WhereClause, err error) {
 	c := s.current()
+	open := c
 	res.VarName = s.GetName(c.Type)
 	s.appendTmpl(res.Create())
 	res.Type = c.Type
 
 	if !s.HasMore() {
+		err = open.notClosedErr()
 		return
 	}
 	c = s.next()
@@ -345,19 +349,19 @@ func (s *Section) parseWhere() (res WhereClause, err error) {
 		}
 		c = s.next()
 	}
-	if c.isEnd() {
-		return
-	}
-	err = fmt.Errorf("incomplete SQL,where not end")
+	// reach the last section without end
+	err = open.notClosedErr()
 	return
 }
 
 // parseSet parse set clause, the clause' type must be one of if, SQL condition
 func (s *Section) parseSet() (res SetClause, err error) {
 	c := s.current()
+	open := c
 	res.VarName = s.GetName(c.Type)
 	s.appendTmpl(res.Create())
 	if !s.HasMore() {
+		err = open.notClosedErr()
 		return
 	}
 	c = s.next()
@@ -412,18 +416,19 @@ func (s *Section) parseSet() (res SetClause, err error) {
 		}
 		c = s.next()
 	}
-	if c.isEnd() {
-		err = fmt.Errorf("incomplete SQL,set not end")
-	}
+	// reach the last section without end
+	err = open.notClosedErr()
 	return
 }
 
 // parseTrim parse set clause, the clause' type must be one of if, SQL condition
 func (s *Section) parseTrim() (res TrimClause, err error) {
 	c := s.current()
+	open := c
 	res.VarName = s.GetName(c.Type)
 	s.appendTmpl(res.Create())
 	if !s.HasMore() {
+		err = open.notClosedErr()
 		return
 	}
 	c = s.next()
@@ -470,19 +475,20 @@ func (s *Section) parseTrim() (res TrimClause, err error) {
 		}
 		c = s.next()
 	}
-	if c.isEnd() {
-		err = fmt.Errorf("incomplete SQL,set not end")
-	}
+	// reach the last section without end
+	err = open.notClosedErr()
 	return
 }
 
 func (s *Section) parseFor(name string) (res ForClause, err error) {
 	c := s.current()
+	open := c
 	res.forSlice = c
 	s.appendTmpl(res.Create())
 	s.forValue = append(s.forValue, res.forSlice.ForRange)
 
 	if !s.HasMore() {
+		err = open.notClosedErr()
 		return
 	}
 	c = s.next()
@@ -490,7 +496,7 @@ func (s *Section) parseFor(name string) (res ForClause, err error) {
 		switch c.Type {
 		case model.SQL, model.DATA, model.VARIABLE:
 			strClause := s.parseSQL(name)
-			res.Value = append(res.Value, strClause)
+			res.appendClause(strClause)
 			s.appendTmpl(fmt.Sprintf("%s.WriteString(%s)", name, strClause.String()))
 		case model.IF:
 			var ifClause IfClause
@@ -498,7 +504,7 @@ func (s *Section) parseFor(name string) (res ForClause, err error) {
 			if err != nil {
 				return
 			}
-			res.Value = append(res.Value, ifClause)
+			res.appendClause(ifClause)
 			s.appendTmpl(ifClause.Finish())
 		case model.FOR:
 			var forClause ForClause
@@ -506,7 +512,7 @@ func (s *Section) parseFor(name string) (res ForClause, err error) {
 			if err != nil {
 				return
 			}
-			res.Value = append(res.Value, forClause)
+			res.appendClause(forClause)
 			s.appendTmpl(forClause.Finish())
 		case model.TRIM:
 			var trimClause TrimClause
@@ -514,8 +520,16 @@ func (s *Section) parseFor(name string) (res ForClause, err error) {
 			if err != nil {
 				return
 			}
-			res.Value = append(res.Value, trimClause)
+			res.appendClause(trimClause)
 			s.appendTmpl(trimClause.Finish(name))
+		case model.ELSE: // e.g. {{for _,id:=range ids}}id=@id{{else}}1=0{{end}}
+			if strings.TrimSpace(c.Value) != "else" || res.hasEmpty {
+				err = fmt.Errorf("unexpected {{%s}} at line %d, for range only supports one {{else}} for empty range list", c.Value, c.Line)
+				return
+			}
+			res.hasEmpty = true
+			s.forValue[len(s.forValue)-1] = ForRange{} // range variables are out of scope in {{else}}
+			s.appendTmpl(res.CreateEmpty())
 		case model.END:
 			s.forValue = s.forValue[:len(s.forValue)-1]
 			return
@@ -528,9 +542,8 @@ func (s *Section) parseFor(name string) (res ForClause, err error) {
 		}
 		c = s.next()
 	}
-	if c.isEnd() {
-		err = fmt.Errorf("incomplete SQL,set not end")
-	}
+	// reach the last section without end
+	err = open.notClosedErr()
 	return
 }
 
@@ -568,6 +581,9 @@ func (s *Section) checkSQLVar(param string, status model.Status, method *Interfa
 		}
 		return
 	}
+	if !s.isDeclared(param, method) {
+		return result, fmt.Errorf("unknown variable param: %s, it matches no parameter of method %s or for range variable", param, method.MethodName)
+	}
 	if status == model.DATA {
 		method.HasForParams = true
 	}
@@ -581,6 +597,41 @@ func (s *Section) checkSQLVar(param string, status model.Status, method *Interfa
 	return
 }
 
+// isDeclared check if root name of param is a method parameter or a variable of for range enclosing it,
+// e.g. user of @user.Name, range variables are out of scope in {{else}} and after {{end}} of for
+func (s *Section) isDeclared(param string, method *InterfaceMethod) bool {
+	name := param
+	if i := strings.IndexAny(name, ".["); i != -1 {
+		name = name[:i]
+	}
+	for _, p := range method.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+	var scopes []ForRange // open clauses before param, ForRange is empty unless clause is for range
+	for _, m := range s.members {
+		switch m.Type {
+		case model.IF, model.WHERE, model.SET, model.TRIM, model.FOR:
+			scopes = append(scopes, m.ForRange)
+		case model.ELSE:
+			if len(scopes) > 0 {
+				scopes[len(scopes)-1] = ForRange{}
+			}
+		case model.END:
+			if len(scopes) > 0 {
+				scopes = scopes[:len(scopes)-1]
+			}
+		}
+	}
+	for _, r := range scopes {
+		if r.value == name || r.index == name {
+			return true
+		}
+	}
+	return false
+}
+
 // GetName ...
 func (s *Section) GetName(status model.Status) string {
 	switch status {
@@ -612,6 +663,7 @@ func (s *Section) checkTemplate(tmpl string) (part section, err error) {
 type section struct {
 	Type      model.Status
 	Value     string
+	Line      int // line of template in sql, starts from 1
 	ForRange  ForRange
 	SQLSlice  *Section
 	splitList []string
@@ -621,6 +673,11 @@ func (s *section) isEnd() bool {
 	return s.Type == model.END
 }
 
+// notClosedErr error of template clause without end
+func (s *section) notClosedErr() error {
+	return fmt.Errorf("incomplete SQL, {{%s}} at line %d is not closed by {{end}}", s.Value, s.Line)
+}
+
 func (s *section) String() string {
 	if s.Type == model.FOR {
 		return s.ForRange.String()
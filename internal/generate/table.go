@@ -2,22 +2,61 @@ package generate
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/utils"
+	"gorm.io/gorm/utils/tests"
 
 	"gorm.io/gen/internal/model"
 )
 
 // ITableInfo table info interface
-type ITableInfo interface {
-	GetTableColumns(schemaName string, tableName string) (result []*model.Column, err error)
+type ITableInfo = model.ITableInfo
 
-	GetTableIndex(schemaName string, tableName string) (indexes []gorm.Index, err error)
+func getTableInfo(ctx context.Context, db *gorm.DB) ITableInfo {
+	return &tableInfo{db.WithContext(ctx)}
 }
 
-func getTableInfo(db *gorm.DB) ITableInfo {
-	return &tableInfo{db}
+// hasConn check if db is connected to a real database server
+func hasConn(db *gorm.DB) bool {
+	if db == nil {
+		return false
+	}
+	_, dummy := db.Dialector.(tests.DummyDialector)
+	return !dummy
+}
+
+// isDefaultSchema check if schema is empty or the default one, e.g. public, dbo or current mysql database
+func isDefaultSchema(db *gorm.DB, schemaName string) bool {
+	switch strings.ToLower(schemaName) {
+	case "", "public", "dbo":
+		return true
+	}
+	return hasConn(db) && schemaName == db.Migrator().CurrentDatabase()
+}
+
+// getSourceSchema schema table is read from, default schema of dialect or current database when schemaName is empty
+func getSourceSchema(db *gorm.DB, schemaName string) string {
+	if schemaName != "" {
+		return schemaName
+	}
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "public"
+	case "sqlserver":
+		return "dbo"
+	case "sqlite":
+		return ""
+	}
+	if hasConn(db) {
+		return db.Migrator().CurrentDatabase()
+	}
+	return ""
 }
 
 func getTableComment(db *gorm.DB, tableName string) string {
@@ -38,44 +77,355 @@ func getTableType(db *gorm.DB, tableName string) (result gorm.TableType, err err
 	return db.Migrator().TableType(tableName)
 }
 
-func getTableColumns(db *gorm.DB, schemaName string, tableName string, indexTag bool) (result []*model.Column, err error) {
+// getTableColumns get table columns with index info from conf.TableInfo, or from db when it is nil
+// index column sequences are read from db only, it is skipped when no database connection is available
+// introspection is aborted with context error once conf.Context is done
+func getTableColumns(db *gorm.DB, conf *model.Config, schemaName string, tableName string) (result []*model.Column, err error) {
 	if db == nil {
 		return nil, errors.New("gorm db is nil")
 	}
+	ctx := conf.Context
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	mt := getTableInfo(db)
-	result, err = mt.GetTableColumns(schemaName, tableName)
+	mt := conf.TableInfo
+	if mt == nil {
+		mt = getTableInfo(ctx, db)
+	}
+	err = withRetry(db, conf, "GetTableColumns", tableName, func() (err error) {
+		result, err = mt.GetTableColumns(schemaName, tableName)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if !indexTag || len(result) == 0 {
+	if hasConn(db) && (conf.TableInfo == nil || conf.FieldSignable || conf.FieldDetectGeneratedColumns) {
+		attrs, ok := conf.ColumnAttrCache.Get(schemaName, tableName)
+		if !ok {
+			var tables map[string]map[string]model.ColumnAttr
+			err = withRetry(db, conf, "GetColumnAttrs", tableName, func() (err error) {
+				tables, err = getColumnAttrs(ctx, db, schemaName, []string{tableName}, conf.FieldDetectGeneratedColumns)
+				return err
+			})
+			if err != nil { // ignore find column attributes err
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				db.Logger.Warn(ctx, "GetColumnAttrs for %s,err=%s", tableName, err.Error())
+			}
+			attrs = tables[tableName]
+		}
+		setColumnAttrs(conf, result, attrs)
+	}
+	sortColumnsByOrdinal(result)
+	if conf.FieldWithEnumTypes {
+		for _, c := range result {
+			if ct, ok := c.ColumnType.ColumnType(); ok {
+				c.EnumValues, _ = model.ParseEnumValues(ct)
+			}
+		}
+	}
+	if conf.View { // views have neither indexes nor primary keys
+		for _, c := range result {
+			c.View = true
+		}
+		return result, nil
+	}
+
+	// without index tag and index lookups, indexes are read for priorities of composite primary key only
+	pkOnly := !conf.FieldWithIndexTag && !conf.FieldWithIndexLookups
+	if len(result) == 0 || (pkOnly && countPrimaryKeys(result) < 2) {
 		return result, nil
 	}
 
-	index, err := mt.GetTableIndex(schemaName, tableName)
+	var index []gorm.Index
+	err = withRetry(db, conf, "GetTableIndex", tableName, func() (err error) {
+		index, err = mt.GetTableIndex(schemaName, tableName)
+		return err
+	})
 	if err != nil { //ignore find index err
-		db.Logger.Warn(context.Background(), "GetTableIndex for %s,err=%s", tableName, err.Error())
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		db.Logger.Warn(ctx, "GetTableIndex for %s,err=%s", tableName, err.Error())
 		return result, nil
 	}
 	if len(index) == 0 {
 		return result, nil
 	}
 
-	// Get index column sequences from database metadata
-	indexColumnSeq, err := getIndexColumnSequences(db, schemaName, tableName)
-	if err != nil {
-		db.Logger.Warn(context.Background(), "GetIndexColumnSequences for %s,err=%s", tableName, err.Error())
-		// Fall back to original behavior if query fails
-		indexColumnSeq = make(map[string]map[string]int32)
+	// Get index column sequences from cache or database metadata
+	indexColumns, ok := conf.IndexColumnCache.Get(schemaName, tableName)
+	if !ok && hasConn(db) {
+		err = withRetry(db, conf, "GetIndexColumnSequences", tableName, func() (err error) {
+			indexColumns, err = getIndexColumnSequences(ctx, db, schemaName, tableName)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			db.Logger.Warn(ctx, "GetIndexColumnSequences for %s,err=%s", tableName, err.Error())
+			// Fall back to original behavior if query fails
+			indexColumns = nil
+		}
+	}
+	if !conf.FieldWithIndexSort { // sort and collation only emitted when enabled
+		indexColumns = withoutIndexSort(indexColumns)
 	}
 
-	im := model.GroupByColumnWithSequences(index, indexColumnSeq)
+	im := model.GroupByColumnWithSequences(index, indexColumns)
 	for _, c := range result {
 		c.Indexes = im[c.Name()]
+		if pkOnly {
+			c.Indexes = primaryKeyIndexes(c.Indexes)
+		}
 	}
+	model.SetPrimaryKeyPriority(result)
 	return result, nil
 }
 
+// countPrimaryKeys count primary key columns
+func countPrimaryKeys(columns []*model.Column) (count int) {
+	for _, c := range columns {
+		if pk, ok := c.PrimaryKey(); ok && pk {
+			count++
+		}
+	}
+	return count
+}
+
+// primaryKeyIndexes filter primary key indexes, e.g. PRIMARY of mysql or pkey constraint of postgres
+func primaryKeyIndexes(indexes []*model.Index) (result []*model.Index) {
+	for _, idx := range indexes {
+		if idx == nil {
+			continue
+		}
+		if pk, _ := idx.PrimaryKey(); pk {
+			result = append(result, idx)
+		}
+	}
+	return result
+}
+
+// setColumnAttrs set ordinal, unsigned and generated attributes of columns,
+// columns of custom table info carry their own ordinal
+func setColumnAttrs(conf *model.Config, columns []*model.Column, attrs map[string]model.ColumnAttr) {
+	for _, c := range columns {
+		attr, ok := attrs[c.Name()]
+		if !ok {
+			continue
+		}
+		if conf.TableInfo == nil {
+			c.Ordinal = attr.Ordinal
+		}
+		c.Unsigned = c.Unsigned || (conf.FieldSignable && attr.Unsigned)
+		c.Generated = c.Generated || (conf.FieldDetectGeneratedColumns && attr.Generated)
+	}
+}
+
+// sortColumnsByOrdinal sort columns by ordinal position, order of migrator is kept when any ordinal is unknown
+func sortColumnsByOrdinal(columns []*model.Column) {
+	for _, c := range columns {
+		if c.Ordinal <= 0 {
+			return
+		}
+	}
+	sort.SliceStable(columns, func(i, j int) bool { return columns[i].Ordinal < columns[j].Ordinal })
+}
+
+// getColumnAttrs query column attributes of tables in one query, keyed by table and column name. ColumnTypes of some
+// drivers don't follow ordinal position, nor contain unsigned keyword. Generated columns are reported by
+// information_schema.COLUMNS.EXTRA of mysql, DEFAULT_GENERATED of default expressions is not one of them,
+// and pg_attribute.attgenerated of postgres 12+, it is queried only when generated is true.
+// Empty schemaName is the current database of mysql and current schema of postgres and sqlserver
+func getColumnAttrs(ctx context.Context, db *gorm.DB, schemaName string, tableNames []string, generated bool) (map[string]map[string]model.ColumnAttr, error) {
+	db = db.WithContext(ctx)
+
+	type columnAttrRow struct {
+		TableName   string
+		ColumnName  string
+		Ordinal     int
+		IsUnsigned  bool
+		IsGenerated bool
+	}
+	var rows []columnAttrRow
+	switch db.Dialector.Name() {
+	case "mysql":
+		err := db.Raw(`
+			SELECT TABLE_NAME AS table_name, COLUMN_NAME AS column_name, ORDINAL_POSITION AS ordinal,
+				COLUMN_TYPE LIKE '%unsigned%' AS is_unsigned,
+				(EXTRA LIKE '%STORED GENERATED%' OR EXTRA LIKE '%VIRTUAL GENERATED%') AS is_generated
+			FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME IN ?`, schemaName, tableNames).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	case "postgres":
+		isGenerated := "false"
+		if generated {
+			isGenerated = "a.attgenerated <> ''"
+		}
+		// attnum follows ordinal position, dropped columns leave gaps only
+		err := db.Raw(`
+			SELECT t.relname AS table_name, a.attname AS column_name, a.attnum AS ordinal,
+				false AS is_unsigned, `+isGenerated+` AS is_generated
+			FROM pg_attribute a
+			JOIN pg_class t ON t.oid = a.attrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			WHERE n.nspname = COALESCE(NULLIF(?, ''), current_schema()) AND t.relname IN ? AND a.attnum > 0 AND NOT a.attisdropped`,
+			schemaName, tableNames).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	case "sqlserver":
+		err := db.Raw(`
+			SELECT table_name, column_name, ordinal_position AS ordinal, 0 AS is_unsigned, 0 AS is_generated
+			FROM information_schema.columns
+			WHERE table_schema = COALESCE(NULLIF(?, ''), SCHEMA_NAME()) AND table_name IN ?`, schemaName, tableNames).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	case "sqlite": // pragma of local database, no round trip to server
+		for _, tableName := range tableNames {
+			var tableRows []columnAttrRow
+			err := db.Raw(`
+				SELECT ? AS table_name, name AS column_name, cid + 1 AS ordinal, 0 AS is_unsigned, 0 AS is_generated
+				FROM pragma_table_info(?)`, tableName, tableName).Scan(&tableRows).Error
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, tableRows...)
+		}
+	default:
+		return nil, nil
+	}
+
+	tables := make(map[string]map[string]model.ColumnAttr, len(tableNames))
+	for _, r := range rows {
+		if tables[r.TableName] == nil {
+			tables[r.TableName] = make(map[string]model.ColumnAttr)
+		}
+		tables[r.TableName][r.ColumnName] = model.ColumnAttr{Ordinal: r.Ordinal, Unsigned: r.IsUnsigned, Generated: r.IsGenerated}
+	}
+	return tables, nil
+}
+
+// getTableForeignKeys get table foreign keys from conf.TableInfo when it provides them, or from db when it is nil
+// foreign keys backed by a unique index are marked when reverse relations are enabled
+func getTableForeignKeys(db *gorm.DB, conf *model.Config, schemaName string, tableName string) ([]*model.ForeignKey, error) {
+	mt := conf.TableInfo
+	if mt == nil {
+		if !hasConn(db) {
+			return nil, nil
+		}
+		mt = getTableInfo(conf.Context, db)
+	}
+	fkInfo, ok := mt.(model.IForeignKeyInfo)
+	if !ok {
+		return nil, nil
+	}
+	foreignKeys, err := fkInfo.GetTableForeignKeys(schemaName, tableName)
+	if err != nil || len(foreignKeys) == 0 || !conf.FieldWithReverseRelations {
+		return foreignKeys, err
+	}
+
+	indexes, err := mt.GetTableIndex(schemaName, tableName)
+	if err != nil {
+		return foreignKeys, err
+	}
+	for _, fk := range foreignKeys {
+		fk.Unique = hasUniqueIndex(indexes, fk.Columns)
+	}
+	return foreignKeys, nil
+}
+
+// getTableCheckConstraints get table check constraints from conf.TableInfo when it provides them, or from db when it is nil
+func getTableCheckConstraints(db *gorm.DB, conf *model.Config, schemaName string, tableName string) ([]*model.CheckConstraint, error) {
+	var mt model.ICheckConstraintInfo
+	switch {
+	case conf.TableInfo != nil:
+		mt, _ = conf.TableInfo.(model.ICheckConstraintInfo)
+	case hasConn(db):
+		mt = &tableInfo{db.WithContext(conf.Context)}
+	}
+	if mt == nil {
+		return nil, nil
+	}
+	return mt.GetTableCheckConstraints(schemaName, tableName)
+}
+
+// hasUniqueIndex check if there is a unique index or primary key exactly on columns
+func hasUniqueIndex(indexes []gorm.Index, columns []string) bool {
+	for _, idx := range indexes {
+		if idx == nil {
+			continue
+		}
+		unique, _ := idx.Unique()
+		pk, _ := idx.PrimaryKey()
+		if (!unique && !pk) || len(idx.Columns()) != len(columns) {
+			continue
+		}
+		matched := true
+		for _, col := range idx.Columns() {
+			if !utils.Contains(columns, col) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutIndexSort copy index columns metadata without sort and collation
+func withoutIndexSort(indexColumns map[string]map[string]model.IndexColumn) map[string]map[string]model.IndexColumn {
+	result := make(map[string]map[string]model.IndexColumn, len(indexColumns))
+	for indexName, columns := range indexColumns {
+		result[indexName] = make(map[string]model.IndexColumn, len(columns))
+		for name, col := range columns {
+			col.Sort, col.Collation = "", ""
+			result[indexName][name] = col
+		}
+	}
+	return result
+}
+
+// PrefetchIndexColumns query index column sequences of tables in batch and save them to cache,
+// it is skipped when no database connection is available
+func PrefetchIndexColumns(ctx context.Context, db *gorm.DB, schemaName string, tableNames []string, cache *model.IndexColumnCache) error {
+	if cache == nil || len(tableNames) == 0 || !hasConn(db) {
+		return nil
+	}
+	tables, err := getIndexColumnSequencesBatch(ctx, db, schemaName, tableNames)
+	if err != nil {
+		return err
+	}
+	for _, tableName := range tableNames {
+		cache.Set(schemaName, tableName, tables[tableName])
+	}
+	return nil
+}
+
+// PrefetchColumnAttrs query column attributes of tables in one query per schema and save them to cache,
+// it is skipped when no database connection is available
+func PrefetchColumnAttrs(ctx context.Context, db *gorm.DB, schemaName string, tableNames []string, generated bool, cache *model.ColumnAttrCache) error {
+	if cache == nil || len(tableNames) == 0 || !hasConn(db) {
+		return nil
+	}
+	tables, err := getColumnAttrs(ctx, db, schemaName, tableNames, generated)
+	if err != nil {
+		return err
+	}
+	for _, tableName := range tableNames {
+		cache.Set(schemaName, tableName, tables[tableName])
+	}
+	return nil
+}
+
 type tableInfo struct{ *gorm.DB }
 
 // GetTableColumns  struct
@@ -85,7 +435,7 @@ func (t *tableInfo) GetTableColumns(schemaName string, tableName string) (result
 		return nil, err
 	}
 	for _, column := range types {
-		result = append(result, &model.Column{ColumnType: column, TableName: tableName, UseScanType: t.Dialector.Name() != "mysql" && t.Dialector.Name() != "sqlite"})
+		result = append(result, &model.Column{ColumnType: column, TableName: tableName, UseScanType: useScanType(t.Dialector.Name())})
 	}
 	return result, nil
 }
@@ -95,40 +445,208 @@ func (t *tableInfo) GetTableIndex(schemaName string, tableName string) (indexes
 	return t.Migrator().GetIndexes(tableName)
 }
 
-// getIndexColumnSequences queries the database to get the correct column order for each index
-// Returns a map: indexName -> columnName -> sequence (1-based)
-func getIndexColumnSequences(db *gorm.DB, schemaName string, tableName string) (map[string]map[string]int32, error) {
+// GetTableForeignKeys foreign keys
+func (t *tableInfo) GetTableForeignKeys(schemaName string, tableName string) (foreignKeys []*model.ForeignKey, err error) {
+	var rows *gorm.DB
+	switch t.Dialector.Name() {
+	case "postgres":
+		pgSchema := schemaName
+		if pgSchema == "" {
+			pgSchema = "public" // Default PostgreSQL schema
+		}
+		// conkey and confkey hold constrained and referenced columns in the same order
+		query := `
+			SELECT
+				c.conname AS constraint_name,
+				a.attname AS column_name,
+				rn.nspname AS referenced_schema,
+				rt.relname AS referenced_table,
+				ra.attname AS referenced_column
+			FROM pg_constraint c
+			JOIN pg_class t ON t.oid = c.conrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			JOIN pg_class rt ON rt.oid = c.confrelid
+			JOIN pg_namespace rn ON rn.oid = rt.relnamespace
+			JOIN LATERAL generate_subscripts(c.conkey, 1) AS pos ON true
+			JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[pos]
+			JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = c.confkey[pos]
+			WHERE c.contype = 'f' AND n.nspname = ? AND t.relname = ?
+			ORDER BY c.conname, pos`
+		rows = t.Raw(query, pgSchema, tableName)
+	case "mysql":
+		query := `
+			SELECT CONSTRAINT_NAME AS constraint_name, COLUMN_NAME AS column_name,
+				REFERENCED_TABLE_SCHEMA AS referenced_schema, REFERENCED_TABLE_NAME AS referenced_table,
+				REFERENCED_COLUMN_NAME AS referenced_column
+			FROM information_schema.KEY_COLUMN_USAGE
+			WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL
+			ORDER BY CONSTRAINT_NAME, ORDINAL_POSITION`
+		rows = t.Raw(query, schemaName, tableName)
+	case "sqlserver":
+		query := `
+			SELECT
+				fk.name AS constraint_name,
+				pc.name AS column_name,
+				rs.name AS referenced_schema,
+				rt.name AS referenced_table,
+				rc.name AS referenced_column
+			FROM sys.foreign_keys fk
+			JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+			JOIN sys.tables t ON t.object_id = fk.parent_object_id
+			JOIN sys.schemas s ON s.schema_id = t.schema_id
+			JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+			JOIN sys.tables rt ON rt.object_id = fk.referenced_object_id
+			JOIN sys.schemas rs ON rs.schema_id = rt.schema_id
+			JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+			WHERE s.name = COALESCE(NULLIF(?, ''), SCHEMA_NAME()) AND t.name = ?
+			ORDER BY fk.name, fkc.constraint_column_id`
+		rows = t.Raw(query, schemaName, tableName)
+	default:
+		return nil, nil
+	}
+
+	sqlRows, err := rows.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer sqlRows.Close()
+
+	fkMap := make(map[string]*model.ForeignKey)
+	for sqlRows.Next() {
+		var name, column, refSchema, refTable, refColumn string
+		if err := sqlRows.Scan(&name, &column, &refSchema, &refTable, &refColumn); err != nil {
+			return nil, err
+		}
+		fk, ok := fkMap[name]
+		if !ok {
+			fk = &model.ForeignKey{Name: name, ReferencedSchema: refSchema, ReferencedTable: refTable}
+			fkMap[name] = fk
+			foreignKeys = append(foreignKeys, fk)
+		}
+		fk.Columns = append(fk.Columns, column)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, refColumn)
+	}
+	return foreignKeys, sqlRows.Err()
+}
+
+// GetTableCheckConstraints check constraints, only mysql 8 and postgres are supported
+func (t *tableInfo) GetTableCheckConstraints(schemaName string, tableName string) (checks []*model.CheckConstraint, err error) {
+	switch t.Dialector.Name() {
+	case "postgres":
+		pgSchema := schemaName
+		if pgSchema == "" {
+			pgSchema = "public" // Default PostgreSQL schema
+		}
+		query := `
+			SELECT c.conname AS name, pg_get_expr(c.conbin, c.conrelid) AS expression
+			FROM pg_constraint c
+			JOIN pg_class t ON t.oid = c.conrelid
+			JOIN pg_namespace n ON n.oid = t.relnamespace
+			WHERE c.contype = 'c' AND n.nspname = ? AND t.relname = ?
+			ORDER BY c.conname`
+		err = t.Raw(query, pgSchema, tableName).Scan(&checks).Error
+	case "mysql":
+		// CHECK_CONSTRAINTS is available since mysql 8.0.16
+		query := `
+			SELECT cc.CONSTRAINT_NAME AS name, cc.CHECK_CLAUSE AS expression
+			FROM information_schema.CHECK_CONSTRAINTS cc
+			JOIN information_schema.TABLE_CONSTRAINTS tc
+				ON tc.CONSTRAINT_SCHEMA = cc.CONSTRAINT_SCHEMA AND tc.CONSTRAINT_NAME = cc.CONSTRAINT_NAME
+			WHERE tc.CONSTRAINT_TYPE = 'CHECK' AND tc.TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND tc.TABLE_NAME = ?
+			ORDER BY cc.CONSTRAINT_NAME`
+		err = t.Raw(query, schemaName, tableName).Scan(&checks).Error
+	}
+	return checks, err
+}
+
+// useScanType built-in default of resolving go type from driver scan type, mysql and sqlite are mapped by database type
+func useScanType(dialect string) bool {
+	return dialect != "mysql" && dialect != "sqlite"
+}
+
+// indexSeqBatchSize max table count in one index column sequences query
+const indexSeqBatchSize = 500
+
+// getIndexColumnSequences queries the database to get the correct column order, sort direction
+// and collation for each index
+// Returns a map: indexName -> columnName -> index column metadata
+func getIndexColumnSequences(ctx context.Context, db *gorm.DB, schemaName string, tableName string) (map[string]map[string]model.IndexColumn, error) {
+	tables, err := getIndexColumnSequencesBatch(ctx, db, schemaName, []string{tableName})
+	if err != nil {
+		return nil, err
+	}
+	if indexColumns, ok := tables[tableName]; ok {
+		return indexColumns, nil
+	}
+	return make(map[string]map[string]model.IndexColumn), nil
+}
+
+// getIndexColumnSequencesBatch queries index column metadata of tables in schema, at most
+// indexSeqBatchSize tables in one query
+// Returns a map: tableName -> indexName -> columnName -> index column metadata
+func getIndexColumnSequencesBatch(ctx context.Context, db *gorm.DB, schemaName string, tableNames []string) (map[string]map[string]map[string]model.IndexColumn, error) {
+	tables := make(map[string]map[string]map[string]model.IndexColumn, len(tableNames))
+	for start := 0; start < len(tableNames); start += indexSeqBatchSize {
+		end := start + indexSeqBatchSize
+		if end > len(tableNames) {
+			end = len(tableNames)
+		}
+		if err := queryIndexColumnSequences(ctx, db, schemaName, tableNames[start:end], tables); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// queryIndexColumnSequences queries index column metadata of tables and fill them into tables
+// rows are closed when ctx is done while scanning
+func queryIndexColumnSequences(ctx context.Context, db *gorm.DB, schemaName string, tableNames []string, tables map[string]map[string]map[string]model.IndexColumn) error {
 	dialector := db.Dialector.Name()
-	indexColumnSeq := make(map[string]map[string]int32)
+	db = db.WithContext(ctx)
 
 	var rows *gorm.DB
-	var err error
+	var withoutVisible func() *gorm.DB // query index columns when IS_VISIBLE is unsupported
 
 	switch dialector {
 	case "postgres":
 		// PostgreSQL query to get index column sequences
 		// Use generate_subscripts to get the position of each column in the indkey array
 		// Note: pg_index.indkey is 0-indexed, so we add 1 to get 1-based priority
+		// Bit 0 of pg_index.indoption is set for DESC columns, collation is only
+		// reported when it differs from the column's own collation
 		pgSchema := schemaName
 		if pgSchema == "" {
 			pgSchema = "public" // Default PostgreSQL schema
 		}
+		if serverFlavor(db).Flavor == flavorCockroachDB {
+			return queryCockroachIndexColumnSequences(db, pgSchema, tableNames, tables)
+		}
 		query := `
 			SELECT 
+				t.relname AS table_name,
 				i.relname AS index_name,
 				a.attname AS column_name,
-				(pos + 1) AS seq_in_index
+				(pos + 1) AS seq_in_index,
+				CASE WHEN (ix.indoption[pos] & 1) = 1 THEN 'DESC' ELSE 'ASC' END AS sort,
+				COALESCE(coll.collname, '') AS collation,
+				1 AS visible,
+				0 AS length
 			FROM pg_index ix
 			JOIN pg_class i ON i.oid = ix.indexrelid
 			JOIN pg_class t ON t.oid = ix.indrelid
 			JOIN pg_namespace n ON n.oid = t.relnamespace
 			JOIN LATERAL generate_subscripts(ix.indkey, 1) AS pos ON true
 			JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ix.indkey[pos]
-			WHERE n.nspname = ? AND t.relname = ?
-			ORDER BY i.relname, pos`
-		rows = db.Raw(query, pgSchema, tableName)
+			LEFT JOIN pg_collation coll ON coll.oid = ix.indcollation[pos] AND ix.indcollation[pos] <> a.attcollation
+			WHERE n.nspname = ? AND t.relname IN ?
+			ORDER BY t.relname, i.relname, pos`
+		rows = db.Raw(query, pgSchema, tableNames)
 	case "mysql":
 		// MySQL query to get index column sequences
+		// STATISTICS.COLLATION holds the sort direction: A (ascending), D (descending) or NULL
+		// STATISTICS.IS_VISIBLE is only available since mysql 8, MariaDB reports ignored indexes by STATISTICS.IGNORED
+		// since 10.6 instead, indexes are visible when both are missing
+		// Key parts of functional indexes have no COLUMN_NAME, STATISTICS.SUB_PART holds prefix length of string columns
 		// If schemaName is empty, use the current database
 		mysqlSchema := schemaName
 		if mysqlSchema == "" {
@@ -137,52 +655,205 @@ func getIndexColumnSequences(db *gorm.DB, schemaName string, tableName string) (
 			mysqlSchema = currentDB
 		}
 		query := `
-			SELECT INDEX_NAME AS index_name, COLUMN_NAME AS column_name, SEQ_IN_INDEX AS seq_in_index
+			SELECT TABLE_NAME AS table_name, INDEX_NAME AS index_name, COLUMN_NAME AS column_name, SEQ_IN_INDEX AS seq_in_index,
+				CASE COLLATION WHEN 'D' THEN 'DESC' WHEN 'A' THEN 'ASC' ELSE '' END AS sort,
+				'' AS collation,
+				%s AS visible,
+				COALESCE(SUB_PART, 0) AS length
 			FROM information_schema.STATISTICS
-			WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
-			ORDER BY INDEX_NAME, SEQ_IN_INDEX`
-		rows = db.Raw(query, mysqlSchema, tableName)
+			WHERE TABLE_SCHEMA = ? AND TABLE_NAME IN ? AND COLUMN_NAME IS NOT NULL
+			ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`
+		rows = db.Raw(fmt.Sprintf(query, "CASE IS_VISIBLE WHEN 'NO' THEN 0 ELSE 1 END"), mysqlSchema, tableNames)
+		withoutVisible = func() *gorm.DB {
+			visible := "1"
+			if s := serverFlavor(db); s.Flavor == flavorMariaDB && CompareVersion(s.Version, minMariaDBIgnoredVersion) >= 0 {
+				visible = "CASE IGNORED WHEN 'YES' THEN 0 ELSE 1 END"
+			}
+			return db.Raw(fmt.Sprintf(query, visible), mysqlSchema, tableNames)
+		}
 	case "sqlserver":
 		// SQL Server query to get index column sequences
 		query := `
 			SELECT 
+				t.name AS table_name,
 				i.name AS index_name,
 				c.name AS column_name,
-				ic.key_ordinal AS seq_in_index
+				ic.key_ordinal AS seq_in_index,
+				CASE WHEN ic.is_descending_key = 1 THEN 'DESC' ELSE 'ASC' END AS sort,
+				'' AS collation,
+				1 AS visible,
+				0 AS length
 			FROM sys.indexes i
 			JOIN sys.index_columns ic ON i.object_id = ic.object_id AND i.index_id = ic.index_id
 			JOIN sys.columns c ON ic.object_id = c.object_id AND ic.column_id = c.column_id
 			JOIN sys.tables t ON i.object_id = t.object_id
 			JOIN sys.schemas s ON t.schema_id = s.schema_id
-			WHERE s.name = ? AND t.name = ?
-			ORDER BY i.name, ic.key_ordinal`
-		rows = db.Raw(query, schemaName, tableName)
+			WHERE s.name = ? AND t.name IN ?
+			ORDER BY t.name, i.name, ic.key_ordinal`
+		rows = db.Raw(query, schemaName, tableNames)
+	case "sqlite":
+		// SQLite has no schema of index metadata, schemaName is ignored
+		return querySQLiteIndexColumnSequences(db, tableNames, tables)
 	default:
-		// For other databases, return empty map (fallback to original behavior)
-		return indexColumnSeq, nil
+		// For other databases, return nothing (fallback to original behavior)
+		return nil
 	}
 
 	sqlRows, err := rows.Rows()
+	if err != nil && withoutVisible != nil && ctx.Err() == nil {
+		sqlRows, err = withoutVisible().Rows()
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer sqlRows.Close()
 
 	for sqlRows.Next() {
-		var indexName, columnName string
-		var seqInIndex int32
-		if err := sqlRows.Scan(&indexName, &columnName, &seqInIndex); err != nil {
-			return nil, err
+		var tableName, indexName, columnName string
+		var col model.IndexColumn
+		var visible int64
+		if err := sqlRows.Scan(&tableName, &indexName, &columnName, &col.Sequence, &col.Sort, &col.Collation, &visible, &col.Length); err != nil {
+			return err
+		}
+		col.Invisible = visible == 0
+		if tables[tableName] == nil {
+			tables[tableName] = make(map[string]map[string]model.IndexColumn)
 		}
-		if indexColumnSeq[indexName] == nil {
-			indexColumnSeq[indexName] = make(map[string]int32)
+		if tables[tableName][indexName] == nil {
+			tables[tableName][indexName] = make(map[string]model.IndexColumn)
 		}
-		indexColumnSeq[indexName][columnName] = seqInIndex
+		tables[tableName][indexName][columnName] = col
 	}
-
 	if err := sqlRows.Err(); err != nil {
-		return nil, err
+		return err
 	}
 
-	return indexColumnSeq, nil
+	if dialector == "mysql" && hasPrimaryIndex(tables) && serverFlavor(db).Flavor == flavorTiDB {
+		normalizePrimarySequences(tables)
+	}
+	return nil
+}
+
+// cockroachIndexColumn row of information_schema.STATISTICS of CockroachDB
+type cockroachIndexColumn struct {
+	TableName  string `gorm:"column:table_name"`
+	IndexName  string `gorm:"column:index_name"`
+	ColumnName string `gorm:"column:column_name"`
+	Direction  string `gorm:"column:direction"` // ASC, DESC or N/A of storing columns
+	Storing    string `gorm:"column:storing"`   // YES for included columns of STORING clause
+	Implicit   string `gorm:"column:implicit"`  // YES for columns added by CockroachDB, e.g. shard column of hash-sharded index
+}
+
+// queryCockroachIndexColumnSequences queries index columns of tables from information_schema.STATISTICS of CockroachDB,
+// the pg_index query of postgres misses storing columns and hash-sharded indexes there.
+// Storing and implicit columns are not key columns declared by index, they are skipped and the others are numbered from 1
+func queryCockroachIndexColumnSequences(db *gorm.DB, schemaName string, tableNames []string, tables map[string]map[string]map[string]model.IndexColumn) error {
+	var columns []cockroachIndexColumn
+	err := db.Raw(`
+		SELECT table_name, index_name, column_name, direction, storing, implicit
+		FROM information_schema.STATISTICS
+		WHERE table_schema = ? AND table_name IN ?
+		ORDER BY table_name, index_name, seq_in_index`, schemaName, tableNames).Scan(&columns).Error
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if column.Storing == "YES" || column.Implicit == "YES" {
+			continue
+		}
+		if tables[column.TableName] == nil {
+			tables[column.TableName] = make(map[string]map[string]model.IndexColumn)
+		}
+		indexColumns := tables[column.TableName][column.IndexName]
+		if indexColumns == nil {
+			indexColumns = make(map[string]model.IndexColumn)
+			tables[column.TableName][column.IndexName] = indexColumns
+		}
+		col := model.IndexColumn{Sequence: int32(len(indexColumns) + 1)}
+		if column.Direction == "ASC" || column.Direction == "DESC" {
+			col.Sort = column.Direction
+		}
+		indexColumns[column.ColumnName] = col
+	}
+	return nil
+}
+
+// sqliteIndex row of PRAGMA index_list
+type sqliteIndex struct {
+	Name string `gorm:"column:name"`
+}
+
+// sqliteIndexColumn row of PRAGMA index_info
+type sqliteIndexColumn struct {
+	SeqNo int32          `gorm:"column:seqno"`
+	Name  sql.NullString `gorm:"column:name"`
+}
+
+// querySQLiteIndexColumnSequences queries index columns of tables by PRAGMA index_list and PRAGMA index_info,
+// seqno of index_info is 0-based so 1 is added to it. Columns of expression indexes are skipped
+func querySQLiteIndexColumnSequences(db *gorm.DB, tableNames []string, tables map[string]map[string]map[string]model.IndexColumn) error {
+	for _, tableName := range tableNames {
+		var indexes []sqliteIndex
+		if err := db.Raw(fmt.Sprintf("PRAGMA index_list(%s)", quoteSQLiteIdent(tableName))).Scan(&indexes).Error; err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			var columns []sqliteIndexColumn
+			if err := db.Raw(fmt.Sprintf("PRAGMA index_info(%s)", quoteSQLiteIdent(index.Name))).Scan(&columns).Error; err != nil {
+				return err
+			}
+			for _, column := range columns {
+				if !column.Name.Valid {
+					continue
+				}
+				if tables[tableName] == nil {
+					tables[tableName] = make(map[string]map[string]model.IndexColumn)
+				}
+				if tables[tableName][index.Name] == nil {
+					tables[tableName][index.Name] = make(map[string]model.IndexColumn)
+				}
+				tables[tableName][index.Name][column.Name.String] = model.IndexColumn{Sequence: column.SeqNo + 1}
+			}
+		}
+	}
+	return nil
+}
+
+// quoteSQLiteIdent quote identifier as pragma arguments can't be bound
+func quoteSQLiteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// primaryIndexName name of primary key index in information_schema.STATISTICS of mysql and tidb
+const primaryIndexName = "PRIMARY"
+
+// hasPrimaryIndex check if any table has primary key index columns
+func hasPrimaryIndex(tables map[string]map[string]map[string]model.IndexColumn) bool {
+	for _, indexes := range tables {
+		if len(indexes[primaryIndexName]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// minMariaDBIgnoredVersion first MariaDB version reporting ignored indexes by STATISTICS.IGNORED
+var minMariaDBIgnoredVersion = []int{10, 6, 0}
+
+// normalizePrimarySequences renumber primary key columns from 1 in the order of SEQ_IN_INDEX,
+// TiDB doesn't number columns of clustered primary key from 1 like mysql
+func normalizePrimarySequences(tables map[string]map[string]map[string]model.IndexColumn) {
+	for _, indexes := range tables {
+		columns := indexes[primaryIndexName]
+		names := make([]string, 0, len(columns))
+		for name := range columns {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return columns[names[i]].Sequence < columns[names[j]].Sequence })
+		for i, name := range names {
+			col := columns[name]
+			col.Sequence = int32(i + 1)
+			columns[name] = col
+		}
+	}
 }
@@ -0,0 +1,1041 @@
+package generate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/utils/tests"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/model"
+)
+
+// indexSeqDriver fake database driver answering index column sequences queries,
+// every query costs latency to simulate a remote database server
+type indexSeqDriver struct {
+	latency   time.Duration
+	queries   int64
+	onQuery   func() // called before answering query
+	invisible bool   // answer invisible index idx_age besides idx_name_age
+	prefix    bool   // answer name of idx_name_age indexed by prefix of 10 characters
+	collation string // answer name of idx_name_age indexed with collation different from column
+	failures  int64  // number of queries failing with io.ErrUnexpectedEOF before answering
+}
+
+func (d *indexSeqDriver) Open(string) (driver.Conn, error) { return &indexSeqConn{d}, nil }
+
+type indexSeqConn struct{ d *indexSeqDriver }
+
+func (c *indexSeqConn) Prepare(query string) (driver.Stmt, error) { return &indexSeqStmt{c.d}, nil }
+func (c *indexSeqConn) Close() error                              { return nil }
+func (c *indexSeqConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type indexSeqStmt struct{ d *indexSeqDriver }
+
+func (s *indexSeqStmt) Close() error                               { return nil }
+func (s *indexSeqStmt) NumInput() int                              { return -1 }
+func (s *indexSeqStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+
+// Query return two index columns for every table, args: schema, tables...
+func (s *indexSeqStmt) Query(args []driver.Value) (driver.Rows, error) {
+	atomic.AddInt64(&s.d.queries, 1)
+	time.Sleep(s.d.latency)
+	if s.d.onQuery != nil {
+		s.d.onQuery()
+	}
+	if atomic.AddInt64(&s.d.failures, -1) >= 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	var length int64
+	if s.d.prefix {
+		length = 10
+	}
+	rows := &indexSeqRows{}
+	for _, table := range args[1:] {
+		rows.values = append(rows.values,
+			[]driver.Value{table, "idx_name_age", "name", int64(1), "ASC", s.d.collation, int64(1), length},
+			[]driver.Value{table, "idx_name_age", "age", int64(2), "DESC", "", int64(1), int64(0)},
+		)
+		if s.d.invisible {
+			rows.values = append(rows.values, []driver.Value{table, "idx_age", "age", int64(1), "ASC", "", int64(0), int64(0)})
+		}
+	}
+	return rows, nil
+}
+
+type indexSeqRows struct{ values [][]driver.Value }
+
+func (r *indexSeqRows) Columns() []string {
+	return []string{"table_name", "index_name", "column_name", "seq_in_index", "sort", "collation", "visible", "length"}
+}
+func (r *indexSeqRows) Close() error { return nil }
+func (r *indexSeqRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+type mysqlDialector struct{ tests.DummyDialector }
+
+func (mysqlDialector) Name() string { return "mysql" }
+
+var driverSeq int64
+
+func openIndexSeqDB(tb testing.TB, latency time.Duration) (*gorm.DB, *indexSeqDriver) {
+	d := &indexSeqDriver{latency: latency}
+	name := fmt.Sprintf("gen_index_seq_%d", atomic.AddInt64(&driverSeq, 1))
+	sql.Register(name, d)
+	sqlDB, err := sql.Open(name, "")
+	if err != nil {
+		tb.Fatalf("open fake db fail: %s", err)
+	}
+	db, err := gorm.Open(mysqlDialector{}, &gorm.Config{ConnPool: sqlDB, Logger: logger.Discard})
+	if err != nil {
+		tb.Fatalf("open gorm db fail: %s", err)
+	}
+	return db, d
+}
+
+func tableNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("table_%d", i)
+	}
+	return names
+}
+
+func TestGetIndexColumnSequencesBatch(t *testing.T) {
+	db, d := openIndexSeqDB(t, 0)
+	names := tableNames(indexSeqBatchSize + 1)
+
+	tables, err := getIndexColumnSequencesBatch(context.Background(), db, "gen", names)
+	if err != nil {
+		t.Fatalf("get index column sequences fail: %s", err)
+	}
+	if d.queries != 2 {
+		t.Errorf("expect 2 queries for %d tables, got %d", len(names), d.queries)
+	}
+	if len(tables) != len(names) {
+		t.Fatalf("expect %d tables, got %d", len(names), len(tables))
+	}
+
+	single, err := getIndexColumnSequences(context.Background(), db, "gen", names[0])
+	if err != nil {
+		t.Fatalf("get index column sequences fail: %s", err)
+	}
+	for _, indexColumns := range []map[string]map[string]model.IndexColumn{tables[names[0]], single} {
+		if col := indexColumns["idx_name_age"]["age"]; col.Sequence != 2 || col.Sort != "DESC" {
+			t.Errorf("unexpected index column: %+v", col)
+		}
+	}
+}
+
+// sqliteDriver fake database driver answering pragmas of sqlite table users, created by
+//
+//	CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, age INTEGER, email TEXT UNIQUE);
+//	CREATE INDEX idx_age_name ON users (age, name);
+//	CREATE INDEX idx_lower_name ON users (lower(name), id);
+type sqliteDriver struct{}
+
+func (d sqliteDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d sqliteDriver) Prepare(query string) (driver.Stmt, error) {
+	return sqliteStmt{query: query}, nil
+}
+func (d sqliteDriver) Close() error              { return nil }
+func (d sqliteDriver) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type sqliteStmt struct{ query string }
+
+func (s sqliteStmt) Close() error                               { return nil }
+func (s sqliteStmt) NumInput() int                              { return -1 }
+func (s sqliteStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s sqliteStmt) Query(args []driver.Value) (driver.Rows, error) {
+	switch {
+	case strings.Contains(s.query, "pragma_table_info"):
+		return &columnAttrRows{indexSeqRows{values: [][]driver.Value{
+			{args[0], "id", int64(1), int64(0), int64(0)},
+			{args[0], "name", int64(2), int64(0), int64(0)},
+			{args[0], "age", int64(3), int64(0), int64(0)},
+			{args[0], "email", int64(4), int64(0), int64(0)},
+		}}}, nil
+	case s.query == `PRAGMA index_list("users")`:
+		return &sqliteRows{[]string{"seq", "name", "unique", "origin", "partial"}, indexSeqRows{values: [][]driver.Value{
+			{int64(0), "idx_lower_name", int64(0), "c", int64(0)},
+			{int64(1), "idx_age_name", int64(0), "c", int64(0)},
+			{int64(2), "sqlite_autoindex_users_1", int64(1), "u", int64(0)},
+		}}}, nil
+	case s.query == `PRAGMA index_info("idx_lower_name")`:
+		return &sqliteRows{[]string{"seqno", "cid", "name"}, indexSeqRows{values: [][]driver.Value{
+			{int64(0), int64(-2), nil}, // expression lower(name)
+			{int64(1), int64(0), "id"},
+		}}}, nil
+	case s.query == `PRAGMA index_info("idx_age_name")`:
+		return &sqliteRows{[]string{"seqno", "cid", "name"}, indexSeqRows{values: [][]driver.Value{
+			{int64(0), int64(2), "age"},
+			{int64(1), int64(1), "name"},
+		}}}, nil
+	case s.query == `PRAGMA index_info("sqlite_autoindex_users_1")`:
+		return &sqliteRows{[]string{"seqno", "cid", "name"}, indexSeqRows{values: [][]driver.Value{
+			{int64(0), int64(3), "email"},
+		}}}, nil
+	}
+	return nil, fmt.Errorf("unexpected query %s", s.query)
+}
+
+type sqliteRows struct {
+	columns []string
+	indexSeqRows
+}
+
+func (r *sqliteRows) Columns() []string { return r.columns }
+
+func openSQLiteDB(tb testing.TB) *gorm.DB {
+	name := fmt.Sprintf("gen_sqlite_%d", atomic.AddInt64(&driverSeq, 1))
+	sql.Register(name, sqliteDriver{})
+	sqlDB, err := sql.Open(name, "")
+	if err != nil {
+		tb.Fatalf("open fake db fail: %s", err)
+	}
+	db, err := gorm.Open(sqliteDialector{}, &gorm.Config{ConnPool: sqlDB, Logger: logger.Discard})
+	if err != nil {
+		tb.Fatalf("open gorm db fail: %s", err)
+	}
+	return db
+}
+
+func TestGetIndexColumnSequencesOfSQLite(t *testing.T) {
+	db := openSQLiteDB(t)
+	indexes, err := getIndexColumnSequences(context.Background(), db, "any_schema", "users")
+	if err != nil {
+		t.Fatalf("get index column sequences fail: %s", err)
+	}
+	expects := map[string]map[string]int32{
+		"idx_age_name":   {"age": 1, "name": 2},
+		"idx_lower_name": {"id": 2},
+	}
+	for index, columns := range expects {
+		if len(indexes[index]) != len(columns) {
+			t.Errorf("expect %d columns of index %s, got %+v", len(columns), index, indexes[index])
+		}
+		for column, seq := range columns {
+			if got := indexes[index][column].Sequence; got != seq {
+				t.Errorf("expect sequence %d of %s.%s, got %d", seq, index, column, got)
+			}
+		}
+	}
+	if len(indexes) != 3 {
+		t.Errorf("expect 3 indexes with unique index of email, got %+v", indexes)
+	}
+}
+
+func TestGetIndexColumnSequencesBatchCancel(t *testing.T) {
+	db, d := openIndexSeqDB(t, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	d.onQuery = cancel // cancelled while the first batch is being read
+
+	_, err := getIndexColumnSequencesBatch(ctx, db, "gen", tableNames(indexSeqBatchSize+1))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect context canceled error, got %v", err)
+	}
+	if d.queries != 1 {
+		t.Errorf("expect introspection stopped after 1 query, got %d queries", d.queries)
+	}
+}
+
+func TestGetTableColumnsWithInvisibleIndexes(t *testing.T) {
+	db, d := openIndexSeqDB(t, 0)
+	d.invisible = true
+	info := indexedTableInfo{
+		catalogTableInfo: catalogTableInfo{"users": testColumn{dataType: "int", scanType: reflect.TypeOf(int32(0))}.columns("users", "name", "age")},
+		indexes: map[string][]gorm.Index{"users": {
+			&migrator.Index{TableName: "users", NameValue: "idx_name_age", ColumnList: []string{"name", "age"}},
+			&migrator.Index{TableName: "users", NameValue: "idx_age", ColumnList: []string{"age"}},
+		}},
+	}
+
+	testcases := []struct {
+		includeInvisible bool
+		expect           []string
+	}{
+		{false, []string{"idx_name_age,priority:2,sort:desc"}},
+		{true, []string{"idx_age,priority:1", "idx_name_age,priority:2,sort:desc"}},
+	}
+	for _, tc := range testcases {
+		conf := &model.Config{TableName: "users", Context: context.Background(), TableInfo: info,
+			FieldConfig: model.FieldConfig{FieldWithIndexTag: true, FieldWithIndexSort: true, FieldWithInvisibleIndexes: tc.includeInvisible}}
+		columns, err := getTableColumns(db, conf, "gen", "users")
+		if err != nil {
+			t.Fatalf("get table columns fail: %s", err)
+		}
+		if tag := getFields(db, conf, columns)[1].GORMTag[field.TagKeyGormIndex]; !reflect.DeepEqual(tag, tc.expect) {
+			t.Errorf("IncludeInvisibleIndexes=%t: expect index tags %v of age, got %v", tc.includeInvisible, tc.expect, tag)
+		}
+	}
+}
+
+func TestGetTableColumnsWithIndexSort(t *testing.T) {
+	db, d := openIndexSeqDB(t, 0)
+	d.collation = "utf8mb4_bin"
+	info := indexedTableInfo{
+		catalogTableInfo: catalogTableInfo{"users": {
+			testColumn{table: "users", name: "name", dataType: "varchar", scanType: reflect.TypeOf("")}.column(),
+			testColumn{table: "users", name: "age", dataType: "int", scanType: reflect.TypeOf("")}.column(),
+		}},
+		indexes: map[string][]gorm.Index{"users": {
+			&migrator.Index{TableName: "users", NameValue: "idx_name_age", ColumnList: []string{"name", "age"}},
+		}},
+	}
+
+	testcases := []struct {
+		withIndexSort bool
+		sorts         []string // sort and collation of name and age in index metadata
+		tags          [][]string
+	}{
+		{true, []string{"ASC utf8mb4_bin", "DESC "}, [][]string{{"idx_name_age,priority:1,collate:utf8mb4_bin"}, {"idx_name_age,priority:2,sort:desc"}}},
+		{false, []string{" ", " "}, [][]string{{"idx_name_age,priority:1"}, {"idx_name_age,priority:2"}}}, // opt-in
+	}
+	for _, tc := range testcases {
+		conf := &model.Config{TableName: "users", Context: context.Background(), TableInfo: info,
+			FieldConfig: model.FieldConfig{FieldWithIndexTag: true, FieldWithIndexSort: tc.withIndexSort}}
+		columns, err := getTableColumns(db, conf, "gen", "users")
+		if err != nil {
+			t.Fatalf("get table columns fail: %s", err)
+		}
+		for i, c := range columns {
+			if len(c.Indexes) != 1 {
+				t.Fatalf("WithIndexSort=%t: expect 1 index of %s, got %d", tc.withIndexSort, c.Name(), len(c.Indexes))
+			}
+			if idx := c.Indexes[0]; idx.Sort+" "+idx.Collation != tc.sorts[i] {
+				t.Errorf("WithIndexSort=%t: expect sort and collation %q of %s, got %q", tc.withIndexSort, tc.sorts[i], c.Name(), idx.Sort+" "+idx.Collation)
+			}
+		}
+		for i, f := range getFields(db, conf, columns) {
+			if tag := f.GORMTag[field.TagKeyGormIndex]; !reflect.DeepEqual(tag, tc.tags[i]) {
+				t.Errorf("WithIndexSort=%t: expect index tags %v of %s, got %v", tc.withIndexSort, tc.tags[i], f.ColumnName, tag)
+			}
+		}
+	}
+}
+
+func TestGetTableColumnsWithIndexPrefixLength(t *testing.T) {
+	db, d := openIndexSeqDB(t, 0)
+	d.prefix = true
+	info := indexedTableInfo{
+		catalogTableInfo: catalogTableInfo{"users": {
+			testColumn{table: "users", name: "name", dataType: "varchar", scanType: reflect.TypeOf("")}.column(),
+			testColumn{table: "users", name: "age", dataType: "int", scanType: reflect.TypeOf("")}.column(),
+		}},
+		indexes: map[string][]gorm.Index{"users": {
+			&migrator.Index{TableName: "users", NameValue: "idx_name_age", ColumnList: []string{"name", "age"}},
+		}},
+	}
+
+	conf := &model.Config{TableName: "users", Context: context.Background(), TableInfo: info,
+		FieldConfig: model.FieldConfig{FieldWithIndexTag: true}}
+	columns, err := getTableColumns(db, conf, "gen", "users")
+	if err != nil {
+		t.Fatalf("get table columns fail: %s", err)
+	}
+	fields := getFields(db, conf, columns)
+	if tag := fields[0].GORMTag[field.TagKeyGormIndex]; !reflect.DeepEqual(tag, []string{"idx_name_age,priority:1,length:10"}) {
+		t.Errorf("expect prefix length in index tag of name, got %v", tag)
+	}
+	if tag := fields[1].GORMTag[field.TagKeyGormIndex]; !reflect.DeepEqual(tag, []string{"idx_name_age,priority:2"}) {
+		t.Errorf("expect no prefix length in index tag of age, got %v", tag)
+	}
+}
+
+// flakyTableInfo indexedTableInfo whose GetTableColumns fails with err failures times before answering
+type flakyTableInfo struct {
+	indexedTableInfo
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *flakyTableInfo) GetTableColumns(schemaName string, tableName string) ([]*model.Column, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return f.indexedTableInfo.GetTableColumns(schemaName, tableName)
+}
+
+func TestGetTableColumnsWithRetries(t *testing.T) {
+	syntaxErr := errors.New("Error 1064 (42000): You have an error in your SQL syntax")
+	testcases := []struct {
+		err     error
+		retries int
+		calls   int   // expected calls of GetTableColumns
+		queries int64 // expected index column sequences queries
+		fail    bool
+	}{
+		{io.ErrUnexpectedEOF, 2, 3, 3, false},
+		{io.ErrUnexpectedEOF, 1, 2, 0, true},
+		{syntaxErr, 3, 1, 0, true},
+	}
+	for _, tc := range testcases {
+		db, d := openIndexSeqDB(t, 0)
+		d.failures = 2
+		info := &flakyTableInfo{err: tc.err, failures: 2, indexedTableInfo: indexedTableInfo{
+			catalogTableInfo: catalogTableInfo{"users": testColumn{dataType: "int", scanType: reflect.TypeOf(int32(0))}.columns("users", "name", "age")},
+			indexes: map[string][]gorm.Index{"users": {
+				&migrator.Index{TableName: "users", NameValue: "idx_name_age", ColumnList: []string{"name", "age"}},
+			}},
+		}}
+		conf := &model.Config{TableName: "users", Context: context.Background(), TableInfo: info, IntrospectRetries: tc.retries,
+			IntrospectBackoff: time.Millisecond, FieldConfig: model.FieldConfig{FieldWithIndexTag: true}}
+		columns, err := getTableColumns(db, conf, "gen", "users")
+		if info.calls != tc.calls || d.queries != tc.queries || (err != nil) != tc.fail {
+			t.Errorf("%s with %d retries: expect %d calls, %d queries and failure %t, got %d calls, %d queries and error %v",
+				tc.err, tc.retries, tc.calls, tc.queries, tc.fail, info.calls, d.queries, err)
+			continue
+		}
+		if err == nil && columns[1].Indexes[0].Priority != 2 {
+			t.Errorf("expect sequence of age read after retries, got %d", columns[1].Indexes[0].Priority)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	testcases := []struct {
+		err    error
+		expect bool
+	}{
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("query columns: %w", io.ErrUnexpectedEOF), true},
+		{&net.OpError{Op: "read", Err: errors.New("timeout")}, true},
+		{errors.New("[mysql] invalid connection"), true},
+		{errors.New("FATAL: terminating connection: server closed the connection unexpectedly"), true},
+		{errors.New("Error 1064 (42000): You have an error in your SQL syntax"), false},
+		{context.Canceled, false},
+		{nil, false},
+	}
+	for _, tc := range testcases {
+		if got := IsRetryable(tc.err); got != tc.expect {
+			t.Errorf("IsRetryable(%v): expect %t, got %t", tc.err, tc.expect, got)
+		}
+	}
+}
+
+func TestGetTableColumnsInOrdinalOrder(t *testing.T) {
+	column := func(name string, ordinal int) *model.Column {
+		return testColumn{table: "users", name: name, dataType: "int", ordinal: ordinal}.column()
+	}
+	names := func(columns []*model.Column) (result []string) {
+		for _, c := range columns {
+			result = append(result, c.Name())
+		}
+		return result
+	}
+
+	testcases := []struct {
+		columns []*model.Column
+		expect  []string
+	}{
+		{[]*model.Column{column("age", 3), column("id", 1), column("name", 2)}, []string{"id", "name", "age"}},
+		{[]*model.Column{column("age", 3), column("id", 0), column("name", 2)}, []string{"age", "id", "name"}}, // unknown ordinal
+	}
+	for _, tc := range testcases {
+		conf := &model.Config{TableName: "users", Context: context.Background(), TableInfo: catalogTableInfo{"users": tc.columns}}
+		columns, err := getTableColumns(dummyDB, conf, "", "users")
+		if err != nil {
+			t.Fatalf("get table columns fail: %s", err)
+		}
+		if got := names(columns); !reflect.DeepEqual(got, tc.expect) {
+			t.Errorf("expect columns %v, got %v", tc.expect, got)
+		}
+	}
+
+	columns := []*model.Column{column("age", 0), column("name", 0), column("id", 0)} // out of order ColumnTypes
+	tables, err := getColumnAttrs(context.Background(), openSQLiteDB(t), "", []string{"users"}, false)
+	if err != nil {
+		t.Fatalf("get column attributes fail: %s", err)
+	}
+	setColumnAttrs(&model.Config{}, columns, tables["users"])
+	sortColumnsByOrdinal(columns)
+	if got := names(columns); !reflect.DeepEqual(got, []string{"id", "name", "age"}) {
+		t.Errorf("expect columns sorted by ordinal of sqlite, got %v", got)
+	}
+}
+
+// fkDriver fake database driver answering rows of information_schema.KEY_COLUMN_USAGE
+type fkDriver struct {
+	query string
+	args  []driver.Value
+}
+
+func (d *fkDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d *fkDriver) Prepare(query string) (driver.Stmt, error) {
+	d.query = query
+	return d, nil
+}
+func (d *fkDriver) Close() error                               { return nil }
+func (d *fkDriver) Begin() (driver.Tx, error)                  { return nil, driver.ErrSkip }
+func (d *fkDriver) NumInput() int                              { return -1 }
+func (d *fkDriver) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+
+// Query return composite foreign key fk_member and single column foreign key fk_creator
+func (d *fkDriver) Query(args []driver.Value) (driver.Rows, error) {
+	d.args = args
+	return &fkRows{indexSeqRows{values: [][]driver.Value{
+		{"fk_creator", "creator_id", "gen", "users", "id"},
+		{"fk_member", "org_id", "gen", "org_members", "org_id"},
+		{"fk_member", "user_id", "gen", "org_members", "user_id"},
+	}}}, nil
+}
+
+type fkRows struct{ indexSeqRows }
+
+func (r *fkRows) Columns() []string {
+	return []string{"constraint_name", "column_name", "referenced_schema", "referenced_table", "referenced_column"}
+}
+
+// tidbDriver fake database driver answering version and index column sequences queries,
+// sequences of clustered primary key columns are numbered from 0, version queries are counted
+type tidbDriver struct {
+	version  string
+	versions int
+}
+
+func (d *tidbDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d *tidbDriver) Prepare(query string) (driver.Stmt, error) {
+	return &tidbStmt{d: d, query: query}, nil
+}
+func (d *tidbDriver) Close() error              { return nil }
+func (d *tidbDriver) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type tidbStmt struct {
+	d     *tidbDriver
+	query string
+}
+
+func (s *tidbStmt) Close() error                               { return nil }
+func (s *tidbStmt) NumInput() int                              { return -1 }
+func (s *tidbStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *tidbStmt) Query([]driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "VERSION()") {
+		s.d.versions++
+		return &versionRows{indexSeqRows{values: [][]driver.Value{{s.d.version}}}}, nil
+	}
+	return &indexSeqRows{values: [][]driver.Value{
+		{"memberships", "PRIMARY", "org_id", int64(0), "ASC", "", int64(1), int64(0)},
+		{"memberships", "PRIMARY", "user_id", int64(1), "ASC", "", int64(1), int64(0)},
+		{"memberships", "idx_user", "user_id", int64(1), "ASC", "", int64(1), int64(0)},
+	}}, nil
+}
+
+type versionRows struct{ indexSeqRows }
+
+func (r *versionRows) Columns() []string { return []string{"version"} }
+
+func TestGetIndexColumnSequencesOfTiDB(t *testing.T) {
+	testcases := []struct {
+		version string
+		primary map[string]model.IndexColumn
+	}{
+		{"8.0.11-TiDB-v7.5.1", map[string]model.IndexColumn{"org_id": {Sequence: 1, Sort: "ASC"}, "user_id": {Sequence: 2, Sort: "ASC"}}},
+		{"8.0.36", map[string]model.IndexColumn{"org_id": {Sequence: 0, Sort: "ASC"}, "user_id": {Sequence: 1, Sort: "ASC"}}}, // mysql is untouched
+	}
+	for _, tc := range testcases {
+		name := fmt.Sprintf("gen_tidb_%d", atomic.AddInt64(&driverSeq, 1))
+		sql.Register(name, &tidbDriver{version: tc.version})
+		sqlDB, err := sql.Open(name, "")
+		if err != nil {
+			t.Fatalf("open fake db fail: %s", err)
+		}
+		db, err := gorm.Open(mysqlDialector{}, &gorm.Config{ConnPool: sqlDB, Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("open gorm db fail: %s", err)
+		}
+
+		indexColumns, err := getIndexColumnSequences(context.Background(), db, "gen", "memberships")
+		if err != nil {
+			t.Fatalf("get index column sequences fail: %s", err)
+		}
+		if !reflect.DeepEqual(indexColumns["PRIMARY"], tc.primary) {
+			t.Errorf("%s: expect primary key columns %+v, got %+v", tc.version, tc.primary, indexColumns["PRIMARY"])
+		}
+		if seq := indexColumns["idx_user"]["user_id"].Sequence; seq != 1 {
+			t.Errorf("%s: expect sequence of secondary index untouched, got %d", tc.version, seq)
+		}
+	}
+}
+
+func TestServerFlavor(t *testing.T) {
+	testcases := []struct {
+		dialector gorm.Dialector
+		version   string
+		server    server
+		versions  int
+	}{
+		{mysqlDialector{}, "8.0.36", server{Version: "8.0.36"}, 1},
+		{mysqlDialector{}, "10.6.16-MariaDB-1:10.6.16+maria~ubu2004", server{Flavor: flavorMariaDB, Version: "10.6.16"}, 1},
+		{mysqlDialector{}, "8.0.11-TiDB-v7.5.1", server{Flavor: flavorTiDB, Version: "8.0.11"}, 1},
+		{postgresDialector{}, "CockroachDB CCL v23.1.11 (x86_64-pc-linux-gnu)", server{Flavor: flavorCockroachDB}, 1},
+		{sqliteDialector{}, "3.45.1", server{}, 0}, // no flavor other than sqlite itself
+	}
+	for _, tc := range testcases {
+		name := fmt.Sprintf("gen_flavor_%d", atomic.AddInt64(&driverSeq, 1))
+		d := &tidbDriver{version: tc.version}
+		sql.Register(name, d)
+		sqlDB, err := sql.Open(name, "")
+		if err != nil {
+			t.Fatalf("open fake db fail: %s", err)
+		}
+		db, err := gorm.Open(tc.dialector, &gorm.Config{ConnPool: sqlDB, Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("open gorm db fail: %s", err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if s := serverFlavor(db.Session(&gorm.Session{})); s != tc.server {
+				t.Errorf("%s: expect server %+v, got %+v", tc.version, tc.server, s)
+			}
+		}
+		if d.versions != tc.versions {
+			t.Errorf("%s: expect version queried %d times, got %d", tc.version, tc.versions, d.versions)
+		}
+	}
+}
+
+// mariaDBDriver fake database driver answering version and index column sequences queries,
+// information_schema.STATISTICS of MariaDB has no IS_VISIBLE, and IGNORED only since 10.6
+type mariaDBDriver struct{ version string }
+
+func (d *mariaDBDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d *mariaDBDriver) Prepare(query string) (driver.Stmt, error) {
+	return &mariaDBStmt{d: d, query: query}, nil
+}
+func (d *mariaDBDriver) Close() error              { return nil }
+func (d *mariaDBDriver) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type mariaDBStmt struct {
+	d     *mariaDBDriver
+	query string
+}
+
+func (s *mariaDBStmt) Close() error                               { return nil }
+func (s *mariaDBStmt) NumInput() int                              { return -1 }
+func (s *mariaDBStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *mariaDBStmt) Query([]driver.Value) (driver.Rows, error) {
+	switch {
+	case strings.Contains(s.query, "VERSION()"):
+		return &versionRows{indexSeqRows{values: [][]driver.Value{{s.d.version}}}}, nil
+	case strings.Contains(s.query, "IS_VISIBLE"):
+		return nil, errors.New("Error 1054 (42S22): Unknown column 'IS_VISIBLE' in 'field list'")
+	case strings.Contains(s.query, "IGNORED") && !strings.Contains(s.d.version, "MariaDB"):
+		return nil, errors.New("Error 1054 (42S22): Unknown column 'IGNORED' in 'field list'")
+	case strings.Contains(s.query, "IGNORED"):
+		return &indexSeqRows{values: [][]driver.Value{
+			{"users", "idx_name_age", "name", int64(1), "ASC", "", int64(1), int64(0)},
+			{"users", "idx_name_age", "age", int64(2), "ASC", "", int64(1), int64(0)},
+			{"users", "idx_age", "age", int64(1), "ASC", "", int64(0), int64(0)},
+		}}, nil
+	default:
+		return &indexSeqRows{values: [][]driver.Value{
+			{"users", "idx_name_age", "name", int64(1), "ASC", "", int64(1), int64(0)},
+			{"users", "idx_name_age", "age", int64(2), "ASC", "", int64(1), int64(0)},
+			{"users", "idx_age", "age", int64(1), "ASC", "", int64(1), int64(0)},
+		}}, nil
+	}
+}
+
+func TestGetIndexColumnSequencesOfMariaDB(t *testing.T) {
+	testcases := []struct {
+		version   string
+		invisible bool // idx_age is ignored
+	}{
+		{"10.5.23-MariaDB-1:10.5.23+maria~ubu2004", false},
+		{"10.6.16-MariaDB-1:10.6.16+maria~ubu2004", true},
+		{"11.4.2-MariaDB", true},
+		{"5.7.44-log", false}, // mysql 5.7 has neither IS_VISIBLE nor IGNORED
+	}
+	for _, tc := range testcases {
+		name := fmt.Sprintf("gen_mariadb_%d", atomic.AddInt64(&driverSeq, 1))
+		sql.Register(name, &mariaDBDriver{version: tc.version})
+		sqlDB, err := sql.Open(name, "")
+		if err != nil {
+			t.Fatalf("open fake db fail: %s", err)
+		}
+		db, err := gorm.Open(mysqlDialector{}, &gorm.Config{ConnPool: sqlDB, Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("open gorm db fail: %s", err)
+		}
+
+		indexColumns, err := getIndexColumnSequences(context.Background(), db, "gen", "users")
+		if err != nil {
+			t.Fatalf("%s: get index column sequences fail: %s", tc.version, err)
+		}
+		expect := map[string]model.IndexColumn{"name": {Sequence: 1, Sort: "ASC"}, "age": {Sequence: 2, Sort: "ASC"}}
+		if !reflect.DeepEqual(indexColumns["idx_name_age"], expect) {
+			t.Errorf("%s: expect index columns %+v, got %+v", tc.version, expect, indexColumns["idx_name_age"])
+		}
+		if invisible := indexColumns["idx_age"]["age"].Invisible; invisible != tc.invisible {
+			t.Errorf("%s: expect idx_age invisible %t, got %t", tc.version, tc.invisible, invisible)
+		}
+	}
+}
+
+// cockroachDriver fake database driver answering version and index column sequences queries,
+// information_schema.STATISTICS of CockroachDB reports storing and implicit columns of indexes
+type cockroachDriver struct{ version string }
+
+func (d *cockroachDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d *cockroachDriver) Prepare(query string) (driver.Stmt, error) {
+	return &cockroachStmt{d: d, query: query}, nil
+}
+func (d *cockroachDriver) Close() error              { return nil }
+func (d *cockroachDriver) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type cockroachStmt struct {
+	d     *cockroachDriver
+	query string
+}
+
+func (s *cockroachStmt) Close() error                               { return nil }
+func (s *cockroachStmt) NumInput() int                              { return -1 }
+func (s *cockroachStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s *cockroachStmt) Query([]driver.Value) (driver.Rows, error) {
+	switch {
+	case strings.Contains(s.query, "VERSION()"):
+		return &versionRows{indexSeqRows{values: [][]driver.Value{{s.d.version}}}}, nil
+	case strings.Contains(s.query, "information_schema.STATISTICS"):
+		return &cockroachRows{indexSeqRows{values: [][]driver.Value{
+			{"users", "idx_tenant_email", "crdb_internal_email_tenant_id_shard_16", "ASC", "NO", "YES"},
+			{"users", "idx_tenant_email", "tenant_id", "ASC", "NO", "NO"},
+			{"users", "idx_tenant_email", "email", "DESC", "NO", "NO"},
+			{"users", "idx_tenant_email", "name", "N/A", "YES", "NO"},
+			{"users", "idx_tenant_email", "id", "ASC", "NO", "YES"},
+			{"users", "users_pkey", "id", "ASC", "NO", "NO"},
+			{"users", "users_pkey", "name", "N/A", "YES", "NO"},
+		}}}, nil
+	default:
+		return &indexSeqRows{values: [][]driver.Value{
+			{"users", "idx_tenant_email", "tenant_id", int64(1), "ASC", "", int64(1), int64(0)},
+		}}, nil
+	}
+}
+
+type cockroachRows struct{ indexSeqRows }
+
+func (r *cockroachRows) Columns() []string {
+	return []string{"table_name", "index_name", "column_name", "direction", "storing", "implicit"}
+}
+
+func TestGetIndexColumnSequencesOfCockroachDB(t *testing.T) {
+	testcases := []struct {
+		version string
+		indexes map[string]map[string]model.IndexColumn
+	}{
+		{"CockroachDB CCL v23.1.11 (x86_64-pc-linux-gnu, built 2023/09/27 01:53:43, go1.19.10)", map[string]map[string]model.IndexColumn{
+			"idx_tenant_email": {"tenant_id": {Sequence: 1, Sort: "ASC"}, "email": {Sequence: 2, Sort: "DESC"}},
+			"users_pkey":       {"id": {Sequence: 1, Sort: "ASC"}},
+		}},
+		{"PostgreSQL 16.1 on x86_64-pc-linux-gnu", map[string]map[string]model.IndexColumn{ // pg_index query of postgres
+			"idx_tenant_email": {"tenant_id": {Sequence: 1, Sort: "ASC"}},
+		}},
+	}
+	for _, tc := range testcases {
+		name := fmt.Sprintf("gen_cockroach_%d", atomic.AddInt64(&driverSeq, 1))
+		sql.Register(name, &cockroachDriver{version: tc.version})
+		sqlDB, err := sql.Open(name, "")
+		if err != nil {
+			t.Fatalf("open fake db fail: %s", err)
+		}
+		db, err := gorm.Open(postgresDialector{}, &gorm.Config{ConnPool: sqlDB, Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("open gorm db fail: %s", err)
+		}
+
+		indexes, err := getIndexColumnSequences(context.Background(), db, "", "users")
+		if err != nil {
+			t.Fatalf("get index column sequences fail: %s", err)
+		}
+		if !reflect.DeepEqual(indexes, tc.indexes) {
+			t.Errorf("%s: expect index columns %+v, got %+v", tc.version, tc.indexes, indexes)
+		}
+	}
+}
+
+// columnAttrDriver fake database driver answering every query with column attributes of users and orders,
+// full_name of users is generated and age unsigned, queries are recorded
+type columnAttrDriver struct {
+	queries []string
+	args    []driver.Value
+	err     error
+}
+
+func (d *columnAttrDriver) Open(string) (driver.Conn, error) { return d, nil }
+func (d *columnAttrDriver) Prepare(query string) (driver.Stmt, error) {
+	d.queries = append(d.queries, query)
+	return d, nil
+}
+func (d *columnAttrDriver) Close() error                               { return nil }
+func (d *columnAttrDriver) Begin() (driver.Tx, error)                  { return nil, driver.ErrSkip }
+func (d *columnAttrDriver) NumInput() int                              { return -1 }
+func (d *columnAttrDriver) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (d *columnAttrDriver) Query(args []driver.Value) (driver.Rows, error) {
+	d.args = args
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &columnAttrRows{indexSeqRows{values: [][]driver.Value{
+		{"users", "full_name", int64(3), false, true},
+		{"users", "first_name", int64(2), false, false},
+		{"users", "age", int64(4), true, false},
+		{"users", "id", int64(1), false, false},
+		{"orders", "id", int64(1), true, false},
+	}}}, nil
+}
+
+type columnAttrRows struct{ indexSeqRows }
+
+func (r *columnAttrRows) Columns() []string {
+	return []string{"table_name", "column_name", "ordinal", "is_unsigned", "is_generated"}
+}
+
+func openColumnAttrDB(tb testing.TB, dialector gorm.Dialector, d *columnAttrDriver) *gorm.DB {
+	name := fmt.Sprintf("gen_column_attr_%d", atomic.AddInt64(&driverSeq, 1))
+	sql.Register(name, d)
+	sqlDB, err := sql.Open(name, "")
+	if err != nil {
+		tb.Fatalf("open fake db fail: %s", err)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{ConnPool: sqlDB, Logger: logger.Discard})
+	if err != nil {
+		tb.Fatalf("open gorm db fail: %s", err)
+	}
+	return db
+}
+
+func TestGetColumnAttrs(t *testing.T) {
+	testcases := []struct {
+		dialector     gorm.Dialector
+		defaultSchema string // default schema expression of empty schema name
+	}{
+		{mysqlDialector{}, "DATABASE()"},
+		{postgresDialector{}, "current_schema()"},
+		{sqlserverDialector{}, "SCHEMA_NAME()"},
+	}
+	for _, tc := range testcases {
+		d := &columnAttrDriver{}
+		db := openColumnAttrDB(t, tc.dialector, d)
+		tables, err := getColumnAttrs(context.Background(), db, "", []string{"users", "orders"}, true)
+		if err != nil {
+			t.Fatalf("%s: get column attributes fail: %s", tc.dialector.Name(), err)
+		}
+		if len(d.queries) != 1 || !strings.Contains(d.queries[0], tc.defaultSchema) {
+			t.Errorf("%s: expect one query of tables in default schema, got %v", tc.dialector.Name(), d.queries)
+		}
+		if expect := []driver.Value{"", "users", "orders"}; !reflect.DeepEqual(d.args, expect) {
+			t.Errorf("%s: expect args %v, got %v", tc.dialector.Name(), expect, d.args)
+		}
+		if attr := tables["users"]["full_name"]; attr != (model.ColumnAttr{Ordinal: 3, Generated: true}) {
+			t.Errorf("%s: unexpected attributes of users.full_name: %+v", tc.dialector.Name(), attr)
+		}
+		if attr := tables["orders"]["id"]; attr != (model.ColumnAttr{Ordinal: 1, Unsigned: true}) {
+			t.Errorf("%s: unexpected attributes of orders.id: %+v", tc.dialector.Name(), attr)
+		}
+	}
+
+	d := &columnAttrDriver{err: errors.New("access denied")}
+	if _, err := getColumnAttrs(context.Background(), openColumnAttrDB(t, mysqlDialector{}, d), "gen", []string{"users"}, false); err == nil {
+		t.Error("expect error of column attributes query returned")
+	}
+}
+
+func TestGetTableColumnsWithColumnAttrCache(t *testing.T) {
+	tableColumns := func(table string) []*model.Column { return testColumn{dataType: "int"}.columns(table, "id", "age") }
+	d := &columnAttrDriver{}
+	db := openColumnAttrDB(t, mysqlDialector{}, d)
+	cache := model.NewColumnAttrCache()
+	if err := PrefetchColumnAttrs(context.Background(), db, "gen", []string{"users", "orders"}, false, cache); err != nil {
+		t.Fatalf("prefetch column attributes fail: %s", err)
+	}
+
+	for _, table := range []string{"users", "orders"} {
+		conf := &model.Config{
+			Context:         context.Background(),
+			TableInfo:       catalogTableInfo{table: tableColumns(table)},
+			ColumnAttrCache: cache,
+			FieldConfig:     model.FieldConfig{FieldSignable: true},
+		}
+		columns, err := getTableColumns(db, conf, "gen", table)
+		if err != nil {
+			t.Fatalf("get table columns fail: %s", err)
+		}
+		if table == "users" && (columns[0].Unsigned || !columns[1].Unsigned) {
+			t.Errorf("expect only users.age unsigned, got %t %t", columns[0].Unsigned, columns[1].Unsigned)
+		}
+		if table == "orders" && (!columns[0].Unsigned || columns[1].Unsigned) {
+			t.Errorf("expect only orders.id unsigned, got %t %t", columns[0].Unsigned, columns[1].Unsigned)
+		}
+	}
+	if len(d.queries) != 1 {
+		t.Errorf("expect columns of all tables queried once, got %d queries", len(d.queries))
+	}
+
+	// failed query is logged and skipped
+	d = &columnAttrDriver{err: errors.New("access denied")}
+	conf := &model.Config{Context: context.Background(), TableInfo: catalogTableInfo{"users": tableColumns("users")},
+		FieldConfig: model.FieldConfig{FieldSignable: true}}
+	columns, err := getTableColumns(openColumnAttrDB(t, mysqlDialector{}, d), conf, "gen", "users")
+	if err != nil || len(columns) != 2 || columns[1].Unsigned {
+		t.Errorf("expect columns without attributes when query fails, got %v %v", columns, err)
+	}
+}
+
+func TestGetTableColumnsWithGeneratedColumns(t *testing.T) {
+	for _, dialector := range []gorm.Dialector{mysqlDialector{}, postgresDialector{}} {
+		for _, detect := range []bool{true, false} {
+			d := &columnAttrDriver{}
+			db := openColumnAttrDB(t, dialector, d)
+
+			conf := &model.Config{
+				Context:     context.Background(),
+				TableInfo:   catalogTableInfo{"users": testColumn{dataType: "varchar", scanType: reflect.TypeOf("")}.columns("users", "first_name", "full_name")},
+				FieldConfig: model.FieldConfig{FieldDetectGeneratedColumns: detect},
+			}
+			columns, err := getTableColumns(db, conf, "gen", "users")
+			if err != nil {
+				t.Fatalf("get table columns fail: %s", err)
+			}
+			if columns[0].Generated || columns[1].Generated != detect {
+				t.Errorf("%s detect=%t: expect only full_name generated when detected, got %t %t", dialector.Name(), detect, columns[0].Generated, columns[1].Generated)
+			}
+			columns[1].WithNS(nil)
+			tag := columns[1].ToField(false, false, false).GORMTag
+			if _, ok := tag[field.TagKeyGormReadOnly]; ok != detect {
+				t.Errorf("%s detect=%t: unexpected gorm tag of full_name: %s", dialector.Name(), detect, tag.Build())
+			}
+			if detect && (len(d.queries) != 1 || !strings.Contains(d.queries[0], map[string]string{"mysql": "GENERATED", "postgres": "attgenerated"}[dialector.Name()])) {
+				t.Errorf("%s: unexpected generated columns query: %v", dialector.Name(), d.queries)
+			}
+		}
+	}
+
+	// columns tagged read-only are not written by BulkInsert
+	type user struct {
+		ID        int64
+		FirstName string
+		FullName  string `gorm:"->"`
+	}
+	db, _ := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true, Logger: logger.Discard})
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	var sql string
+	_ = db.Callback().Create().After("gorm:create").Register("gen:capture_sql", func(tx *gorm.DB) { sql = tx.Statement.SQL.String() })
+	if err := db.CreateInBatches([]*user{{FirstName: "a", FullName: "a"}}, 10).Error; err != nil {
+		t.Fatalf("create in batches fail: %s", err)
+	}
+	if !strings.Contains(sql, "first_name") || strings.Contains(sql, "full_name") {
+		t.Errorf("expect read-only column excluded from insert, got %s", sql)
+	}
+}
+
+func TestGroupByColumnWithSequencesOrder(t *testing.T) {
+	indexList := []gorm.Index{
+		migrator.Index{NameValue: "idx_name", ColumnList: []string{"name"}},
+		migrator.Index{NameValue: "idx_age_name", ColumnList: []string{"age", "name"}},
+		migrator.Index{NameValue: "idx_name_age", ColumnList: []string{"name", "age"}},
+		migrator.Index{NameValue: "uniq_name", ColumnList: []string{"name"}, UniqueValue: sql.NullBool{Bool: true, Valid: true}},
+	}
+	indexColumns := map[string]map[string]model.IndexColumn{
+		"idx_age_name": {"age": {Sequence: 1}, "name": {Sequence: 2}},
+	}
+	want := map[string][]string{
+		"name": {"idx_age_name:2", "idx_name:1", "idx_name_age:1", "uniq_name:1"},
+		"age":  {"idx_age_name:1", "idx_name_age:2"},
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 20; i++ {
+		r.Shuffle(len(indexList), func(i, j int) { indexList[i], indexList[j] = indexList[j], indexList[i] })
+
+		got := make(map[string][]string)
+		for col, indexes := range model.GroupByColumnWithSequences(indexList, indexColumns) {
+			for _, idx := range indexes {
+				got[col] = append(got[col], fmt.Sprintf("%s:%d", idx.Name(), idx.Priority))
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expect indexes %v, got %v", want, got)
+		}
+	}
+}
+
+func TestGetTableForeignKeys(t *testing.T) {
+	d := &fkDriver{}
+	name := fmt.Sprintf("gen_fk_%d", atomic.AddInt64(&driverSeq, 1))
+	sql.Register(name, d)
+	sqlDB, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("open fake db fail: %s", err)
+	}
+	db, err := gorm.Open(mysqlDialector{}, &gorm.Config{ConnPool: sqlDB, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open gorm db fail: %s", err)
+	}
+
+	fkInfo, ok := getTableInfo(context.Background(), db).(model.IForeignKeyInfo)
+	if !ok {
+		t.Fatal("expect table info of db providing foreign keys")
+	}
+	foreignKeys, err := fkInfo.GetTableForeignKeys("gen", "projects")
+	if err != nil {
+		t.Fatalf("get table foreign keys fail: %s", err)
+	}
+	if !strings.Contains(d.query, "information_schema.KEY_COLUMN_USAGE") || len(d.args) != 2 || d.args[0] != "gen" || d.args[1] != "projects" {
+		t.Errorf("unexpected foreign keys query %s with args %v", d.query, d.args)
+	}
+	expects := []*model.ForeignKey{
+		{Name: "fk_creator", Columns: []string{"creator_id"}, ReferencedSchema: "gen", ReferencedTable: "users", ReferencedColumns: []string{"id"}},
+		{Name: "fk_member", Columns: []string{"org_id", "user_id"}, ReferencedSchema: "gen", ReferencedTable: "org_members", ReferencedColumns: []string{"org_id", "user_id"}},
+	}
+	if !reflect.DeepEqual(foreignKeys, expects) {
+		t.Errorf("expect foreign keys %+v, got %+v", expects, foreignKeys)
+	}
+}
+
+func BenchmarkIndexColumnSequences(b *testing.B) {
+	names := tableNames(100)
+
+	b.Run("n+1", func(b *testing.B) {
+		db, _ := openIndexSeqDB(b, 100*time.Microsecond)
+		for i := 0; i < b.N; i++ {
+			for _, name := range names {
+				if _, err := getIndexColumnSequences(context.Background(), db, "gen", name); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		db, _ := openIndexSeqDB(b, 100*time.Microsecond)
+		for i := 0; i < b.N; i++ {
+			if _, err := getIndexColumnSequencesBatch(context.Background(), db, "gen", names); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
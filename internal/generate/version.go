@@ -0,0 +1,30 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OptimisticLock version column of model, updated by UpdateWithVersion with WHERE version = ? guard
+type OptimisticLock struct {
+	Column  string // column name
+	Type    string // go type of version, e.g. int64
+	GenType string // field type of version, e.g. Int64 for field.NewInt64
+}
+
+// OptimisticLock version column of model, nil when model has no such column. Version must be non-pointer integer
+func (b *QueryStructMeta) OptimisticLock(column string) (*OptimisticLock, error) {
+	if column == "" {
+		return nil, nil
+	}
+	for _, f := range b.ColumnFields() {
+		if f.ColumnName != column {
+			continue
+		}
+		if integer := strings.HasPrefix(f.Type, "int") || strings.HasPrefix(f.Type, "uint"); !integer || !filterTypes[f.Type] {
+			return nil, fmt.Errorf("optimistic lock column %s of %s must be integer, got %s", column, b.ModelStructName, f.Type)
+		}
+		return &OptimisticLock{Column: column, Type: f.Type, GenType: f.GenType()}, nil
+	}
+	return nil, nil
+}
@@ -10,6 +10,8 @@ import (
 const (
 	// DefaultModelPkg ...
 	DefaultModelPkg = "model"
+	// DefaultSoftDeleteField ...
+	DefaultSoftDeleteField = "deleted_at"
 )
 
 // Status sql status
@@ -169,14 +171,35 @@ type Field struct {
 	ColumnName       string
 	ColumnComment    string
 	MultilineComment bool
+	DocComment       bool // generate column comment as doc comment above field
 	Tag              field.Tag
 	GORMTag          field.GormTag
 	CustomGenType    string
 	Relation         *field.Relation
+	SelfRelation     bool        // Relation targets the model being generated
+	ForeignKey       *ForeignKey // foreign key of belongs-to Relation read from table metadata
+	Enum             *Enum       // enum type generated for field
+	Embedded         bool        // anonymous field of base struct embedded instead of base columns
+	BaseEmbedded     bool        // column provided by embedded base struct, not declared in model struct
 
 	Column *Column
 }
 
+// Meta model assembled from table, exposed to model plugins. Fields and ImportPkgPaths can be changed,
+// TableName and ModelStructName are read-only
+type Meta struct {
+	TableName       string
+	ModelStructName string
+	Fields          []*Field
+	ImportPkgPaths  []string // import paths of model file, e.g. "github.com/acme/validate"
+}
+
+// BaseModel base struct embedded into model instead of its columns, e.g. gorm.Model
+type BaseModel struct {
+	Type    string   // embedded struct type, e.g. gorm.Model
+	Columns []string // columns provided by embedded struct, e.g. id, created_at, updated_at, deleted_at
+}
+
 // Tags ...
 func (m *Field) Tags() string {
 	if _, ok := m.Tag[field.TagKeyGorm]; ok {
@@ -189,6 +212,18 @@ func (m *Field) Tags() string {
 	return m.Tag.Build()
 }
 
+// DocCommentLines split column comment into lines for doc comment
+func (m *Field) DocCommentLines() []string {
+	if m.ColumnComment == "" {
+		return nil
+	}
+	lines := strings.Split(strings.ReplaceAll(m.ColumnComment, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return lines
+}
+
 // IsRelation ...
 func (m *Field) IsRelation() bool { return m.Relation != nil }
 
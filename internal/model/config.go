@@ -1,8 +1,10 @@
 package model
 
 import (
+	"context"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -13,9 +15,19 @@ type Config struct {
 	TablePrefix string
 	TableName   string
 	ModelName   string
+	View        bool // generate read-only model from database view
 
-	ImportPkgPaths []string
-	ModelOpts      []Option
+	TableNameInComment bool // state source schema and table in model doc comment
+
+	ImportPkgPaths   []string
+	ModelOpts        []Option
+	TableInfo        ITableInfo        // table metadata provider, read from db when nil
+	Context          context.Context   // context of introspection queries, default: context.Background()
+	IndexColumnCache *IndexColumnCache // index column metadata cache of current generation run
+	ColumnAttrCache  *ColumnAttrCache  // column attributes cache of current generation run
+
+	IntrospectRetries int           // number of retries of introspection queries failing with transient errors
+	IntrospectBackoff time.Duration // wait before the first retry, doubled after each retry
 
 	NameStrategy
 	FieldConfig
@@ -24,7 +36,12 @@ type Config struct {
 
 // NameStrategy name strategy
 type NameStrategy struct {
-	SchemaNameOpts []SchemaNameOpt
+	SchemaNameOpts      []SchemaNameOpt
+	TableNameWithSchema bool     // prefix table name with schema when schema is not the default one
+	TableNameSchema     string   // schema prefixed to table name of all tables, takes precedence over TableNameWithSchema
+	Initialisms         []string // initialisms kept in their written form in struct and field names
+	Naming              NamingStrategy
+	TrimPrefixes        []string // table name prefixes trimmed before naming model struct, the longest matching one is trimmed
 
 	TableNameNS func(tableName string) string
 	ModelNameNS func(tableName string) string
@@ -33,15 +50,38 @@ type NameStrategy struct {
 
 // FieldConfig field configuration
 type FieldConfig struct {
-	DataTypeMap map[string]func(columnType gorm.ColumnType) (dataType string)
-
-	FieldNullable     bool // generate pointer when field is nullable
-	FieldCoverable    bool // generate pointer when field has default value
-	FieldSignable     bool // detect integer field's unsigned type, adjust generated data type
-	FieldWithIndexTag bool // generate with gorm index tag
-	FieldWithTypeTag  bool // generate with gorm column type tag
-
-	FieldJSONTagNS func(columnName string) string
+	DataTypeMap    map[string]func(columnType gorm.ColumnType) (dataType string)
+	FieldTypeRules []FieldTypeRule // column name based type mapping, take precedence over DataTypeMap
+	FieldDateType  string          // go type of date-only columns, time.Time is generated when empty
+
+	UseScanTypeDialects map[string]bool // dialect name => whether resolve go type from driver scan type, override built-in defaults
+
+	FieldNullable                bool     // generate pointer when field is nullable
+	FieldNullableExceptDefaulted bool     // generate value instead of pointer for nullable column with non-null default
+	FieldCoverable               bool     // generate pointer when field has default value
+	FieldSignable                bool     // detect integer field's unsigned type, adjust generated data type
+	FieldWithIndexTag            bool     // generate with gorm index tag
+	FieldWithIndexSort           bool     // generate index tag with sort direction and collation
+	FieldWithIndexLookups        bool     // read indexes of all columns for index lookups of query code
+	FieldWithComment             bool     // generate column comment as doc comment above field
+	FieldWithForeignKeyRelations bool     // generate belongs-to relation fields from foreign keys
+	FieldWithReverseRelations    bool     // generate has-one/has-many relation fields on tables referenced by foreign keys
+	FieldWithTypeTag             bool     // generate with gorm column type tag
+	FieldUseDatatypesJSON        bool     // generate json columns as datatypes.JSON
+	FieldWithEnumTypes           bool     // generate named go type for enum columns
+	FieldWithCheckConstraints    bool     // generate check constraints of table as doc comment above model struct
+	FieldArrayLib                ArrayLib // library of go types generated for postgres array columns
+	FieldWithDefaultValueTag     bool     // quote string default values in default tag, functions are kept as is
+	FieldWithInvisibleIndexes    bool     // generate index tags of invisible indexes
+	FieldDetectGeneratedColumns  bool     // detect generated columns of mysql and postgres, tagged read-only
+	FieldPreciseIntegerTypes     bool     // generate integer columns as go types of the same width, e.g. smallint => int16
+	FieldPreciseTinyIntAsBool    bool     // generate tinyint(1) as bool with FieldPreciseIntegerTypes
+
+	FieldSoftDeleteNames []string            // columns generated as gorm.DeletedAt
+	FieldJSONTypes       map[string]JSONType // column name => go type of json column
+	FieldEmbedBaseModel  BaseModel           // base struct embedded instead of its columns when table has all of them
+	FieldJSONTagNS       func(columnName string) string
+	FieldTagProviders    []TagProvider // providers of extra struct tags, consulted per column in order
 
 	ModifyOpts []FieldOption
 	FilterOpts []FieldOption
@@ -59,6 +99,9 @@ func (cfg *Config) Preprocess() *Config {
 		cfg.ModelPkg = DefaultModelPkg
 	}
 	cfg.ModelPkg = filepath.Base(cfg.ModelPkg)
+	if cfg.Context == nil {
+		cfg.Context = context.Background()
+	}
 
 	cfg.ModifyOpts, cfg.FilterOpts, cfg.CreateOpts, cfg.MethodOpts = sortOptions(cfg.ModelOpts)
 
@@ -75,6 +118,10 @@ func (cfg *Config) GetNames() (tableName, structName, fileName string) {
 
 	if cfg.TableNameNS != nil {
 		tableName = cfg.TableNameNS(tableName)
+	} else if cfg.Naming != nil {
+		if name := cfg.Naming.TableName(tableName); name != "" {
+			tableName = name
+		}
 	}
 	if tableName != "" && !strings.HasPrefix(tableName, cfg.TablePrefix) {
 		tableName = cfg.TablePrefix + tableName
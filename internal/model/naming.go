@@ -0,0 +1,66 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// NamingStrategy go names of tables and columns, empty result falls back to the default naming,
+// initialisms are applied to the names returned
+type NamingStrategy interface {
+	// TableName table name used in generated model, e.g. TableName()
+	TableName(tableName string) string
+	// ModelName model struct name of table
+	ModelName(tableName string) string
+	// ColumnName struct field name of column
+	ColumnName(tableName, columnName string) string
+}
+
+// DefaultModelName model struct name of table from Naming or db naming strategy, with initialisms applied
+func (ns *NameStrategy) DefaultModelName(db *gorm.DB, tableName string) string {
+	if prefix := LongestPrefix(tableName, ns.TrimPrefixes); prefix != "" && prefix != tableName {
+		tableName = strings.TrimPrefix(tableName, prefix)
+	}
+	if ns.Naming != nil {
+		if name := ns.Naming.ModelName(tableName); name != "" {
+			return ApplyInitialisms(name, ns.Initialisms)
+		}
+	}
+	return ApplyInitialisms(db.NamingStrategy.SchemaName(tableName), ns.Initialisms)
+}
+
+// LongestPrefix the longest one of prefixes which name starts with, empty when none matches
+func LongestPrefix(name string, prefixes []string) string {
+	var longest string
+	for _, prefix := range prefixes {
+		if len(prefix) > len(longest) && strings.HasPrefix(name, prefix) {
+			longest = prefix
+		}
+	}
+	return longest
+}
+
+// ApplyInitialisms replace initialisms in camel case name with their written form,
+// e.g. Oauth2Token => OAuth2Token with initialism OAuth2, only whole words are replaced
+func ApplyInitialisms(name string, initialisms []string) string {
+	for _, word := range initialisms {
+		if len(word) < 2 {
+			continue
+		}
+		title := strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+		for i := 0; i < len(name); {
+			j := strings.Index(name[i:], title)
+			if j < 0 {
+				break
+			}
+			start, end := i+j, i+j+len(title)
+			if end == len(name) || unicode.IsUpper(rune(name[end])) {
+				name = name[:start] + word + name[end:]
+			}
+			i = end
+		}
+	}
+	return name
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"regexp"
+
 	"gorm.io/gorm"
 )
 
@@ -34,6 +36,12 @@ var (
 	_ Option = CreateFieldOpt(nil)
 
 	_ Option = AddMethodOpt(nil)
+
+	_ Option = TableFilterOpt{}
+
+	_ Option = ModeOpt(0)
+
+	_ Option = JSONPathOpt{}
 )
 
 // ModifyFieldOpt modify field option
@@ -72,6 +80,78 @@ func (AddMethodOpt) OptionType() string { return methodType }
 // Methods ...
 func (o AddMethodOpt) Methods() []interface{} { return o() }
 
+const tableType = "table"
+
+// TableFilterOpt table filter option, select tables by name when generating all tables
+type TableFilterOpt struct {
+	Exclude  bool // skip matched tables instead of generating matched tables only
+	Patterns []*regexp.Regexp
+}
+
+// OptionType implement for interface Option
+func (TableFilterOpt) OptionType() string { return tableType }
+
+// Match check if any pattern matches table name
+func (o TableFilterOpt) Match(tableName string) bool {
+	for _, p := range o.Patterns {
+		if p.MatchString(tableName) {
+			return true
+		}
+	}
+	return false
+}
+
+const modeType = "mode"
+
+// ModelMode generate mode of a single model
+type ModelMode uint8
+
+const (
+	// ModelWithQuery generate model struct and query code, the default mode
+	ModelWithQuery ModelMode = iota
+	// ModelOnly generate model struct only, query code is skipped when model is applied
+	ModelOnly
+)
+
+// ModeOpt model generate mode option, the last one wins
+type ModeOpt ModelMode
+
+// OptionType implement for interface Option
+func (ModeOpt) OptionType() string { return modeType }
+
+// GetModelMode get generate mode of model from options
+func GetModelMode(opts []Option) (mode ModelMode) {
+	for _, opt := range opts {
+		if opt, ok := opt.(ModeOpt); ok {
+			mode = ModelMode(opt)
+		}
+	}
+	return mode
+}
+
+const jsonPathType = "json path"
+
+// JSONPathOpt json path of column extracted by generated query code, e.g. metadata ->> 'tenant_id'
+type JSONPathOpt struct {
+	Column string // json column name
+	Path   string // dotted path of value in json document, e.g. tenant_id or owner.id
+	Name   string // go name of extracted value, e.g. TenantID generates WhereTenantID
+	Type   string // go type of extracted value
+}
+
+// OptionType implement for interface Option
+func (JSONPathOpt) OptionType() string { return jsonPathType }
+
+// GetJSONPaths get json paths declared by options
+func GetJSONPaths(opts []Option) (paths []JSONPathOpt) {
+	for _, opt := range opts {
+		if opt, ok := opt.(JSONPathOpt); ok {
+			paths = append(paths, opt)
+		}
+	}
+	return paths
+}
+
 func sortOptions(opts []Option) (modifyOpts []FieldOption, filterOpts []FieldOption, createOpts []FieldOption, methodOpt []MethodOption) {
 	for _, opt := range opts {
 		switch opt := opt.(type) {
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ArrayLib library of go types generated for postgres array columns
+type ArrayLib string
+
+const (
+	// ArrayLibPQ github.com/lib/pq array types, e.g. pq.Int64Array
+	ArrayLibPQ ArrayLib = "pq"
+	// ArrayLibPgx github.com/jackc/pgtype array types of pgx, e.g. pgtype.Int8Array
+	ArrayLibPgx ArrayLib = "pgx"
+	// ArrayLibJSON go slice types of json and jsonb columns holding json arrays, read and written by gorm json serializer,
+	// e.g. []string `gorm:"serializer:json"`. Native arrays can't be written as json, they are generated as types of ArrayLibPQ
+	ArrayLibJSON ArrayLib = "json"
+)
+
+// ImportPath import path of array types
+func (lib ArrayLib) ImportPath() string {
+	switch lib {
+	case ArrayLibPQ, ArrayLibJSON:
+		return "github.com/lib/pq"
+	case ArrayLibPgx:
+		return "github.com/jackc/pgtype"
+	}
+	return ""
+}
+
+// arrayTypes go type of one-dimensional array by element type name of pg internal, e.g. int4 of _int4
+var arrayTypes = map[ArrayLib]map[string]string{
+	ArrayLibPQ: {
+		"int2": "pq.Int32Array", "int4": "pq.Int32Array", "int8": "pq.Int64Array",
+		"float4": "pq.Float32Array", "float8": "pq.Float64Array", "numeric": "pq.Float64Array",
+		"text": "pq.StringArray", "varchar": "pq.StringArray", "bpchar": "pq.StringArray", "uuid": "pq.StringArray",
+		"bool": "pq.BoolArray", "bytea": "pq.ByteaArray",
+	},
+	ArrayLibPgx: {
+		"int2": "pgtype.Int2Array", "int4": "pgtype.Int4Array", "int8": "pgtype.Int8Array",
+		"float4": "pgtype.Float4Array", "float8": "pgtype.Float8Array", "numeric": "pgtype.NumericArray",
+		"text": "pgtype.TextArray", "varchar": "pgtype.VarcharArray", "bpchar": "pgtype.BPCharArray", "uuid": "pgtype.UUIDArray",
+		"bool": "pgtype.BoolArray", "bytea": "pgtype.ByteaArray",
+		"date": "pgtype.DateArray", "timestamp": "pgtype.TimestampArray", "timestamptz": "pgtype.TimestamptzArray",
+	},
+}
+
+// arrayElemAliases pg internal name of element type written in sql standard, e.g. integer[]
+var arrayElemAliases = map[string]string{
+	"smallint": "int2", "integer": "int4", "int": "int4", "bigint": "int8",
+	"real": "float4", "double precision": "float8", "decimal": "numeric",
+	"character varying": "varchar", "character": "bpchar", "char": "bpchar",
+	"boolean": "bool", "timestamp without time zone": "timestamp", "timestamp with time zone": "timestamptz",
+}
+
+var arrayElemModifier = regexp.MustCompile(`\(.*?\)`) // e.g. (255) of character varying(255)[]
+
+// arrayType go type of postgres array column, ok is false when column is not an array.
+// multi-dimensional arrays and arrays of unsupported element type fall back to the raw scan type
+func (c *Column) arrayType() (fieldType string, ok bool) {
+	names := []string{strings.ToLower(c.DatabaseTypeName()), strings.ToLower(c.columnType())}
+	var elem string
+	for _, name := range names {
+		switch {
+		case strings.Contains(name, "[][]"):
+			return c.rawType(), true
+		case strings.HasPrefix(name, "_"):
+			elem = name[1:]
+		case strings.HasSuffix(name, "[]") && elem == "":
+			elem = strings.TrimSpace(arrayElemModifier.ReplaceAllString(strings.TrimSuffix(name, "[]"), ""))
+		}
+	}
+	if elem == "" {
+		return "", false
+	}
+	if alias, ok := arrayElemAliases[elem]; ok {
+		elem = alias
+	}
+	lib := c.ArrayLib
+	if lib == ArrayLibJSON {
+		lib = ArrayLibPQ
+	}
+	if typ, ok := arrayTypes[lib][elem]; ok {
+		return typ, true
+	}
+	return c.rawType(), true
+}
+
+// jsonSerialized check if json column is generated as slice type with ArrayLibJSON, which needs gorm json serializer
+func (c *Column) jsonSerialized(fieldType string) bool {
+	return c.ArrayLib == ArrayLibJSON && c.JSONType != "" && c.isJSON() && strings.HasPrefix(strings.TrimPrefix(fieldType, "*"), "[]")
+}
+
+// rawType go type of column's driver scan type, string when it is unknown
+func (c *Column) rawType() string {
+	if c.ScanType() != nil && c.ScanType().String() != "interface {}" {
+		return c.ScanType().String()
+	}
+	return "string"
+}
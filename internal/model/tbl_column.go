@@ -3,21 +3,58 @@ package model
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"gorm.io/gen/field"
 	"gorm.io/gorm"
 )
 
+// FieldTypeRule go type for columns whose name matches Pattern
+type FieldTypeRule struct {
+	Pattern *regexp.Regexp
+	GoType  string
+}
+
+// JSONType go type of json column, PkgPath is imported by model file when it is not empty
+type JSONType struct {
+	GoType  string
+	PkgPath string
+}
+
+// TagProvider provide struct tag key and value of column, no tag is generated when ok is false
+type TagProvider func(c *Column) (key, value string, ok bool)
+
 // Column table column's info
 type Column struct {
 	gorm.ColumnType
-	TableName   string                                                        `gorm:"column:TABLE_NAME"`
-	Indexes     []*Index                                                      `gorm:"-"`
-	UseScanType bool                                                          `gorm:"-"`
-	dataTypeMap map[string]func(columnType gorm.ColumnType) (dataType string) `gorm:"-"`
-	jsonTagNS   func(columnName string) string                                `gorm:"-"`
+	TableName               string                                                        `gorm:"column:TABLE_NAME"`
+	Ordinal                 int                                                           `gorm:"-"` // ordinal position of column declared in table, unknown when 0
+	Indexes                 []*Index                                                      `gorm:"-"`
+	UseScanType             bool                                                          `gorm:"-"`
+	UseJSONType             bool                                                          `gorm:"-"` // use datatypes.JSON for json columns
+	EnumValues              []string                                                      `gorm:"-"` // values of enum column
+	Unsigned                bool                                                          `gorm:"-"` // integer column is unsigned
+	View                    bool                                                          `gorm:"-"` // column of database view, not tagged as primary key
+	ArrayLib                ArrayLib                                                      `gorm:"-"` // library of postgres array types, arrays are not detected when empty
+	JSONType                string                                                        `gorm:"-"` // go type of json or jsonb column, takes precedence over other type mappings
+	InvisibleIndexes        bool                                                          `gorm:"-"` // generate index tags of invisible indexes
+	WithoutIndexTag         bool                                                          `gorm:"-"` // indexes are read for query code only, index tags are not generated
+	QuoteDefault            bool                                                          `gorm:"-"` // quote default value of string column in default tag, functions are kept as is
+	NullableExceptDefaulted bool                                                          `gorm:"-"` // nullable column with non-null default value is not generated as pointer
+	Generated               bool                                                          `gorm:"-"` // generated (computed) column, tagged read-only
+	DateType                string                                                        `gorm:"-"` // go type of date-only column, time.Time is generated when empty
+	BitAsBool               bool                                                          `gorm:"-"` // generate bit column as bool whatever its scan type, e.g. BIT of sqlserver
+	PreciseIntegers         bool                                                          `gorm:"-"` // generate integer column as go type of the same width, e.g. smallint => int16
+	TinyIntAsBool           bool                                                          `gorm:"-"` // generate tinyint(1) as bool with PreciseIntegers
+	TinyIntUnsigned         bool                                                          `gorm:"-"` // tinyint is unsigned whatever its column type, e.g. TINYINT of sqlserver is 0..255
+	PrimaryKeyPriority      bool                                                          `gorm:"-"` // tag priority of composite primary key, set when key order differs from column order
+	dataTypeMap             map[string]func(columnType gorm.ColumnType) (dataType string) `gorm:"-"`
+	typeRules               []FieldTypeRule                                               `gorm:"-"`
+	jsonTagNS               func(columnName string) string                                `gorm:"-"`
+	softDelete              []string                                                      `gorm:"-"`
 }
 
 // SetDataTypeMap set data type map
@@ -25,11 +62,48 @@ func (c *Column) SetDataTypeMap(m map[string]func(columnType gorm.ColumnType) (d
 	c.dataTypeMap = m
 }
 
+// SetFieldTypeRules set column name based type mapping rules
+func (c *Column) SetFieldTypeRules(rules []FieldTypeRule) {
+	c.typeRules = rules
+}
+
+// SetSoftDeleteFields set names of soft delete columns, DefaultSoftDeleteField is used when it is empty
+func (c *Column) SetSoftDeleteFields(names []string) {
+	c.softDelete = names
+}
+
 // GetDataType get data type
 func (c *Column) GetDataType() (fieldtype string) {
+	if c.JSONType != "" && c.isJSON() {
+		return c.JSONType
+	}
+	for _, rule := range c.typeRules {
+		if rule.Pattern != nil && rule.Pattern.MatchString(c.Name()) {
+			return rule.GoType
+		}
+	}
 	if mapping, ok := c.dataTypeMap[c.DatabaseTypeName()]; ok {
 		return mapping(c.ColumnType)
 	}
+	if c.PreciseIntegers {
+		if typ, ok := c.preciseIntegerType(); ok {
+			return typ
+		}
+	}
+	if c.BitAsBool && strings.EqualFold(c.DatabaseTypeName(), "bit") {
+		return "bool"
+	}
+	if c.DateType != "" && c.isDate() {
+		return c.DateType
+	}
+	if c.ArrayLib != "" {
+		if arrayType, ok := c.arrayType(); ok {
+			return arrayType
+		}
+	}
+	if c.UseJSONType && c.isJSON() {
+		return "datatypes.JSON"
+	}
 	if c.UseScanType && c.ScanType() != nil {
 		return c.ScanType().String()
 	}
@@ -44,19 +118,35 @@ func (c *Column) WithNS(jsonTagNS func(columnName string) string) {
 	}
 }
 
+// nullScanTypes value types of database/sql null types and []uint8, nullable columns are pointer of them when FieldNullable is enabled
+var nullScanTypes = map[string]string{
+	"sql.NullString":  "string",
+	"sql.NullBool":    "bool",
+	"sql.NullByte":    "uint8",
+	"sql.NullInt16":   "int16",
+	"sql.NullInt32":   "int32",
+	"sql.NullInt64":   "int64",
+	"sql.NullFloat64": "float64",
+	"sql.NullTime":    "time.Time",
+	"[]uint8":         "[]byte", // same type as []uint8, mapped to field.Bytes
+}
+
 // ToField convert to field
 func (c *Column) ToField(nullable, coverable, signable bool) *Field {
 	fieldType := c.GetDataType()
-	if signable && strings.Contains(c.columnType(), "unsigned") && strings.HasPrefix(fieldType, "int") {
-		fieldType = "u" + fieldType
+	if typ, ok := nullScanTypes[fieldType]; ok && nullable {
+		fieldType = typ
+	}
+	if signable && c.unsigned() && strings.HasPrefix(fieldType, "int") {
+		fieldType = c.unsignedType(fieldType)
 	}
 	switch {
-	case c.Name() == "deleted_at" && fieldType == "time.Time":
+	case c.isSoftDelete() && fieldType == "time.Time":
 		fieldType = "gorm.DeletedAt"
 	case coverable && c.needDefaultTag(c.defaultTagValue()):
 		fieldType = "*" + fieldType
 	case nullable && !strings.HasPrefix(fieldType, "*"):
-		if n, ok := c.Nullable(); ok && n {
+		if n, ok := c.Nullable(); ok && n && !(c.NullableExceptDefaulted && c.hasNonNullDefault()) {
 			fieldType = "*" + fieldType
 		}
 	}
@@ -66,12 +156,17 @@ func (c *Column) ToField(nullable, coverable, signable bool) *Field {
 		comment = c
 	}
 
+	tag := c.buildGormTag()
+	if c.jsonSerialized(fieldType) {
+		tag.Set(field.TagKeyGormSerializer, "json")
+	}
+
 	return &Field{
 		Name:             c.Name(),
 		Type:             fieldType,
 		ColumnName:       c.Name(),
 		MultilineComment: c.multilineComment(),
-		GORMTag:          c.buildGormTag(),
+		GORMTag:          tag,
 		Tag:              map[string]string{field.TagKeyJson: c.jsonTagNS(c.Name())},
 		ColumnComment:    comment,
 		Column:           c,
@@ -89,15 +184,21 @@ func (c *Column) buildGormTag() field.GormTag {
 		field.TagKeyGormType:   []string{c.columnType()},
 	}
 	isPriKey, ok := c.PrimaryKey()
-	isValidPriKey := ok && isPriKey
+	isValidPriKey := ok && isPriKey && !c.View
 	if isValidPriKey {
 		tag.Set(field.TagKeyGormPrimaryKey, "")
+		if priority := c.primaryKeyPriority(); c.PrimaryKeyPriority && priority > 0 {
+			tag.Set(field.TagKeyGormPriority, strconv.Itoa(int(priority)))
+		}
 		if at, ok := c.AutoIncrement(); ok {
 			tag.Set(field.TagKeyGormAutoIncrement, fmt.Sprintf("%t", at))
 		}
 	} else if n, ok := c.Nullable(); ok && !n {
 		tag.Set(field.TagKeyGormNotNull, "")
 	}
+	if c.Generated { // computed by database, never written on create and update
+		tag.Set(field.TagKeyGormReadOnly, "")
+	}
 
 	// Create a copy of indexes and sort by name to ensure consistent order
 	indexes := make([]*Index, len(c.Indexes))
@@ -113,10 +214,16 @@ func (c *Column) buildGormTag() field.GormTag {
 		if pk, _ := idx.PrimaryKey(); pk { //ignore PrimaryKey
 			continue
 		}
+		if !idx.Visible && !c.InvisibleIndexes { // AutoMigrate would recreate it as visible index
+			continue
+		}
+		if c.WithoutIndexTag {
+			continue
+		}
 		if uniq, _ := idx.Unique(); uniq {
-			tag.Append(field.TagKeyGormUniqueIndex, fmt.Sprintf("%s,priority:%d", idx.Name(), idx.Priority))
+			tag.Append(field.TagKeyGormUniqueIndex, idx.tagValue())
 		} else {
-			tag.Append(field.TagKeyGormIndex, fmt.Sprintf("%s,priority:%d", idx.Name(), idx.Priority))
+			tag.Append(field.TagKeyGormIndex, idx.tagValue())
 		}
 	}
 
@@ -128,14 +235,50 @@ func (c *Column) buildGormTag() field.GormTag {
 		tag.Set(field.TagKeyGormDefault, dtValue)
 	}
 	if comment, ok := c.Comment(); ok && comment != "" {
-		if c.multilineComment() {
-			comment = strings.ReplaceAll(comment, "\n", "\\n")
-		}
-		tag.Set(field.TagKeyGormComment, comment)
+		tag.Set(field.TagKeyGormComment, tagCommentReplacer.Replace(comment))
 	}
 	return tag
 }
 
+// SetPrimaryKeyPriority tag priority of composite primary key columns when key order differs from column order,
+// gorm orders primary keys by fields otherwise
+func SetPrimaryKeyPriority(columns []*Column) {
+	var last int32
+	ordered := true
+	for _, c := range columns {
+		if priority := c.primaryKeyPriority(); priority > 0 {
+			ordered = ordered && priority > last
+			last = priority
+		}
+	}
+	for _, c := range columns {
+		c.PrimaryKeyPriority = !ordered && c.primaryKeyPriority() > 0
+	}
+}
+
+// primaryKeyPriority position of column in composite primary key, 0 when primary key is not composite or unknown
+func (c *Column) primaryKeyPriority() int32 {
+	for _, idx := range c.Indexes {
+		if idx == nil {
+			continue
+		}
+		if pk, _ := idx.PrimaryKey(); pk && len(idx.Columns()) > 1 {
+			return idx.Priority
+		}
+	}
+	return 0
+}
+
+// tagCommentReplacer escape comment so it can be placed in a quoted struct tag value,
+// applied with or without FieldWithComment: quotes and backticks left as is break the tag or the generated file
+var tagCommentReplacer = strings.NewReplacer(
+	"\\", "\\\\",
+	"\"", "\\\"",
+	"\n", "\\n",
+	"\r", "\\r",
+	"`", "\\x60",
+)
+
 // needDefaultTag check if default tag needed
 func (c *Column) needDefaultTag(defaultTagValue string) bool {
 	if defaultTagValue == "" {
@@ -169,9 +312,116 @@ func (c *Column) defaultTagValue() string {
 	if value == "" {
 		return "''"
 	}
+	if c.QuoteDefault && c.isStringDefault(value) {
+		return tagCommentReplacer.Replace("'" + strings.ReplaceAll(value, "'", "''") + "'")
+	}
 	return value
 }
 
+// hasNonNullDefault check if column has default value other than NULL, e.g. NULL::character varying of postgres
+func (c *Column) hasNonNullDefault() bool {
+	value, ok := c.DefaultValue()
+	return ok && !strings.HasPrefix(strings.ToUpper(value), "NULL")
+}
+
+// defaultFuncReg default value of functions and expressions, e.g. now(), uuid_generate_v4(), (now() + '1 day'::interval)
+var defaultFuncReg = regexp.MustCompile(`^\(|\w\(`)
+
+// isStringDefault check if default value of string column is a plain string literal, which is not quoted yet
+// and neither a function nor a keyword, e.g. CURRENT_TIMESTAMP or NULL
+func (c *Column) isStringDefault(value string) bool {
+	if strings.TrimPrefix(c.GetDataType(), "*") != "string" || strings.HasPrefix(value, "'") || defaultFuncReg.MatchString(value) {
+		return false
+	}
+	switch strings.ToUpper(value) {
+	case "NULL", "CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME", "LOCALTIME", "LOCALTIMESTAMP", "CURRENT_USER":
+		return false
+	}
+	return true
+}
+
+// isSoftDelete check if column is soft delete column,
+// column of custom name known to be not null is excluded while deleted_at is matched whatever its nullability
+func (c *Column) isSoftDelete() bool {
+	names := c.softDelete
+	if len(names) == 0 {
+		names = []string{DefaultSoftDeleteField}
+	}
+	for _, name := range names {
+		if c.Name() != name {
+			continue
+		}
+		n, ok := c.Nullable()
+		return name == DefaultSoftDeleteField || !ok || n
+	}
+	return false
+}
+
+func (c *Column) unsigned() bool {
+	return c.Unsigned || strings.Contains(strings.ToLower(c.columnType()), "unsigned")
+}
+
+// unsignedTypes unsigned go type of integer columns with default data type mapping
+var unsignedTypes = map[string]string{
+	"tinyint":   "uint8",
+	"smallint":  "uint16",
+	"mediumint": "uint32",
+	"int":       "uint32",
+	"integer":   "uint32",
+	"bigint":    "uint64",
+}
+
+// unsignedType unsigned variant of fieldType, e.g. tinyint unsigned => uint8
+func (c *Column) unsignedType(fieldType string) string {
+	name := strings.ToLower(c.DatabaseTypeName())
+	if typ, ok := unsignedTypes[name]; ok && fieldType == dataType.Get(name, c.columnType()) {
+		return typ
+	}
+	return "u" + fieldType
+}
+
+// preciseIntegerTypes go type of the same width of integer columns, int2/int4/int8 are type names of postgres
+var preciseIntegerTypes = map[string]string{
+	"tinyint":   "int8",
+	"smallint":  "int16",
+	"int2":      "int16",
+	"mediumint": "int32",
+	"int":       "int32",
+	"integer":   "int32",
+	"int4":      "int32",
+	"bigint":    "int64",
+	"int8":      "int64",
+}
+
+// preciseIntegerType go type of the same width of integer column, width is read from column type since scan type is coarse
+func (c *Column) preciseIntegerType() (fieldType string, ok bool) {
+	fieldType, ok = preciseIntegerTypes[strings.ToLower(c.DatabaseTypeName())]
+	if !ok {
+		return "", false
+	}
+	if c.TinyIntAsBool && strings.HasPrefix(strings.ToLower(strings.TrimSpace(c.columnType())), "tinyint(1)") {
+		return "bool", true
+	}
+	if c.unsigned() || (c.TinyIntUnsigned && fieldType == "int8") {
+		return "u" + fieldType, true
+	}
+	return fieldType, true
+}
+
+// isJSON check if column is json or jsonb
+func (c *Column) isJSON() bool {
+	switch strings.ToLower(c.DatabaseTypeName()) {
+	case "json", "jsonb":
+		return true
+	}
+	return false
+}
+
+// isDate check if column stores date without time of day, set DateType only for dialects whose DATE is date-only
+func (c *Column) isDate() bool {
+	return strings.EqualFold(c.DatabaseTypeName(), "date")
+}
+
 func (c *Column) columnType() (v string) {
 	if cl, ok := c.ColumnType.ColumnType(); ok {
 		return cl
@@ -0,0 +1,45 @@
+package model
+
+import "sync"
+
+// ColumnAttr column attributes read from catalog of db, which ColumnType of gorm does not expose
+type ColumnAttr struct {
+	Ordinal   int  // ordinal position of column declared in table
+	Unsigned  bool // unsigned integer column of mysql
+	Generated bool // generated (computed) column of mysql and postgres
+}
+
+// ColumnAttrCache column attributes cache keyed by schema.table, safe for concurrent use
+type ColumnAttrCache struct {
+	mu     sync.RWMutex
+	tables map[string]map[string]ColumnAttr
+}
+
+// NewColumnAttrCache create column attributes cache
+func NewColumnAttrCache() *ColumnAttrCache {
+	return &ColumnAttrCache{tables: make(map[string]map[string]ColumnAttr)}
+}
+
+// Get get column attributes of table keyed by column name, ok is false when table is not cached
+func (c *ColumnAttrCache) Get(schemaName, tableName string) (attrs map[string]ColumnAttr, ok bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	attrs, ok = c.tables[schemaName+"."+tableName]
+	return attrs, ok
+}
+
+// Set set column attributes of table
+func (c *ColumnAttrCache) Set(schemaName, tableName string, attrs map[string]ColumnAttr) {
+	if c == nil {
+		return
+	}
+	if attrs == nil {
+		attrs = make(map[string]ColumnAttr)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tables[schemaName+"."+tableName] = attrs
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Enum go type generated for enum column
+type Enum struct {
+	TypeName string
+	Values   []EnumValue
+}
+
+// EnumValue enum constant
+type EnumValue struct {
+	Name  string
+	Value string
+}
+
+// NewEnum build enum type with one exported constant for each value, e.g. UserStatus + south-east => UserStatusSouthEast
+func NewEnum(typeName string, values []string) *Enum {
+	enum := &Enum{TypeName: typeName, Values: make([]EnumValue, 0, len(values))}
+	names := make(map[string]bool, len(values))
+	for _, value := range values {
+		name := typeName + enumIdentifier(value)
+		for i := 2; names[name]; i++ { // e.g. it's and it-s are both ItS
+			name = typeName + enumIdentifier(value) + strconv.Itoa(i)
+		}
+		names[name] = true
+		enum.Values = append(enum.Values, EnumValue{Name: name, Value: value})
+	}
+	return enum
+}
+
+// enumIdentifier convert enum value to exported identifier, letters and digits are kept only
+func enumIdentifier(value string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(value, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	switch {
+	case b.Len() == 0:
+		return "Empty"
+	case unicode.IsDigit(rune(b.String()[0])):
+		return "V" + b.String()
+	}
+	return b.String()
+}
+
+// ParseEnumValues parse values from enum column type, e.g. enum('a','b,c') => [a b,c]
+// quoted values can contain commas, quotes are escaped by doubling or backslash
+func ParseEnumValues(columnType string) (values []string, ok bool) {
+	s := strings.TrimSpace(columnType)
+	if len(s) < len("enum()") || !strings.EqualFold(s[:5], "enum(") || s[len(s)-1] != ')' {
+		return nil, false
+	}
+	s = s[5 : len(s)-1]
+
+	for i := 0; i < len(s); {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i == len(s) || s[i] != '\'' {
+			return nil, false
+		}
+
+		var value strings.Builder
+	quoted:
+		for i++; ; { // skip opening quote
+			switch {
+			case i >= len(s):
+				return nil, false
+			case s[i] == '\\' && i+1 < len(s):
+				value.WriteByte(s[i+1])
+				i += 2
+			case s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+				value.WriteByte('\'')
+				i += 2
+			case s[i] == '\'':
+				i++ // skip closing quote
+				break quoted
+			default:
+				value.WriteByte(s[i])
+				i++
+			}
+		}
+		values = append(values, value.String())
+
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i < len(s) {
+			if s[i] != ',' || i == len(s)-1 {
+				return nil, false
+			}
+			i++
+		}
+	}
+	return values, len(values) > 0
+}
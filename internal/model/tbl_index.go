@@ -1,11 +1,46 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
 
 // Index table index info
 type Index struct {
 	gorm.Index
-	Priority int32 `gorm:"column:SEQ_IN_INDEX"`
+	Priority  int32  `gorm:"column:SEQ_IN_INDEX"`
+	Sort      string `gorm:"-"` // ASC or DESC, empty when unknown
+	Collation string `gorm:"-"` // index column collation, empty when same as column
+	Visible   bool   `gorm:"-"` // index is used by optimizer, false for invisible index of mysql 8
+	Length    int32  `gorm:"-"` // prefix length of column in index, e.g. 10 of mysql INDEX (name(10)), 0 when whole column is indexed
+}
+
+// tagValue build index tag value, e.g. idx_name,priority:1,length:10,sort:desc
+func (idx *Index) tagValue() string {
+	value := fmt.Sprintf("%s,priority:%d", idx.Name(), idx.Priority)
+	if idx.Length > 0 {
+		value += fmt.Sprintf(",length:%d", idx.Length)
+	}
+	if strings.EqualFold(idx.Sort, "DESC") {
+		value += ",sort:desc"
+	}
+	if idx.Collation != "" {
+		value += ",collate:" + idx.Collation
+	}
+	return value
+}
+
+// IndexColumn index column metadata read from database
+type IndexColumn struct {
+	Sequence  int32  // 1-based position of column in index
+	Sort      string // ASC or DESC
+	Collation string // collation used by index, empty when same as column
+	Invisible bool   // index is invisible to optimizer, e.g. mysql 8 invisible index
+	Length    int32  // prefix length of indexed string column, 0 when whole column is indexed
 }
 
 // GroupByColumn group columns
@@ -23,15 +58,17 @@ func GroupByColumn(indexList []gorm.Index) map[string][]*Index {
 			columnIndexMap[col] = append(columnIndexMap[col], &Index{
 				Index:    idx,
 				Priority: int32(i + 1),
+				Visible:  true,
 			})
 		}
 	}
 	return columnIndexMap
 }
 
-// GroupByColumnWithSequences group columns with correct sequences from database metadata
-// indexColumnSeq: map[indexName]map[columnName]sequence (1-based)
-func GroupByColumnWithSequences(indexList []gorm.Index, indexColumnSeq map[string]map[string]int32) map[string][]*Index {
+// GroupByColumnWithSequences group columns with correct sequences from database metadata,
+// indexes of every column are sorted by name then priority to keep generated tags stable
+// indexColumns: map[indexName]map[columnName]IndexColumn
+func GroupByColumnWithSequences(indexList []gorm.Index, indexColumns map[string]map[string]IndexColumn) map[string][]*Index {
 	columnIndexMap := make(map[string][]*Index, len(indexList))
 	if len(indexList) == 0 {
 		return columnIndexMap
@@ -41,40 +78,66 @@ func GroupByColumnWithSequences(indexList []gorm.Index, indexColumnSeq map[strin
 		if idx == nil {
 			continue
 		}
-		indexName := idx.Name()
-		columnSeqMap, hasSeq := indexColumnSeq[indexName]
+		columnMetas := indexColumns[idx.Name()]
 
-		for _, col := range idx.Columns() {
-			var priority int32
-			if hasSeq {
-				// Use sequence from database metadata if available
-				if seq, ok := columnSeqMap[col]; ok {
-					priority = seq
-				} else {
-					// Fallback: use position in Columns() array if column not found in metadata
-					// This shouldn't happen, but provides a safety net
-					for i, c := range idx.Columns() {
-						if c == col {
-							priority = int32(i + 1)
-							break
-						}
-					}
-				}
-			} else {
-				// Fallback to original behavior if no sequence data available
-				for i, c := range idx.Columns() {
-					if c == col {
-						priority = int32(i + 1)
-						break
-					}
+		for i, col := range idx.Columns() {
+			index := &Index{Index: idx, Priority: int32(i + 1), Visible: true}
+			// Use sequence from database metadata if available,
+			// fallback to position in Columns() array otherwise
+			if meta, ok := columnMetas[col]; ok {
+				if meta.Sequence > 0 {
+					index.Priority = meta.Sequence
 				}
+				index.Sort = meta.Sort
+				index.Collation = meta.Collation
+				index.Visible = !meta.Invisible
+				index.Length = meta.Length
 			}
-
-			columnIndexMap[col] = append(columnIndexMap[col], &Index{
-				Index:    idx,
-				Priority: priority,
-			})
+			columnIndexMap[col] = append(columnIndexMap[col], index)
 		}
 	}
+	for _, indexes := range columnIndexMap {
+		sort.SliceStable(indexes, func(i, j int) bool {
+			if indexes[i].Name() != indexes[j].Name() {
+				return indexes[i].Name() < indexes[j].Name()
+			}
+			return indexes[i].Priority < indexes[j].Priority
+		})
+	}
 	return columnIndexMap
 }
+
+// IndexColumnCache index column metadata cache keyed by schema.table, safe for concurrent use
+type IndexColumnCache struct {
+	mu     sync.RWMutex
+	tables map[string]map[string]map[string]IndexColumn
+}
+
+// NewIndexColumnCache create index column metadata cache
+func NewIndexColumnCache() *IndexColumnCache {
+	return &IndexColumnCache{tables: make(map[string]map[string]map[string]IndexColumn)}
+}
+
+// Get get index column metadata of table, ok is false when table is not cached
+func (c *IndexColumnCache) Get(schemaName, tableName string) (indexColumns map[string]map[string]IndexColumn, ok bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	indexColumns, ok = c.tables[schemaName+"."+tableName]
+	return indexColumns, ok
+}
+
+// Set set index column metadata of table
+func (c *IndexColumnCache) Set(schemaName, tableName string, indexColumns map[string]map[string]IndexColumn) {
+	if c == nil {
+		return
+	}
+	if indexColumns == nil {
+		indexColumns = make(map[string]map[string]IndexColumn)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tables[schemaName+"."+tableName] = indexColumns
+}
@@ -0,0 +1,36 @@
+package model
+
+import "gorm.io/gorm"
+
+// ITableInfo table info interface
+type ITableInfo interface {
+	GetTableColumns(schemaName string, tableName string) (result []*Column, err error)
+
+	GetTableIndex(schemaName string, tableName string) (indexes []gorm.Index, err error)
+}
+
+// IForeignKeyInfo table info providing foreign keys, optional for ITableInfo implementations
+type IForeignKeyInfo interface {
+	GetTableForeignKeys(schemaName string, tableName string) (foreignKeys []*ForeignKey, err error)
+}
+
+// ForeignKey table foreign key info
+type ForeignKey struct {
+	Name              string   // constraint name
+	Columns           []string // columns in table, ordered by position in constraint
+	ReferencedSchema  string   // schema of referenced table
+	ReferencedTable   string   // referenced table name
+	ReferencedColumns []string // referenced columns, in the same order as Columns
+	Unique            bool     // columns are backed by a unique index, means a has-one reverse relation
+}
+
+// ICheckConstraintInfo table info providing check constraints, optional for ITableInfo implementations
+type ICheckConstraintInfo interface {
+	GetTableCheckConstraints(schemaName string, tableName string) (checks []*CheckConstraint, err error)
+}
+
+// CheckConstraint table check constraint info
+type CheckConstraint struct {
+	Name       string // constraint name
+	Expression string // check expression as stored in db, e.g. (age >= 0)
+}
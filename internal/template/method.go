@@ -13,11 +13,11 @@ func ({{.S}} {{.TargetStruct}}Do){{.FuncSign}}{
 
 	{{if .HasNeedNewResult}}result ={{if .ResultData.IsMap}}make{{else}}new{{end}}({{if ne .ResultData.Package ""}}{{.ResultData.Package}}.{{end}}{{.ResultData.Type}}){{end}}
 	{{if .ReturnSQLResult}}stmt := {{.S}}.UnderlyingDB().Statement
-	result,{{if .ReturnError}}err{{else}}_{{end}} = stmt.ConnPool.ExecContext(stmt.Context,generateSQL.String(){{if .HasSQLData}},params...{{end}}) // ignore_security_alert
-	{{else if .ReturnSQLRow}}row = {{.S}}.UnderlyingDB().Raw(generateSQL.String(){{if .HasSQLData}},params...{{end}}).Row() // ignore_security_alert
-	{{else if .ReturnSQLRows}}rows,{{if .ReturnError}}err{{else}}_{{end}} = {{.S}}.UnderlyingDB().Raw(generateSQL.String(){{if .HasSQLData}},params...{{end}}).Rows() // ignore_security_alert
+	result,{{if .ReturnError}}err{{else}}_{{end}} = stmt.ConnPool.ExecContext({{if .ContextFirstArg}}ctx{{else}}stmt.Context{{end}},generateSQL.String(){{if .HasSQLData}},params...{{end}}) // ignore_security_alert
+	{{else if .ReturnSQLRow}}row = {{.S}}.UnderlyingDB(){{if .ContextFirstArg}}.WithContext(ctx){{end}}.Raw(generateSQL.String(){{if .HasSQLData}},params...{{end}}).Row() // ignore_security_alert
+	{{else if .ReturnSQLRows}}rows,{{if .ReturnError}}err{{else}}_{{end}} = {{.S}}.UnderlyingDB(){{if .ContextFirstArg}}.WithContext(ctx){{end}}.Raw(generateSQL.String(){{if .HasSQLData}},params...{{end}}).Rows() // ignore_security_alert
 	{{else}}var executeSQL *gorm.DB
-	executeSQL = {{.S}}.UnderlyingDB().{{.GormOption}}(generateSQL.String(){{if .HasSQLData}},params...{{end}}){{if not .ResultData.IsNull}}.{{.GormRunMethodName}}({{if .HasGotPoint}}&{{end}}{{.ResultData.Name}}){{end}}  // ignore_security_alert
+	executeSQL = {{.S}}.UnderlyingDB(){{if .ContextFirstArg}}.WithContext(ctx){{end}}.{{.GormOption}}(generateSQL.String(){{if .HasSQLData}},params...{{end}}){{if not .ResultData.IsNull}}.{{.GormRunMethodName}}({{if .HasGotPoint}}&{{end}}{{.ResultData.Name}}){{end}}  // ignore_security_alert
 	{{if .ReturnRowsAffected}}rowsAffected = executeSQL.RowsAffected
 	{{end}}{{if .ReturnError}}err = executeSQL.Error
 	{{end}}{{if .ReturnNothing}}_ = executeSQL
@@ -35,10 +35,144 @@ func ({{.S}} *{{.QueryStructName}}Do) withDO(do gen.Dao) {{.ReturnObject}} {
 	_r.IWithDO = gen.WithDOFunc[{{.ReturnObject}}]({{.S}}.withDO)
 	return _r
 }
+{{if and (not .BatchCreateWithAssociations) (not .ReadOnly)}}
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
+func ({{.S}} *{{.QueryStructName}}Do) CreateInBatches(values []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int) error {
+	return {{.S}}.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
+}
+{{end}}`
+
+// UpsertMethod upsert method, conflicting rows are updated by gorm's clause.OnConflict
+const UpsertMethod = `
+// UpsertByColumns create values, rows conflicting on conflictCols are updated with updateCols instead,
+// conflicting rows are kept unchanged when updateCols is empty.
+// mysql ignores conflictCols and detects conflict by primary key and unique indexes (ON DUPLICATE KEY UPDATE)
+func ({{.S}} {{.QueryStructName}}Do) UpsertByColumns({{if .ContextFirstArg}}ctx context.Context, {{end}}conflictCols []field.Expr, updateCols []field.Expr, values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	onConflict := clause.OnConflict{Columns: make([]clause.Column, len(conflictCols))}
+	for i, col := range conflictCols {
+		onConflict.Columns[i] = clause.Column{Name: col.ColumnName().String()}
+	}
+	if len(updateCols) == 0 {
+		onConflict.DoNothing = true
+	} else {
+		columns := make([]string, len(updateCols))
+		for i, col := range updateCols {
+			columns[i] = col.ColumnName().String()
+		}
+		onConflict.DoUpdates = clause.AssignmentColumns(columns)
+	}
+	return {{.S}}.Clauses(onConflict).Create({{if .ContextFirstArg}}ctx, {{end}}values...)
+}
+
+// Upsert create values, rows conflicting on conflictColumns are updated by doUpdates instead,
+// conflicting rows are kept unchanged when doUpdates is empty.
+{{- with .UpsertConflictColumns}}
+// conflictColumns default to{{range .}} {{.}}{{end}} when empty.{{end}}
+// mysql ignores conflictColumns and detects conflict by primary key and unique indexes (ON DUPLICATE KEY UPDATE)
+func ({{.S}} {{.QueryStructName}}Do) Upsert({{if .ContextFirstArg}}ctx context.Context, {{end}}conflictColumns []clause.Column, doUpdates clause.Set, values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	{{- with .UpsertConflictColumns}}
+	if len(conflictColumns) == 0 {
+		conflictColumns = []clause.Column{ {{- range .}}{Name: {{printf "%q" .}}}, {{end -}} }
+	}
+	{{- end}}
+	onConflict := clause.OnConflict{Columns: conflictColumns, DoUpdates: doUpdates, DoNothing: len(doUpdates) == 0}
+	return {{.S}}.Clauses(onConflict).Create({{if .ContextFirstArg}}ctx, {{end}}values...)
+}
 `
 
+// BulkInsertMethod bulk insert method, records are created with current db handle to join ongoing transaction
+const BulkInsertMethod = `
+// BulkInsert create records in batches of batchSize, batchSize falls back to {{.DefaultBatchSize}} when it is 0.
+// It uses the current db handle so records are created within the ongoing transaction,
+{{- if not .BatchCreateWithAssociations}} associations of records are not saved,{{end}}
+// return total rows affected and the first error encountered
+func ({{.S}} {{.QueryStructName}}Do) BulkInsert({{if .ContextFirstArg}}ctx context.Context, {{end}}records []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int) (rowsAffected int64, err error) {
+	if batchSize == 0 {
+		batchSize = {{.DefaultBatchSize}}
+	}
+	if batchSize < 0 {
+		return 0, fmt.Errorf("invalid batch size %d, it must be greater than 0", batchSize)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	result := {{.S}}.DO.UnderlyingDB(){{if .ContextFirstArg}}.WithContext(ctx){{end}}{{if not .BatchCreateWithAssociations}}.Omit(clause.Associations){{end}}.CreateInBatches(records, batchSize)
+	return result.RowsAffected, result.Error
+}
+`
+
+// OptimisticLockMethod update method guarded by version column of optimistic locking
+const OptimisticLockMethod = `
+{{with .OptimisticLock -}}
+// UpdateWithVersion update columns of records whose {{.Column}} equals version and increment {{.Column}} by 1,
+// return rows affected, gen.ErrStaleVersion is returned when no record matches, e.g. record is updated concurrently
+func ({{$.S}} {{$.QueryStructName}}Do) UpdateWithVersion({{if $.ContextFirstArg}}ctx context.Context, {{end}}version {{.Type}}, columns ...field.AssignExpr) (rowsAffected int64, err error) {
+	versionField := field.New{{.GenType}}({{$.S}}.TableName(), {{printf "%q" .Column}})
+	info, err := {{$.S}}.Where(versionField.Eq(version)).UpdateSimple({{if $.ContextFirstArg}}ctx, {{end}}append(columns, versionField.Add(1))...)
+	if err != nil {
+		return 0, err
+	}
+	if info.RowsAffected == 0 {
+		return 0, gen.ErrStaleVersion
+	}
+	return info.RowsAffected, nil
+}
+{{end}}
+`
+
+// IndexLookupMethod lookup methods of columns backing unique single-column index
+const IndexLookupMethod = `
+{{range .IndexLookups}}
+// {{.Name}} filter records whose {{.Column}} equals {{.Param}}, {{.Column}} is backed by unique index {{.Index}}
+func ({{$.S}} {{$.QueryStructName}}Do) {{.Name}}({{.Param}} {{.Type}}) {{$.ReturnObject}} {
+	return {{$.S}}.Where(field.New{{.GenType}}({{$.S}}.TableName(), {{printf "%q" .Column}}).Eq({{if .Convert}}{{.Convert}}({{.Param}}){{else}}{{.Param}}{{end}}))
+}
+{{end}}
+`
+
+// JSONPathMethod condition methods of values extracted from json columns
+const JSONPathMethod = `
+{{range .JSONPathConds}}
+// {{.Name}} filter records whose {{.Path}} of json column {{.Column}} equals value
+func ({{$.S}} {{$.QueryStructName}}Do) {{.Name}}(value {{.Type}}) {{$.ReturnObject}} {
+	return {{$.S}}.Where(gen.Cond(clause.Expr{SQL: {{printf "%q" .SQL}}, Vars: []interface{}{clause.Column{Table: {{$.S}}.TableName(), Name: {{printf "%q" .Column}}}, value}})...)
+}
+{{end}}
+`
+
+// AssociationMethod association helpers of has-many relations, count and clear associations of a model by gorm's Association
+const AssociationMethod = `
+{{range .HasManyRelations}}
+// CountAssociation{{.Name}} count {{.Name}} associated with m
+func ({{$.S}} {{$.QueryStructName}}Do) CountAssociation{{.Name}}({{if $.ContextFirstArg}}ctx context.Context, {{end}}m *{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) (int64, error) {
+	association := {{$.S}}.DO.UnderlyingDB(){{if $.ContextFirstArg}}.WithContext(ctx){{end}}.Model(m).Association("{{.Name}}")
+	if association.Error != nil {
+		return 0, association.Error
+	}
+	count := association.Count()
+	return count, association.Error
+}
+{{if not $.ReadOnly}}
+// Clear{{.Name}} remove references between m and its {{.Name}}, associated records are not deleted
+func ({{$.S}} {{$.QueryStructName}}Do) Clear{{.Name}}({{if $.ContextFirstArg}}ctx context.Context, {{end}}m *{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) error {
+	return {{$.S}}.DO.UnderlyingDB(){{if $.ContextFirstArg}}.WithContext(ctx){{end}}.Model(m).Association("{{.Name}}").Clear()
+}
+{{end}}
+{{- end}}
+`
+
+// contextArgs template variables of ctx parameter added to DAO methods when ContextFirstArg is enabled
+const contextArgs = `{{$ctx := ""}}{{$ctxArg := ""}}{{$c := ""}}{{$do := print .S ".DO"}}
+{{- if .ContextFirstArg}}{{$ctx = "ctx context.Context"}}{{$ctxArg = "ctx context.Context, "}}{{$c = "ctx"}}{{$do = print .S ".DO.WithContext(ctx)"}}{{end}}`
+
 // CRUDMethod CRUD method
-const CRUDMethod = `
+const CRUDMethod = contextArgs + `
 func ({{.S}} {{.QueryStructName}}Do) Debug() {{.ReturnObject}} {
 	return {{.S}}.withDO({{.S}}.DO.Debug())
 }
@@ -131,66 +265,79 @@ func ({{.S}} {{.QueryStructName}}Do) Unscoped() {{.ReturnObject}} {
 	return {{.S}}.withDO({{.S}}.DO.Unscoped())
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Create(values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error {
+{{if not .ReadOnly -}}
+func ({{.S}} {{.QueryStructName}}Do) Create({{$ctxArg}}values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error {
 	if len(values) == 0 {
 		return nil
 	}
-	return {{.S}}.DO.Create(values)
+	return {{$do}}.Create(values)
 }
 
-func ({{.S}} {{.QueryStructName}}Do) CreateInBatches(values []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int) error {
-	return {{.S}}.DO.CreateInBatches(values, batchSize)
+{{if not .BatchCreateWithAssociations -}}
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
+func ({{.S}} {{.QueryStructName}}Do) CreateInBatches({{$ctxArg}}values []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int) error {
+	return {{.S}}.DO.UnderlyingDB(){{if .ContextFirstArg}}.WithContext(ctx){{end}}.Omit(clause.Associations).CreateInBatches(values, batchSize).Error
+}
+{{- else -}}
+func ({{.S}} {{.QueryStructName}}Do) CreateInBatches({{$ctxArg}}values []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int) error {
+	return {{$do}}.CreateInBatches(values, batchSize)
 }
+{{- end}}
 
+{{if .WithoutPrimaryKeyMethods -}}
+// Save is not generated: table <{{.TableName}}> has no primary key to identify records to update
+{{- else -}}
 // Save : !!! underlying implementation is different with GORM
 // The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
-func ({{.S}} {{.QueryStructName}}Do) Save(values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error {
+func ({{.S}} {{.QueryStructName}}Do) Save({{$ctxArg}}values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error {
 	if len(values) == 0 {
 		return nil
 	}
-	return {{.S}}.DO.Save(values)
+	return {{$do}}.Save(values)
 }
+{{- end}}
 
-func ({{.S}} {{.QueryStructName}}Do) First() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
-	if result, err := {{.S}}.DO.First(); err != nil {
+{{end -}}
+func ({{.S}} {{.QueryStructName}}Do) First({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
+	if result, err := {{$do}}.First(); err != nil {
 		return nil, err
 	} else {
 		return result.(*{{.StructInfo.Package}}.{{.StructInfo.Type}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Take() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
-	if result, err := {{.S}}.DO.Take(); err != nil {
+func ({{.S}} {{.QueryStructName}}Do) Take({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
+	if result, err := {{$do}}.Take(); err != nil {
 		return nil, err
 	} else {
 		return result.(*{{.StructInfo.Package}}.{{.StructInfo.Type}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Last() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
-	if result, err := {{.S}}.DO.Last(); err != nil {
+func ({{.S}} {{.QueryStructName}}Do) Last({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
+	if result, err := {{$do}}.Last(); err != nil {
 		return nil, err
 	} else {
 		return result.(*{{.StructInfo.Package}}.{{.StructInfo.Type}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Find() ([]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
-	result, err := {{.S}}.DO.Find()
+func ({{.S}} {{.QueryStructName}}Do) Find({{$ctx}}) ([]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
+	result, err := {{$do}}.Find()
 	return result.([]*{{.StructInfo.Package}}.{{.StructInfo.Type}}), err
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, err error) {
+func ({{.S}} {{.QueryStructName}}Do) FindInBatch({{$ctxArg}}batchSize int, fc func(tx gen.Dao, batch int) error) (results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, err error) {
 	buf := make([]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, 0, batchSize)
-	err = {{.S}}.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+	err = {{$do}}.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
 		defer func() { results = append(results, buf...) }()
 		return fc(tx, batch)
 	})
 	return results, err
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FindInBatches(result *[]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int, fc func(tx gen.Dao, batch int) error) error {
-	return {{.S}}.DO.FindInBatches(result, batchSize, fc)
+func ({{.S}} {{.QueryStructName}}Do) FindInBatches({{$ctxArg}}result *[]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return {{$do}}.FindInBatches(result, batchSize, fc)
 }
 
 func ({{.S}} {{.QueryStructName}}Do) Attrs(attrs ...field.AssignExpr) {{.ReturnObject}} {
@@ -215,24 +362,52 @@ func ({{.S}} {{.QueryStructName}}Do) Preload(fields ...field.RelationField) {{.R
 	return &{{.S}}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FirstOrInit() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
-	if result, err := {{.S}}.DO.FirstOrInit(); err != nil {
+func ({{.S}} {{.QueryStructName}}Do) FirstOrInit({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
+	if result, err := {{$do}}.FirstOrInit(); err != nil {
 		return nil, err
 	} else {
 		return result.(*{{.StructInfo.Package}}.{{.StructInfo.Type}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FirstOrCreate() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
-	if result, err := {{.S}}.DO.FirstOrCreate(); err != nil {
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func ({{.S}} {{.QueryStructName}}Do) FirstOrInitWith({{$ctxArg}}seed *{{.StructInfo.Package}}.{{.StructInfo.Type}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
+	if seed == nil {
+		seed = new({{.StructInfo.Package}}.{{.StructInfo.Type}})
+	}
+	if err := {{$do}}.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+{{if not .ReadOnly -}}
+{{if .WithoutPrimaryKeyMethods -}}
+// FirstOrCreate is not generated: table <{{.TableName}}> has no primary key to identify the found or created record
+{{- else -}}
+func ({{.S}} {{.QueryStructName}}Do) FirstOrCreate({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
+	if result, err := {{$do}}.FirstOrCreate(); err != nil {
 		return nil, err
 	} else {
 		return result.(*{{.StructInfo.Package}}.{{.StructInfo.Type}}), nil
 	}
 }
 
-func ({{.S}} {{.QueryStructName}}Do) FindByPage(offset int, limit int) (result []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, count int64, err error) {
-	result, err = {{.S}}.Offset(offset).Limit(limit).Find()
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func ({{.S}} {{.QueryStructName}}Do) FirstOrCreateWith({{$ctxArg}}seed *{{.StructInfo.Package}}.{{.StructInfo.Type}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error) {
+	if seed == nil {
+		seed = new({{.StructInfo.Package}}.{{.StructInfo.Type}})
+	}
+	if err := {{$do}}.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+{{- end}}
+
+{{end -}}
+func ({{.S}} {{.QueryStructName}}Do) FindByPage({{$ctxArg}}offset int, limit int) (result []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, count int64, err error) {
+	result, err = {{.S}}.Offset(offset).Limit(limit).Find({{$c}})
 	if err != nil{
 		return
 	}
@@ -242,28 +417,95 @@ func ({{.S}} {{.QueryStructName}}Do) FindByPage(offset int, limit int) (result [
 		return
 	}
 
-	count, err = {{.S}}.Offset(-1).Limit(-1).Count()
+	count, err = {{.S}}.Offset(-1).Limit(-1).Count({{$c}})
 	return
 }
 
-func ({{.S}} {{.QueryStructName}}Do) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
-	count, err = {{.S}}.Count()
+func ({{.S}} {{.QueryStructName}}Do) FindPage({{$ctxArg}}offset int, limit int, conds ...gen.Condition) (result []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = {{.S}}.Where(conds...).Find({{$c}})
+		return result, int64(len(result)), err
+	}
+	return {{.S}}.Where(conds...).FindByPage({{$c}}{{if $c}}, {{end}}offset, limit)
+}
+
+func ({{.S}} {{.QueryStructName}}Do) CountBy({{$ctxArg}}conds ...gen.Condition) (count int64, err error) {
+	return {{.S}}.Where(conds...).Count({{$c}})
+}
+
+func ({{.S}} {{.QueryStructName}}Do) ScanByPage({{$ctxArg}}result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = {{.S}}.Count({{$c}})
 	if err != nil {
 		return
 	}
 
-	err = {{.S}}.Offset(offset).Limit(limit).Scan(result)
+	err = {{.S}}.Offset(offset).Limit(limit).Scan({{$c}}{{if $c}}, {{end}}result)
 	return
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Scan(result interface{}) (err error) {
-	return {{.S}}.DO.Scan(result)
+func ({{.S}} {{.QueryStructName}}Do) Scan({{$ctxArg}}result interface{}) (err error) {
+	return {{$do}}.Scan(result)
+}
+
+{{if not .ReadOnly -}}
+{{if .WithoutPrimaryKeyMethods -}}
+// Delete delete records matching conditions, deleting by models is rejected:
+// table <{{.TableName}}> has no primary key to identify records
+{{end -}}
+func ({{.S}} {{.QueryStructName}}Do) Delete({{$ctxArg}}models ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) (result gen.ResultInfo, err error) {
+	{{if .WithoutPrimaryKeyMethods -}}
+	if len(models) > 0 {
+		return result, gorm.ErrPrimaryKeyRequired
+	}
+	{{end -}}
+	return {{$do}}.Delete(models)
+}
+
+{{end -}}
+{{if .ContextFirstArg -}}
+func ({{.S}} {{.QueryStructName}}Do) Count(ctx context.Context) (count int64, err error) {
+	return {{$do}}.Count()
+}
+
+func ({{.S}} {{.QueryStructName}}Do) Pluck(ctx context.Context, column field.Expr, dest interface{}) error {
+	return {{$do}}.Pluck(column, dest)
+}
+
+func ({{.S}} {{.QueryStructName}}Do) Rows(ctx context.Context) (*sql.Rows, error) {
+	return {{$do}}.Rows()
+}
+
+func ({{.S}} {{.QueryStructName}}Do) Row(ctx context.Context) *sql.Row {
+	return {{$do}}.Row()
+}
+
+{{if not .ReadOnly -}}
+func ({{.S}} {{.QueryStructName}}Do) Update(ctx context.Context, column field.Expr, value interface{}) (info gen.ResultInfo, err error) {
+	return {{$do}}.Update(column, value)
+}
+
+func ({{.S}} {{.QueryStructName}}Do) UpdateSimple(ctx context.Context, columns ...field.AssignExpr) (info gen.ResultInfo, err error) {
+	return {{$do}}.UpdateSimple(columns...)
+}
+
+func ({{.S}} {{.QueryStructName}}Do) Updates(ctx context.Context, value interface{}) (info gen.ResultInfo, err error) {
+	return {{$do}}.Updates(value)
+}
+
+func ({{.S}} {{.QueryStructName}}Do) UpdateColumn(ctx context.Context, column field.Expr, value interface{}) (info gen.ResultInfo, err error) {
+	return {{$do}}.UpdateColumn(column, value)
+}
+
+func ({{.S}} {{.QueryStructName}}Do) UpdateColumnSimple(ctx context.Context, columns ...field.AssignExpr) (info gen.ResultInfo, err error) {
+	return {{$do}}.UpdateColumnSimple(columns...)
 }
 
-func ({{.S}} {{.QueryStructName}}Do) Delete(models ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) (result gen.ResultInfo, err error) {
-	return {{.S}}.DO.Delete(models)
+func ({{.S}} {{.QueryStructName}}Do) UpdateColumns(ctx context.Context, value interface{}) (info gen.ResultInfo, err error) {
+	return {{$do}}.UpdateColumns(value)
 }
 
+{{end -}}
+{{end -}}
 func ({{.S}} *{{.QueryStructName}}Do) withDO(do gen.Dao) (*{{.QueryStructName}}Do) {
 	{{.S}}.DO = *do.(*gen.DO)
 	return {{.S}}
@@ -1,7 +1,8 @@
 package template
 
 // Model used as a variable because it cannot load template file after packed, params still can pass file
-const Model = NotEditMark + `
+const Model = NotEditMark + `{{if .SchemaFingerprint}}// Schema fingerprint: {{.SchemaFingerprint}}
+{{end}}
 package {{.StructInfo.Package}}
 
 import (
@@ -14,21 +15,79 @@ import (
 	{{range .ImportPkgPaths}}{{.}} ` + "\n" + `{{end}}
 )
 
-{{if .TableName -}}const TableName{{.ModelStructName}} = "{{.TableName}}"{{- end}}
+{{if .TableName -}}const TableName{{.ModelStructName}} = "{{if .TableSchema}}{{.TableSchema}}.{{end}}{{.TableName}}"{{- end}}
 
 // {{.ModelStructName}} {{.StructComment}}
+{{- if .TableNameInComment}}
+//
+// {{.ModelStructName}} maps table {{.SourceTable}}
+{{- end}}
+{{- if .CheckConstraints}}
+//
+// Check constraints:
+{{range .CheckConstraints}}//   - {{.Name}}: {{.Expression}}
+{{end}}{{- else}}
+{{end -}}
 type {{.ModelStructName}} struct {
     {{range .Fields}}
-    {{if .MultilineComment -}}
+    {{if .Embedded -}}
+    {{.Type}}
+    {{- else if not .BaseEmbedded -}}
+    {{if and .DocComment .ColumnComment -}}
+	{{range .DocCommentLines}}//{{if .}} {{.}}{{end}}
+	{{end -}}
+    {{else if .MultilineComment -}}
 	/*
 {{.ColumnComment}}
     */
 	{{end -}}
     {{.Name}} {{.Type}} ` + "`{{.Tags}}` " +
-	"{{if not .MultilineComment}}{{if .ColumnComment}}// {{.ColumnComment}}{{end}}{{end}}" +
-	`{{end}}
+	"{{if not .DocComment}}{{if not .MultilineComment}}{{if .ColumnComment}}// {{.ColumnComment}}{{end}}{{end}}{{end}}" +
+	`{{- end}}{{end}}
 }
 
+{{range .Fields}}{{if .Enum}}{{$enum := .Enum}}
+// {{$enum.TypeName}} values of column {{.ColumnName}}
+type {{$enum.TypeName}} string
+
+const (
+	{{range $enum.Values}}{{.Name}} {{$enum.TypeName}} = {{printf "%q" .Value}}
+	{{end}}
+)
+
+// Valid check if v is one of {{$enum.TypeName}} values
+func (v {{$enum.TypeName}}) Valid() bool {
+	switch v {
+	case {{range $i, $v := $enum.Values}}{{if $i}}, {{end}}{{$v.Name}}{{end}}:
+		return true
+	}
+	return false
+}
+{{end}}{{end}}
+{{if .WithColumnNames}}
+// {{.ColumnNamesVar}} column names of table {{.TableName}}
+var {{.ColumnNamesVar}} = struct {
+	{{range .ColumnFields}}{{.Name}} string
+	{{end}}
+}{
+	{{range .ColumnFields}}{{.Name}}: {{printf "%q" .ColumnName}},
+	{{end}}
+}
+{{with .ColumnConsts}}
+// column names of table {{$.TableName}}
+const (
+	{{range .}}{{.Name}} = {{printf "%q" .Value}}
+	{{end}}
+)
+{{end}}{{end}}
+{{if .ModelInterface.Method}}{{with .PrimaryKeyFields}}
+// {{$.ModelInterface.Method}} primary key value of {{$.ModelStructName}}
+func ({{$.S}} *{{$.ModelStructName}}) {{$.ModelInterface.Method}}() interface{} {
+	{{if eq (len .) 1}}return {{$.S}}.{{(index . 0).Name}}{{else}}return []interface{}{ {{- range $i, $f := .}}{{if $i}}, {{end}}{{$.S}}.{{$f.Name}}{{end -}} }{{end}}
+}
+{{if $.ModelInterface.Interface}}
+var _ {{$.ModelInterface.Interface}} = (*{{$.ModelStructName}})(nil)
+{{end}}{{end}}{{end}}
 `
 
 // ModelMethod model struct DIY method
@@ -21,6 +21,9 @@ func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 // QueryMethod query method template
 const QueryMethod = `
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
+	{{if .DBResolver -}}
+	db = db.Clauses(dbresolver.Use({{printf "%q" .DBResolver}}))
+	{{end -}}
 	return &Query{
 		db: db,
 		{{range $name,$d :=.Data -}}
@@ -56,7 +59,18 @@ func (q *Query) WriteDB() *Query {
 	return q.clone(q.db.Clauses(dbresolver.Write))
 }
 
+// WithTx use externally managed transaction tx, committing or rolling it back is up to caller
+func (q *Query) WithTx(tx *gorm.DB) *Query {
+	{{if .DBResolver -}}
+	tx = tx.Clauses(dbresolver.Use({{printf "%q" .DBResolver}}))
+	{{end -}}
+	return q.clone(tx)
+}
+
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
+	{{if .DBResolver -}}
+	db = db.Clauses(dbresolver.Use({{printf "%q" .DBResolver}}))
+	{{end -}}
 	return &Query{
 		db: db,
 		{{range $name,$d :=.Data -}}
@@ -79,6 +93,18 @@ func (q *Query) WithContext(ctx context.Context) *queryCtx  {
 	}
 }
 
+{{if .ContextFirstArg -}}
+// Transaction run fc in transaction, it's rolled back when fc returns error or panics, and panic is re-panicked
+func (q *Query) Transaction(ctx context.Context, fc func(tx *Query) error, opts ...*sql.TxOptions) error {
+	return q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error { return fc(q.clone(tx)) }, opts...)
+}
+
+func (q *Query) Begin(ctx context.Context, opts ...*sql.TxOptions) *QueryTx {
+	tx := q.db.WithContext(ctx).Begin(opts...)
+	return &QueryTx{Query: q.clone(tx), Error: tx.Error}
+}
+{{- else -}}
+// Transaction run fc in transaction, it's rolled back when fc returns error or panics, and panic is re-panicked
 func (q *Query) Transaction(fc func(tx *Query) error, opts ...*sql.TxOptions) error {
 	return q.db.Transaction(func(tx *gorm.DB) error { return fc(q.clone(tx)) }, opts...)
 }
@@ -87,6 +113,7 @@ func (q *Query) Begin(opts ...*sql.TxOptions) *QueryTx {
 	tx := q.db.Begin(opts...)
 	return &QueryTx{Query: q.clone(tx), Error: tx.Error}
 }
+{{- end}}
 
 type QueryTx struct {
 	*Query
@@ -204,4 +231,94 @@ func Test_Transaction(t *testing.T) {
 		t.Errorf("query tx Rollback fail: %s", err)
 	}
 }
+
+{{if .DBResolver -}}
+func Test_DBResolver(t *testing.T) {
+	query := Use(_gen_test_db)
+	dbs := []*gorm.DB{query.db, query.ReadDB().db, query.WriteDB().db, query.ReplaceDB(_gen_test_db).db}
+	err := query.Transaction(func(tx *Query) error {
+		dbs = append(dbs, tx.db)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("query.Transaction execute fail: %s", err)
+	}
+	tx := query.Begin()
+	dbs = append(dbs, tx.db)
+	if err = tx.Rollback(); err != nil {
+		t.Errorf("query tx Rollback fail: %s", err)
+	}
+
+	for _, db := range dbs {
+		if _, ok := db.Statement.Clauses["gorm:db_resolver:using"]; !ok {
+			t.Errorf("expect dbresolver clause of resolver {{.DBResolver}} on query db")
+		}
+	}
+}
+
+{{end -}}
+func Test_TransactionPanic(t *testing.T) {
+	query := Use(_gen_test_db)
+	record := _another{ID: 1<<62 + 1}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "rollback" {
+				t.Errorf("query.Transaction expects panic of callback re-panicked, got %v", r)
+			}
+		}()
+		_ = query.Transaction(func(tx *Query) error {
+			if err := tx.db.Create(&record).Error; err != nil {
+				t.Errorf("create record in transaction fail: %s", err)
+			}
+			panic("rollback")
+		})
+	}()
+
+	sqlDB, err := _gen_test_db.DB()
+	if err != nil {
+		t.Fatalf("get sql db fail: %s", err)
+	}
+	if inUse := sqlDB.Stats().InUse; inUse != 0 {
+		t.Errorf("query.Transaction leaves %d connections of pending transaction after panic", inUse)
+	}
+	var count int64
+	if err := _gen_test_db.Model(&_another{}).Where("id = ?", record.ID).Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("query.Transaction expects record rolled back after panic, got count %d, err %v", count, err)
+	}
+}
+
+func Test_WithTx(t *testing.T) {
+	tx := _gen_test_db.Begin()
+	defer tx.Rollback()
+
+	query := Use(_gen_test_db).WithTx(tx)
+	for _, db := range []*gorm.DB{
+		{{range $name,$d :=.Data -}}
+		query.{{$d.ModelStructName}}.WithContext(context.Background()).UnderlyingDB(),
+		{{end}}
+	} {
+		if db.Statement.ConnPool != tx.Statement.ConnPool {
+			t.Errorf("query.WithTx expects dao using connection of transaction")
+		}
+	}
+}
+`
+
+// Scopes scope functions of columns configured by ScopeColumns, applied by gorm's db.Scopes or DO's Scopes with gen.DBScope
+const Scopes = NotEditMark + `
+package {{.Package}}
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+{{range .Scopes}}
+// {{.Name}} scope of records whose {{.Column}} equals {{.Param}}, e.g. db.Scopes({{.Name}}(v)) or do.Scopes(gen.DBScope({{.Name}}(v)))
+func {{.Name}}({{.Param}} {{.Type}}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: {{printf "%q" .Column}}}, Value: {{.Param}}})
+	}
+}
+{{end}}
 `
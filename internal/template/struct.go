@@ -40,6 +40,57 @@ const (
 
 	// DefineMethodStruct do struct
 	DefineMethodStruct = `type {{.QueryStructName}}Do struct { gen.DO }`
+
+	// TableOrderBy function mapping allowed column names to order expressions
+	TableOrderBy = `
+// _{{.QueryStructName}}OrderByColumns column names allowed in {{.ModelStructName}}OrderBy
+var _{{.QueryStructName}}OrderByColumns = map[string]string{
+	{{range .OrderByColumns}}{{printf "%q" .Key}}: {{printf "%q" .Name}},
+	{{end -}}
+}
+
+// {{.ModelStructName}}OrderBy order expression of column of {{.TableName}}, e.g. for sort parameter of api request,
+// it returns error when column is not a column of {{.TableName}}{{if .OrderByCaseInsensitive}}, column is matched case-insensitively{{end}}
+func {{.ModelStructName}}OrderBy(column string, desc bool) (field.Expr, error) {
+	name, ok := _{{.QueryStructName}}OrderByColumns[{{if .OrderByCaseInsensitive}}strings.ToLower(column){{else}}column{{end}}]
+	if !ok {
+		return nil, fmt.Errorf("order by unknown column %q of {{.TableName}}", column)
+	}
+	f := field.NewField({{printf "%q" .TableName}}, name)
+	if desc {
+		return f.Desc(), nil
+	}
+	return f, nil
+}
+`
+
+	// TableQueryFilter filter struct of model and method translating it to conditions
+	TableQueryFilter = `
+// {{.ModelStructName}}Filter conditions of {{.TableName}}, nil fields are ignored
+type {{.ModelStructName}}Filter struct {
+	{{range .FilterFields -}}
+	{{.Name}} *{{.Type}} // {{.Comment}}
+	{{end -}}
+}
+
+// FilterConds translate non-nil fields of filter to conditions
+func ({{.S}} {{.QueryStructName}}) FilterConds(filter {{.ModelStructName}}Filter) (conds []gen.Condition) {
+	{{range .FilterFields -}}
+	if filter.{{.Name}} != nil {
+		{{if eq .Method "IsNull" -}}
+		if *filter.{{.Name}} {
+			conds = append(conds, {{$.S}}.{{.Field}}.IsNull())
+		} else {
+			conds = append(conds, {{$.S}}.{{.Field}}.IsNotNull())
+		}
+		{{- else -}}
+		conds = append(conds, {{$.S}}.{{.Field}}.{{.Method}}({{if .Convert}}{{.Convert}}(*filter.{{.Name}}){{else}}*filter.{{.Name}}{{end}}))
+		{{- end}}
+	}
+	{{end -}}
+	return conds
+}
+`
 )
 
 const (
@@ -159,12 +210,38 @@ func ({{.S}} *{{.QueryStructName}}) fillFieldMap() {
 	defineGenericsDoInterface = `
 type I{{.ModelStructName}}Do interface {
 	gen.IGenericsDo[I{{.ModelStructName}}Do, *{{.StructInfo.Package}}.{{.StructInfo.Type}}]
+	FirstOrInitWith(seed *{{.StructInfo.Package}}.{{.StructInfo.Type}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	FirstOrCreateWith(seed *{{.StructInfo.Package}}.{{.StructInfo.Type}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, count int64, err error)
+	{{if and .WithUpsert (not .ReadOnly) -}}
+	UpsertByColumns({{if .ContextFirstArg}}ctx context.Context, {{end}}conflictCols []field.Expr, updateCols []field.Expr, values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error
+	Upsert({{if .ContextFirstArg}}ctx context.Context, {{end}}conflictColumns []clause.Column, doUpdates clause.Set, values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error
+	{{end -}}
+	{{if and (gt .DefaultBatchSize 0) (not .ReadOnly) -}}
+	BulkInsert({{if .ContextFirstArg}}ctx context.Context, {{end}}records []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int) (rowsAffected int64, err error)
+	{{end -}}
+	{{with .OptimisticLock -}}
+	UpdateWithVersion({{if $.ContextFirstArg}}ctx context.Context, {{end}}version {{.Type}}, columns ...field.AssignExpr) (rowsAffected int64, err error)
+	{{end -}}
+	{{if .WithIndexLookups}}{{range .IndexLookups -}}
+	{{.Name}}({{.Param}} {{.Type}}) I{{$.ModelStructName}}Do
+	{{end}}{{end -}}
+	{{range .JSONPathConds -}}
+	{{.Name}}(value {{.Type}}) I{{$.ModelStructName}}Do
+	{{end -}}
+	{{range .HasManyRelations -}}
+	CountAssociation{{.Name}}({{if $.ContextFirstArg}}ctx context.Context, {{end}}m *{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) (int64, error)
+	{{if not $.ReadOnly -}}
+	Clear{{.Name}}({{if $.ContextFirstArg}}ctx context.Context, {{end}}m *{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) error
+	{{end -}}
+	{{end -}}
 	{{range .Interfaces -}}
 	{{.FuncSign}}
 	{{end}}
 }
 `
-	defineDoInterface = `
+	defineDoInterface = contextArgs + `
 
 type I{{.ModelStructName}}Do interface {
 	gen.SubQuery
@@ -192,38 +269,72 @@ type I{{.ModelStructName}}Do interface {
 	Having(conds ...gen.Condition) I{{.ModelStructName}}Do
 	Limit(limit int) I{{.ModelStructName}}Do
 	Offset(offset int) I{{.ModelStructName}}Do
-	Count() (count int64, err error)
+	Count({{$ctx}}) (count int64, err error)
+	CountBy({{$ctxArg}}conds ...gen.Condition) (count int64, err error)
 	Scopes(funcs ...func(gen.Dao) gen.Dao) I{{.ModelStructName}}Do
 	Unscoped() I{{.ModelStructName}}Do
-	Create(values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error
-	CreateInBatches(values []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int) error
-	Save(values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error
-	First() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
-	Take() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
-	Last() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
-	Find() ([]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
-	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, err error)
-	FindInBatches(result *[]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int, fc func(tx gen.Dao, batch int) error) error
-	Pluck(column field.Expr, dest interface{}) error
-	Delete(...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) (info gen.ResultInfo, err error)
-	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
-	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
-	Updates(value interface{}) (info gen.ResultInfo, err error)
-	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
-	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
-	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	{{if not .ReadOnly -}}
+	Create({{$ctxArg}}values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error
+	CreateInBatches({{$ctxArg}}values []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int) error
+	{{if not .WithoutPrimaryKeyMethods -}}
+	Save({{$ctxArg}}values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error
+	{{end -}}
+	{{end -}}
+	{{if and .WithUpsert (not .ReadOnly) -}}
+	UpsertByColumns({{if .ContextFirstArg}}ctx context.Context, {{end}}conflictCols []field.Expr, updateCols []field.Expr, values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error
+	Upsert({{if .ContextFirstArg}}ctx context.Context, {{end}}conflictColumns []clause.Column, doUpdates clause.Set, values ...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) error
+	{{end -}}
+	{{if and (gt .DefaultBatchSize 0) (not .ReadOnly) -}}
+	BulkInsert({{if .ContextFirstArg}}ctx context.Context, {{end}}records []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int) (rowsAffected int64, err error)
+	{{end -}}
+	{{with .OptimisticLock -}}
+	UpdateWithVersion({{if $.ContextFirstArg}}ctx context.Context, {{end}}version {{.Type}}, columns ...field.AssignExpr) (rowsAffected int64, err error)
+	{{end -}}
+	{{if .WithIndexLookups}}{{range .IndexLookups -}}
+	{{.Name}}({{.Param}} {{.Type}}) I{{$.ModelStructName}}Do
+	{{end}}{{end -}}
+	{{range .JSONPathConds -}}
+	{{.Name}}(value {{.Type}}) I{{$.ModelStructName}}Do
+	{{end -}}
+	{{range .HasManyRelations -}}
+	CountAssociation{{.Name}}({{if $.ContextFirstArg}}ctx context.Context, {{end}}m *{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) (int64, error)
+	{{if not $.ReadOnly -}}
+	Clear{{.Name}}({{if $.ContextFirstArg}}ctx context.Context, {{end}}m *{{$.StructInfo.Package}}.{{$.StructInfo.Type}}) error
+	{{end -}}
+	{{end -}}
+	First({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	Take({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	Last({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	Find({{$ctx}}) ([]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	FindInBatch({{$ctxArg}}batchSize int, fc func(tx gen.Dao, batch int) error) (results []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, err error)
+	FindInBatches({{$ctxArg}}result *[]*{{.StructInfo.Package}}.{{.StructInfo.Type}}, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck({{$ctxArg}}column field.Expr, dest interface{}) error
+	{{if not .ReadOnly -}}
+	Delete({{if $ctx}}{{$ctxArg}}models {{end}}...*{{.StructInfo.Package}}.{{.StructInfo.Type}}) (info gen.ResultInfo, err error)
+	Update({{$ctxArg}}column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple({{$ctxArg}}columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates({{$ctxArg}}value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn({{$ctxArg}}column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple({{$ctxArg}}columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns({{$ctxArg}}value interface{}) (info gen.ResultInfo, err error)
 	UpdateFrom(q gen.SubQuery) gen.Dao
+	{{end -}}
 	Attrs(attrs ...field.AssignExpr) I{{.ModelStructName}}Do
 	Assign(attrs ...field.AssignExpr) I{{.ModelStructName}}Do
 	Joins(fields ...field.RelationField) I{{.ModelStructName}}Do
 	Preload(fields ...field.RelationField) I{{.ModelStructName}}Do
-	FirstOrInit() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
-	FirstOrCreate() (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
-	FindByPage(offset int, limit int) (result []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, count int64, err error)
-	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
-	Rows() (*sql.Rows, error)
-	Row() *sql.Row
-	Scan(result interface{}) (err error)
+	FirstOrInit({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	FirstOrInitWith({{$ctxArg}}seed *{{.StructInfo.Package}}.{{.StructInfo.Type}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	{{if and (not .ReadOnly) (not .WithoutPrimaryKeyMethods) -}}
+	FirstOrCreate({{$ctx}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	FirstOrCreateWith({{$ctxArg}}seed *{{.StructInfo.Package}}.{{.StructInfo.Type}}) (*{{.StructInfo.Package}}.{{.StructInfo.Type}}, error)
+	{{end -}}
+	FindByPage({{$ctxArg}}offset int, limit int) (result []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, count int64, err error)
+	FindPage({{$ctxArg}}offset int, limit int, conds ...gen.Condition) (result []*{{.StructInfo.Package}}.{{.StructInfo.Type}}, count int64, err error)
+	ScanByPage({{$ctxArg}}result interface{}, offset int, limit int) (count int64, err error)
+	Rows({{$ctx}}) (*sql.Rows, error)
+	Row({{$ctx}}) *sql.Row
+	Scan({{$ctxArg}}result interface{}) (err error)
 	Returning(value interface{}, columns ...string) I{{.ModelStructName}}Do
 	UnderlyingDB() *gorm.DB
 	schema.Tabler
@@ -232,7 +343,9 @@ type I{{.ModelStructName}}Do interface {
 	{{.FuncSign}}
 	{{end}}
 }
-`
+{{if .AssertQueryInterface}}
+var _ I{{.ModelStructName}}Do = (*{{.QueryStructName}}Do)(nil)
+{{end}}`
 )
 
 const (
@@ -0,0 +1,225 @@
+package gen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gorm.io/gen/internal/model"
+)
+
+var (
+	// protoFieldReg field declaration of generated message, e.g. optional string name = 2;
+	protoFieldReg = regexp.MustCompile(`^\s*(?:optional\s+)?[\w.]+\s+(\w+)\s*=\s*(\d+)\s*;`)
+	// protoReservedReg reserved field numbers of generated message, e.g. reserved 3, 5;
+	protoReservedReg = regexp.MustCompile(`^\s*reserved\s+([\d\s,]+);`)
+)
+
+// protoKeywords words which are not used as field names, suffixed with _ instead
+var protoKeywords = map[string]bool{
+	"syntax": true, "import": true, "weak": true, "public": true, "package": true, "option": true,
+	"message": true, "enum": true, "service": true, "rpc": true, "returns": true, "stream": true,
+	"reserved": true, "extensions": true, "extend": true, "to": true, "max": true,
+	"optional": true, "repeated": true, "required": true, "oneof": true, "map": true,
+}
+
+// GenerateProto write a <table>.proto file of proto3 message into outDir for each table introspected by GenerateModel.
+// Field numbers follow column order, numbers of existing proto file are kept so that adding a column doesn't renumber
+// fields, numbers of dropped columns are reserved
+func (g *Generator) GenerateProto(outDir, packageName string) error {
+	if err := g.mkdirAll(outDir); err != nil {
+		return fmt.Errorf("make dir %s fail: %w", outDir, err)
+	}
+
+	names := make([]string, 0, len(g.models))
+	for name, meta := range g.models {
+		if meta != nil && meta.Source == model.Table {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		meta := g.models[name]
+		fileName := filepath.Join(outDir, meta.FileName+".proto")
+		numbers, reserved, err := readProtoFieldNumbers(fileName)
+		if err != nil {
+			return err
+		}
+		content := protoMessage(packageName, meta.ModelStructName, meta.TableName, meta.Fields, numbers, reserved, g.ProtoOptionalNullable)
+		if err := g.writeFile(fileName, content); err != nil {
+			return fmt.Errorf("write proto file %s fail: %w", fileName, err)
+		}
+		g.info(fmt.Sprintf("generate proto file: %s", fileName))
+	}
+	return nil
+}
+
+// protoField field of proto message
+type protoField struct {
+	Name     string
+	Type     string
+	Number   int
+	Optional bool
+}
+
+// protoMessage build proto file of model, numbers are field numbers of previous generated file by field name
+func protoMessage(pkg, modelName, tableName string, fields []*model.Field, numbers map[string]int, reserved []int, optional bool) []byte {
+	next := 0
+	for _, n := range numbers {
+		if n > next {
+			next = n
+		}
+	}
+	for _, n := range reserved {
+		if n > next {
+			next = n
+		}
+	}
+
+	var protoFields []protoField
+	used := make(map[string]bool)
+	for _, f := range fields {
+		if f.Column == nil || used[f.ColumnName] {
+			continue
+		}
+		used[f.ColumnName] = true
+
+		pf := protoField{Name: protoFieldName(f.ColumnName), Type: protoType(f.Type)}
+		if f.Enum != nil {
+			pf.Type = "string"
+		}
+		if nullable, _ := f.Column.Nullable(); optional && nullable {
+			pf.Optional = true
+		}
+		if n, ok := numbers[pf.Name]; ok {
+			pf.Number = n
+			delete(numbers, pf.Name)
+		} else {
+			next++
+			pf.Number = next
+		}
+		protoFields = append(protoFields, pf)
+	}
+	for _, n := range numbers { // fields of dropped columns
+		reserved = append(reserved, n)
+	}
+	sort.Ints(reserved)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gorm.io/gen. DO NOT EDIT.\n\n")
+	buf.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&buf, "package %s;\n\n", pkg)
+	for _, f := range protoFields {
+		if f.Type == "google.protobuf.Timestamp" {
+			buf.WriteString("import \"google/protobuf/timestamp.proto\";\n\n")
+			break
+		}
+	}
+	fmt.Fprintf(&buf, "// %s mapped from table <%s>\n", modelName, tableName)
+	fmt.Fprintf(&buf, "message %s {\n", modelName)
+	if len(reserved) > 0 {
+		values := make([]string, 0, len(reserved))
+		for _, n := range reserved {
+			values = append(values, strconv.Itoa(n))
+		}
+		fmt.Fprintf(&buf, "  reserved %s;\n\n", strings.Join(values, ", "))
+	}
+	for _, f := range protoFields {
+		buf.WriteString("  ")
+		if f.Optional {
+			buf.WriteString("optional ")
+		}
+		fmt.Fprintf(&buf, "%s %s = %d;\n", f.Type, f.Name, f.Number)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// readProtoFieldNumbers read field numbers by name and reserved numbers from previous generated proto file
+func readProtoFieldNumbers(path string) (numbers map[string]int, reserved []int, err error) {
+	numbers = make(map[string]int)
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return numbers, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read proto file %s fail: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := protoReservedReg.FindStringSubmatch(line); m != nil {
+			for _, v := range strings.Split(m[1], ",") {
+				if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+					reserved = append(reserved, n)
+				}
+			}
+		} else if m := protoFieldReg.FindStringSubmatch(line); m != nil {
+			numbers[m[1]], _ = strconv.Atoi(m[2])
+		}
+	}
+	return numbers, reserved, nil
+}
+
+// protoFieldName snake_case field name of column, e.g. userName => user_name, type => type, package => package_
+func protoFieldName(columnName string) string {
+	var b strings.Builder
+	runes := []rune(columnName)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		name = "f_" + name
+	}
+	if protoKeywords[name] {
+		name += "_"
+	}
+	return name
+}
+
+// protoType proto scalar type of go type, string for types without proto scalar type
+func protoType(goType string) string {
+	switch strings.TrimPrefix(goType, "*") {
+	case "int", "int64":
+		return "int64"
+	case "int8", "int16", "int32":
+		return "int32"
+	case "uint", "uint64":
+		return "uint64"
+	case "uint8", "uint16", "uint32":
+		return "uint32"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	case "bool":
+		return "bool"
+	case "[]byte", "[]uint8":
+		return "bytes"
+	case "time.Time", "gorm.DeletedAt":
+		return "google.protobuf.Timestamp"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,250 @@
+package gen
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"gorm.io/gen/internal/model"
+)
+
+// SchemaFormat format of exported schema
+type SchemaFormat string
+
+const (
+	// SchemaFormatJSON export schema as json
+	SchemaFormatJSON SchemaFormat = "json"
+	// SchemaFormatYAML export schema as yaml
+	SchemaFormatYAML SchemaFormat = "yaml"
+)
+
+// TableSchema introspected table schema
+type TableSchema struct {
+	Name    string         `json:"name" yaml:"name"`
+	Model   string         `json:"model" yaml:"model"`
+	Comment string         `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Columns []ColumnSchema `json:"columns" yaml:"columns"`
+	Indexes []IndexSchema  `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+}
+
+// ColumnSchema introspected column schema
+type ColumnSchema struct {
+	Name         string `json:"name" yaml:"name"`
+	DatabaseType string `json:"database_type" yaml:"database_type"`
+	ColumnType   string `json:"column_type,omitempty" yaml:"column_type,omitempty"`
+	GoType       string `json:"go_type" yaml:"go_type"`
+	Nullable     bool   `json:"nullable" yaml:"nullable"`
+	PrimaryKey   bool   `json:"primary_key,omitempty" yaml:"primary_key,omitempty"`
+	Comment      string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// IndexSchema introspected index schema, columns are sorted by priority
+type IndexSchema struct {
+	Name       string              `json:"name" yaml:"name"`
+	Unique     bool                `json:"unique,omitempty" yaml:"unique,omitempty"`
+	PrimaryKey bool                `json:"primary_key,omitempty" yaml:"primary_key,omitempty"`
+	Columns    []IndexColumnSchema `json:"columns" yaml:"columns"`
+}
+
+// IndexColumnSchema column of index
+type IndexColumnSchema struct {
+	Name      string `json:"name" yaml:"name"`
+	Priority  int32  `json:"priority" yaml:"priority"`
+	Sort      string `json:"sort,omitempty" yaml:"sort,omitempty"`
+	Collation string `json:"collation,omitempty" yaml:"collation,omitempty"`
+}
+
+// ExportSchema write schema of tables introspected by GenerateModel, tables are sorted by name,
+// indexes are exported when FieldWithIndexTag is enabled
+func (g *Generator) ExportSchema(w io.Writer, format SchemaFormat) error {
+	tables := make([]TableSchema, 0, len(g.models))
+	for _, meta := range g.models {
+		if meta == nil || meta.Source != model.Table {
+			continue
+		}
+		tables = append(tables, tableSchema(meta.TableName, meta.ModelStructName, meta.TableComment, meta.Fields))
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+
+	switch format {
+	case SchemaFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tables)
+	case SchemaFormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(tables); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported schema format: %q", format)
+	}
+}
+
+// tableSchema build table schema from fields generated from columns, fields without column are skipped
+func tableSchema(name, modelName, comment string, fields []*model.Field) TableSchema {
+	table := TableSchema{Name: name, Model: modelName, Comment: comment, Columns: make([]ColumnSchema, 0, len(fields))}
+
+	columns := make([]*model.Column, 0, len(fields))
+	for _, f := range fields {
+		if f.Column == nil {
+			continue
+		}
+		col := columnSchema(f.Column)
+		col.GoType = f.Type
+		table.Columns = append(table.Columns, col)
+		columns = append(columns, f.Column)
+	}
+	table.Indexes = indexSchemas(columns)
+	return table
+}
+
+// columnSchema build column schema of introspected column, go type is left empty
+func columnSchema(c *model.Column) ColumnSchema {
+	col := ColumnSchema{Name: c.Name(), DatabaseType: c.DatabaseTypeName()}
+	col.ColumnType, _ = c.ColumnType.ColumnType()
+	col.Nullable, _ = c.Nullable()
+	col.PrimaryKey, _ = c.PrimaryKey()
+	col.Comment, _ = c.Comment()
+	return col
+}
+
+// indexSchemas build index schemas from indexes of columns, sorted by name
+func indexSchemas(columns []*model.Column) (result []IndexSchema) {
+	indexes := make(map[string]*IndexSchema)
+	for _, c := range columns {
+		for _, idx := range c.Indexes {
+			if idx == nil {
+				continue
+			}
+			index := indexes[idx.Name()]
+			if index == nil {
+				index = &IndexSchema{Name: idx.Name()}
+				index.Unique, _ = idx.Unique()
+				index.PrimaryKey, _ = idx.PrimaryKey()
+				indexes[idx.Name()] = index
+			}
+			index.Columns = append(index.Columns, IndexColumnSchema{Name: c.Name(), Priority: idx.Priority, Sort: idx.Sort, Collation: idx.Collation})
+		}
+	}
+
+	for _, index := range indexes {
+		sort.SliceStable(index.Columns, func(i, j int) bool { return index.Columns[i].Priority < index.Columns[j].Priority })
+		result = append(result, *index)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// fingerprintPrefix prefix of schema fingerprint line in model file header
+const fingerprintPrefix = "// Schema fingerprint: "
+
+// schemaFingerprint hash of column names, types and index definitions of introspected table columns
+func schemaFingerprint(columns []*model.Column) string {
+	h := sha256.New()
+	for _, c := range columns {
+		col := columnSchema(c)
+		fmt.Fprintf(h, "column %s %s %s %t %t\n", col.Name, col.DatabaseType, col.ColumnType, col.Nullable, col.PrimaryKey)
+	}
+	for _, idx := range indexSchemas(columns) {
+		fmt.Fprintf(h, "index %s %t %t", idx.Name, idx.Unique, idx.PrimaryKey)
+		for _, c := range idx.Columns {
+			fmt.Fprintf(h, " %s:%d:%s:%s", c.Name, c.Priority, c.Sort, c.Collation)
+		}
+		fmt.Fprintln(h)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// SchemaFingerprint hash of all tables introspected by GenerateModel, tables are sorted by name so it is stable
+// across runs. It covers the attributes of schemaFingerprint only:
+//   - column name, database type, full column type (e.g. varchar(64)), nullability and primary key
+//   - index name, uniqueness, primary key, and name, priority, sort and collation of each index column
+//
+// Comments, default values, foreign keys, go types and naming of generated code are not covered,
+// neither are field options, plugins and base models, columns are hashed as introspected.
+// A table generated as several models, e.g. by GenerateModelAs, is hashed once.
+// Indexes other than composite primary key are only introspected when FieldWithIndexTag is enabled
+func (g *Generator) SchemaFingerprint() (string, error) {
+	names := make([]string, 0, len(g.models))
+	fingerprints := make(map[string]string, len(g.models))
+	for _, meta := range g.models {
+		if meta == nil || meta.Source != model.Table {
+			continue
+		}
+		if _, ok := fingerprints[meta.TableName]; ok {
+			continue
+		}
+		names = append(names, meta.TableName)
+		fingerprints[meta.TableName] = schemaFingerprint(meta.Columns)
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no table is introspected, generate models by GenerateModel or GenerateAllTable first")
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "table %s %s\n", name, fingerprints[name])
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CheckStale compare schema fingerprint of tables introspected by GenerateModel with the header of
+// their generated model files, return sorted names of tables whose model file is missing or out of date
+func (g *Generator) CheckStale() ([]string, error) {
+	modelOutPath, err := g.getModelOutputPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, data := range g.models {
+		if data == nil || !data.Generated || data.Source != model.Table {
+			continue
+		}
+		fingerprint, err := readFingerprint(g.modelFilePath(modelOutPath, data))
+		if err != nil {
+			return nil, err
+		}
+		if fingerprint != schemaFingerprint(data.Columns) {
+			stale = append(stale, data.TableName)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// readFingerprint read schema fingerprint from file header, empty when file or fingerprint does not exist
+func readFingerprint(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read model file %s fail: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, fingerprintPrefix) {
+			return strings.TrimPrefix(line, fingerprintPrefix), nil
+		}
+		if strings.HasPrefix(line, "package ") { // fingerprint is written before package clause
+			break
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,122 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gorm.io/gen/internal/generate"
+	"gorm.io/gen/internal/model"
+	tmpl "gorm.io/gen/internal/template"
+)
+
+// scopeFunc scope function of column generated into scopes.go
+type scopeFunc struct {
+	Name   string
+	Column string
+	Param  string
+	Type   string
+}
+
+// generateScopesFile write scopes.go of ScopeColumns into query package, skipped when no generated table has them
+func (g *Generator) generateScopesFile() error {
+	scopes, err := g.scopeFuncs()
+	if err != nil || len(scopes) == 0 {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = render(tmpl.Scopes, &buf, map[string]interface{}{
+		"Package": g.queryPkgName,
+		"Scopes":  scopes,
+	})
+	if err != nil {
+		return err
+	}
+	fileName := filepath.Join(g.OutPath, "scopes.go")
+	if err = g.output(fileName, buf.Bytes()); err != nil {
+		return err
+	}
+	g.info("generate scopes file: " + fileName)
+	return nil
+}
+
+// scopeFuncs scope functions of ScopeColumns which generated tables have, sorted by name.
+// Parameter type is the go type of column, interface{} when tables disagree on it
+func (g *Generator) scopeFuncs() ([]scopeFunc, error) {
+	if len(g.ScopeColumns) == 0 {
+		return nil, nil
+	}
+
+	structNames := make([]string, 0, len(g.Data))
+	for name := range g.Data {
+		structNames = append(structNames, name)
+	}
+	sort.Strings(structNames)
+
+	paramTypes := make(map[string]string, len(g.ScopeColumns)) // column name => param type
+	for _, name := range structNames {
+		data := g.Data[name]
+		if data.Source != model.Table {
+			continue
+		}
+		for _, f := range data.ColumnFields() {
+			if _, ok := g.ScopeColumns[f.ColumnName]; !ok {
+				continue
+			}
+			typ := scopeParamType(f)
+			if t, ok := paramTypes[f.ColumnName]; ok && t != typ {
+				typ = "interface{}"
+			}
+			paramTypes[f.ColumnName] = typ
+		}
+	}
+
+	scopes := make([]scopeFunc, 0, len(paramTypes))
+	columns := make(map[string]string, len(paramTypes)) // scope function name => column name
+	for column, typ := range paramTypes {
+		name := g.ScopeColumns[column]
+		if name == "" {
+			name = "By" + g.db.NamingStrategy.SchemaName(strings.TrimSuffix(column, "_id"))
+		}
+		if !token.IsIdentifier(name) || token.IsKeyword(name) {
+			return nil, fmt.Errorf("invalid scope function name %q of column %s", name, column)
+		}
+		if other, ok := columns[name]; ok {
+			return nil, fmt.Errorf("scope function %s is declared for both column %s and %s", name, other, column)
+		}
+		columns[name] = column
+
+		param := lowerCamel(g.db.NamingStrategy.SchemaName(column))
+		if token.IsKeyword(param) || !token.IsIdentifier(param) {
+			param = "value"
+		}
+		scopes = append(scopes, scopeFunc{Name: name, Column: column, Param: param, Type: typ})
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].Name < scopes[j].Name })
+	return scopes, nil
+}
+
+// scopeParamType go type of scope function parameter, enum columns use string, types declared in model package
+// or packages other than time use interface{} as they may not be importable from query package
+func scopeParamType(f *model.Field) string {
+	typ := strings.TrimPrefix(f.Type, "*")
+	if f.Enum != nil {
+		return "string"
+	}
+	if base := strings.TrimLeft(typ, "[]*"); strings.Contains(base, ".") {
+		if !strings.HasPrefix(base, "time.") {
+			return "interface{}"
+		}
+	} else if types.Universe.Lookup(base) == nil {
+		return "interface{}"
+	}
+	return typ
+}
+
+// lowerCamel lower leading upper case letters of camel case name, e.g. TenantID => tenantID, ID => id, URLPath => urlPath
+var lowerCamel = generate.LowerCamel
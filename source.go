@@ -0,0 +1,70 @@
+package gen
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"gorm.io/gen/internal/generate"
+)
+
+// DataSource named db connection registered by UseNamedDB, models generated from it are introspected
+// with its connection and dialect, e.g. index column sequences and dialect specific type mapping
+type DataSource struct {
+	g  *Generator
+	db *gorm.DB
+}
+
+// UseNamedDB register db connection as data source name, models are generated from it by FromDB(name),
+// db set by UseDB is still used by other methods of generator
+func (g *Generator) UseNamedDB(name string, db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	if g.namedDBs == nil {
+		g.namedDBs = make(map[string]*gorm.DB)
+	}
+	g.namedDBs[name] = db
+}
+
+// FromDB data source registered by UseNamedDB, its models are generated into the same query and model path
+// as other models of generator, model names must not conflict, e.g. specify them by GenerateModelAs.
+// Use separate generators to generate data sources into separate packages
+func (g *Generator) FromDB(name string) *DataSource {
+	db, ok := g.namedDBs[name]
+	if !ok {
+		panic(fmt.Errorf("data source %s is not registered by UseNamedDB", name))
+	}
+	return &DataSource{g: g, db: db}
+}
+
+// GenerateModel catch table info from data source, return a BaseStruct
+func (s *DataSource) GenerateModel(tableName string, opts ...ModelOpt) *generate.QueryStructMeta {
+	defer s.use()()
+	return s.g.GenerateModel(tableName, opts...)
+}
+
+// GenerateModelAs catch table info from data source, return a BaseStruct
+func (s *DataSource) GenerateModelAs(tableName string, modelName string, opts ...ModelOpt) *generate.QueryStructMeta {
+	defer s.use()()
+	return s.g.GenerateModelAs(tableName, modelName, opts...)
+}
+
+// GenerateModelFromView catch view info from data source, return a read-only BaseStruct
+func (s *DataSource) GenerateModelFromView(viewName string, opts ...ModelOpt) *generate.QueryStructMeta {
+	defer s.use()()
+	return s.g.GenerateModelFromView(viewName, opts...)
+}
+
+// GenerateAllTable generate all tables in data source
+func (s *DataSource) GenerateAllTable(opts ...ModelOpt) (tableModels []interface{}) {
+	defer s.use()()
+	return s.g.GenerateAllTable(opts...)
+}
+
+// use switch db of generator to data source, return func restoring it
+func (s *DataSource) use() (restore func()) {
+	db := s.g.db
+	s.g.db = s.db
+	return func() { s.g.db = db }
+}
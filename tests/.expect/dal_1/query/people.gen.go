@@ -275,8 +275,9 @@ func (p personDo) Create(values ...*model.Person) error {
 	return p.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (p personDo) CreateInBatches(values []*model.Person, batchSize int) error {
-	return p.DO.CreateInBatches(values, batchSize)
+	return p.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -360,6 +361,17 @@ func (p personDo) FirstOrInit() (*model.Person, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (p personDo) FirstOrInitWith(seed *model.Person) (*model.Person, error) {
+	if seed == nil {
+		seed = new(model.Person)
+	}
+	if err := p.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (p personDo) FirstOrCreate() (*model.Person, error) {
 	if result, err := p.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -368,6 +380,17 @@ func (p personDo) FirstOrCreate() (*model.Person, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (p personDo) FirstOrCreateWith(seed *model.Person) (*model.Person, error) {
+	if seed == nil {
+		seed = new(model.Person)
+	}
+	if err := p.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (p personDo) FindByPage(offset int, limit int) (result []*model.Person, count int64, err error) {
 	result, err = p.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -383,6 +406,18 @@ func (p personDo) FindByPage(offset int, limit int) (result []*model.Person, cou
 	return
 }
 
+func (p personDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.Person, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = p.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return p.Where(conds...).FindByPage(offset, limit)
+}
+
+func (p personDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return p.Where(conds...).Count()
+}
+
 func (p personDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = p.Count()
 	if err != nil {
@@ -75,6 +75,11 @@ func (q *Query) WriteDB() *Query {
 	return q.clone(q.db.Clauses(dbresolver.Write))
 }
 
+// WithTx use externally managed transaction tx, committing or rolling it back is up to caller
+func (q *Query) WithTx(tx *gorm.DB) *Query {
+	return q.clone(tx)
+}
+
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
 		db:         db,
@@ -104,6 +109,7 @@ func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	}
 }
 
+// Transaction run fc in transaction, it's rolled back when fc returns error or panics, and panic is re-panicked
 func (q *Query) Transaction(fc func(tx *Query) error, opts ...*sql.TxOptions) error {
 	return q.db.Transaction(func(tx *gorm.DB) error { return fc(q.clone(tx)) }, opts...)
 }
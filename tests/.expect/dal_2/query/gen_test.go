@@ -120,3 +120,52 @@ func Test_Transaction(t *testing.T) {
 		t.Errorf("query tx Rollback fail: %s", err)
 	}
 }
+
+func Test_TransactionPanic(t *testing.T) {
+	query := Use(_gen_test_db)
+	record := _another{ID: 1<<62 + 1}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "rollback" {
+				t.Errorf("query.Transaction expects panic of callback re-panicked, got %v", r)
+			}
+		}()
+		_ = query.Transaction(func(tx *Query) error {
+			if err := tx.db.Create(&record).Error; err != nil {
+				t.Errorf("create record in transaction fail: %s", err)
+			}
+			panic("rollback")
+		})
+	}()
+
+	sqlDB, err := _gen_test_db.DB()
+	if err != nil {
+		t.Fatalf("get sql db fail: %s", err)
+	}
+	if inUse := sqlDB.Stats().InUse; inUse != 0 {
+		t.Errorf("query.Transaction leaves %d connections of pending transaction after panic", inUse)
+	}
+	var count int64
+	if err := _gen_test_db.Model(&_another{}).Where("id = ?", record.ID).Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("query.Transaction expects record rolled back after panic, got count %d, err %v", count, err)
+	}
+}
+
+func Test_WithTx(t *testing.T) {
+	tx := _gen_test_db.Begin()
+	defer tx.Rollback()
+
+	query := Use(_gen_test_db).WithTx(tx)
+	for _, db := range []*gorm.DB{
+		query.Bank.WithContext(context.Background()).UnderlyingDB(),
+		query.CreditCard.WithContext(context.Background()).UnderlyingDB(),
+		query.Customer.WithContext(context.Background()).UnderlyingDB(),
+		query.Person.WithContext(context.Background()).UnderlyingDB(),
+		query.User.WithContext(context.Background()).UnderlyingDB(),
+	} {
+		if db.Statement.ConnPool != tx.Statement.ConnPool {
+			t.Errorf("query.WithTx expects dao using connection of transaction")
+		}
+	}
+}
@@ -22,7 +22,7 @@ type Person struct {
 	AnotherFlag    *int32         `gorm:"column:another_flag" json:"-"`
 	Commit         *string        `gorm:"column:commit" json:"-"`
 	First          *bool          `gorm:"column:First" json:"-"`
-	Bit            *[]uint8       `gorm:"column:bit" json:"-"`
+	Bit            *[]byte        `gorm:"column:bit" json:"-"`
 	Small          *int32         `gorm:"column:small" json:"-"`
 	DeletedAt      gorm.DeletedAt `gorm:"column:deleted_at" json:"-"`
 	Score          *float64       `gorm:"column:score" json:"-"`
@@ -140,6 +140,7 @@ type ICustomerDo interface {
 	Limit(limit int) ICustomerDo
 	Offset(offset int) ICustomerDo
 	Count() (count int64, err error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
 	Scopes(funcs ...func(gen.Dao) gen.Dao) ICustomerDo
 	Unscoped() ICustomerDo
 	Create(values ...*model.Customer) error
@@ -165,8 +166,11 @@ type ICustomerDo interface {
 	Joins(fields ...field.RelationField) ICustomerDo
 	Preload(fields ...field.RelationField) ICustomerDo
 	FirstOrInit() (*model.Customer, error)
+	FirstOrInitWith(seed *model.Customer) (*model.Customer, error)
 	FirstOrCreate() (*model.Customer, error)
+	FirstOrCreateWith(seed *model.Customer) (*model.Customer, error)
 	FindByPage(offset int, limit int) (result []*model.Customer, count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.Customer, count int64, err error)
 	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
 	Rows() (*sql.Rows, error)
 	Row() *sql.Row
@@ -275,8 +279,9 @@ func (c customerDo) Create(values ...*model.Customer) error {
 	return c.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (c customerDo) CreateInBatches(values []*model.Customer, batchSize int) error {
-	return c.DO.CreateInBatches(values, batchSize)
+	return c.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -360,6 +365,17 @@ func (c customerDo) FirstOrInit() (*model.Customer, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (c customerDo) FirstOrInitWith(seed *model.Customer) (*model.Customer, error) {
+	if seed == nil {
+		seed = new(model.Customer)
+	}
+	if err := c.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (c customerDo) FirstOrCreate() (*model.Customer, error) {
 	if result, err := c.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -368,6 +384,17 @@ func (c customerDo) FirstOrCreate() (*model.Customer, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (c customerDo) FirstOrCreateWith(seed *model.Customer) (*model.Customer, error) {
+	if seed == nil {
+		seed = new(model.Customer)
+	}
+	if err := c.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (c customerDo) FindByPage(offset int, limit int) (result []*model.Customer, count int64, err error) {
 	result, err = c.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -383,6 +410,18 @@ func (c customerDo) FindByPage(offset int, limit int) (result []*model.Customer,
 	return
 }
 
+func (c customerDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.Customer, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = c.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return c.Where(conds...).FindByPage(offset, limit)
+}
+
+func (c customerDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return c.Where(conds...).Count()
+}
+
 func (c customerDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = c.Count()
 	if err != nil {
@@ -157,6 +157,7 @@ type IUserDo interface {
 	Limit(limit int) IUserDo
 	Offset(offset int) IUserDo
 	Count() (count int64, err error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
 	Scopes(funcs ...func(gen.Dao) gen.Dao) IUserDo
 	Unscoped() IUserDo
 	Create(values ...*model.User) error
@@ -182,8 +183,11 @@ type IUserDo interface {
 	Joins(fields ...field.RelationField) IUserDo
 	Preload(fields ...field.RelationField) IUserDo
 	FirstOrInit() (*model.User, error)
+	FirstOrInitWith(seed *model.User) (*model.User, error)
 	FirstOrCreate() (*model.User, error)
+	FirstOrCreateWith(seed *model.User) (*model.User, error)
 	FindByPage(offset int, limit int) (result []*model.User, count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.User, count int64, err error)
 	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
 	Rows() (*sql.Rows, error)
 	Row() *sql.Row
@@ -292,8 +296,9 @@ func (u userDo) Create(values ...*model.User) error {
 	return u.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (u userDo) CreateInBatches(values []*model.User, batchSize int) error {
-	return u.DO.CreateInBatches(values, batchSize)
+	return u.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -377,6 +382,17 @@ func (u userDo) FirstOrInit() (*model.User, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (u userDo) FirstOrInitWith(seed *model.User) (*model.User, error) {
+	if seed == nil {
+		seed = new(model.User)
+	}
+	if err := u.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (u userDo) FirstOrCreate() (*model.User, error) {
 	if result, err := u.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -385,6 +401,17 @@ func (u userDo) FirstOrCreate() (*model.User, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (u userDo) FirstOrCreateWith(seed *model.User) (*model.User, error) {
+	if seed == nil {
+		seed = new(model.User)
+	}
+	if err := u.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (u userDo) FindByPage(offset int, limit int) (result []*model.User, count int64, err error) {
 	result, err = u.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -400,6 +427,18 @@ func (u userDo) FindByPage(offset int, limit int) (result []*model.User, count i
 	return
 }
 
+func (u userDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.User, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = u.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return u.Where(conds...).FindByPage(offset, limit)
+}
+
+func (u userDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return u.Where(conds...).Count()
+}
+
 func (u userDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = u.Count()
 	if err != nil {
@@ -136,6 +136,7 @@ type IBankDo interface {
 	Limit(limit int) IBankDo
 	Offset(offset int) IBankDo
 	Count() (count int64, err error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
 	Scopes(funcs ...func(gen.Dao) gen.Dao) IBankDo
 	Unscoped() IBankDo
 	Create(values ...*model.Bank) error
@@ -161,8 +162,11 @@ type IBankDo interface {
 	Joins(fields ...field.RelationField) IBankDo
 	Preload(fields ...field.RelationField) IBankDo
 	FirstOrInit() (*model.Bank, error)
+	FirstOrInitWith(seed *model.Bank) (*model.Bank, error)
 	FirstOrCreate() (*model.Bank, error)
+	FirstOrCreateWith(seed *model.Bank) (*model.Bank, error)
 	FindByPage(offset int, limit int) (result []*model.Bank, count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.Bank, count int64, err error)
 	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
 	Rows() (*sql.Rows, error)
 	Row() *sql.Row
@@ -271,8 +275,9 @@ func (b bankDo) Create(values ...*model.Bank) error {
 	return b.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (b bankDo) CreateInBatches(values []*model.Bank, batchSize int) error {
-	return b.DO.CreateInBatches(values, batchSize)
+	return b.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -356,6 +361,17 @@ func (b bankDo) FirstOrInit() (*model.Bank, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (b bankDo) FirstOrInitWith(seed *model.Bank) (*model.Bank, error) {
+	if seed == nil {
+		seed = new(model.Bank)
+	}
+	if err := b.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (b bankDo) FirstOrCreate() (*model.Bank, error) {
 	if result, err := b.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -364,6 +380,17 @@ func (b bankDo) FirstOrCreate() (*model.Bank, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (b bankDo) FirstOrCreateWith(seed *model.Bank) (*model.Bank, error) {
+	if seed == nil {
+		seed = new(model.Bank)
+	}
+	if err := b.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (b bankDo) FindByPage(offset int, limit int) (result []*model.Bank, count int64, err error) {
 	result, err = b.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -379,6 +406,18 @@ func (b bankDo) FindByPage(offset int, limit int) (result []*model.Bank, count i
 	return
 }
 
+func (b bankDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.Bank, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = b.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return b.Where(conds...).FindByPage(offset, limit)
+}
+
+func (b bankDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return b.Where(conds...).Count()
+}
+
 func (b bankDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = b.Count()
 	if err != nil {
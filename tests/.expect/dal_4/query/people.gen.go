@@ -36,7 +36,7 @@ func newPerson(db *gorm.DB, opts ...gen.DOOption) person {
 	_person.AnotherFlag = field.NewInt32(tableName, "another_flag")
 	_person.Commit = field.NewString(tableName, "commit")
 	_person.First = field.NewBool(tableName, "First")
-	_person.Bit = field.NewField(tableName, "bit")
+	_person.Bit = field.NewBytes(tableName, "bit")
 	_person.Small = field.NewInt32(tableName, "small")
 	_person.DeletedAt = field.NewField(tableName, "deleted_at")
 	_person.Score = field.NewFloat64(tableName, "score")
@@ -67,7 +67,7 @@ type person struct {
 	AnotherFlag    field.Int32
 	Commit         field.String
 	First          field.Bool
-	Bit            field.Field
+	Bit            field.Bytes
 	Small          field.Int32
 	DeletedAt      field.Field
 	Score          field.Float64
@@ -104,7 +104,7 @@ func (p *person) updateTableName(table string) *person {
 	p.AnotherFlag = field.NewInt32(table, "another_flag")
 	p.Commit = field.NewString(table, "commit")
 	p.First = field.NewBool(table, "First")
-	p.Bit = field.NewField(table, "bit")
+	p.Bit = field.NewBytes(table, "bit")
 	p.Small = field.NewInt32(table, "small")
 	p.DeletedAt = field.NewField(table, "deleted_at")
 	p.Score = field.NewFloat64(table, "score")
@@ -204,6 +204,7 @@ type IPersonDo interface {
 	Limit(limit int) IPersonDo
 	Offset(offset int) IPersonDo
 	Count() (count int64, err error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
 	Scopes(funcs ...func(gen.Dao) gen.Dao) IPersonDo
 	Unscoped() IPersonDo
 	Create(values ...*model.Person) error
@@ -229,8 +230,11 @@ type IPersonDo interface {
 	Joins(fields ...field.RelationField) IPersonDo
 	Preload(fields ...field.RelationField) IPersonDo
 	FirstOrInit() (*model.Person, error)
+	FirstOrInitWith(seed *model.Person) (*model.Person, error)
 	FirstOrCreate() (*model.Person, error)
+	FirstOrCreateWith(seed *model.Person) (*model.Person, error)
 	FindByPage(offset int, limit int) (result []*model.Person, count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.Person, count int64, err error)
 	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
 	Rows() (*sql.Rows, error)
 	Row() *sql.Row
@@ -339,8 +343,9 @@ func (p personDo) Create(values ...*model.Person) error {
 	return p.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (p personDo) CreateInBatches(values []*model.Person, batchSize int) error {
-	return p.DO.CreateInBatches(values, batchSize)
+	return p.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -424,6 +429,17 @@ func (p personDo) FirstOrInit() (*model.Person, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (p personDo) FirstOrInitWith(seed *model.Person) (*model.Person, error) {
+	if seed == nil {
+		seed = new(model.Person)
+	}
+	if err := p.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (p personDo) FirstOrCreate() (*model.Person, error) {
 	if result, err := p.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -432,6 +448,17 @@ func (p personDo) FirstOrCreate() (*model.Person, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (p personDo) FirstOrCreateWith(seed *model.Person) (*model.Person, error) {
+	if seed == nil {
+		seed = new(model.Person)
+	}
+	if err := p.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (p personDo) FindByPage(offset int, limit int) (result []*model.Person, count int64, err error) {
 	result, err = p.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -447,6 +474,18 @@ func (p personDo) FindByPage(offset int, limit int) (result []*model.Person, cou
 	return
 }
 
+func (p personDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.Person, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = p.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return p.Where(conds...).FindByPage(offset, limit)
+}
+
+func (p personDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return p.Where(conds...).Count()
+}
+
 func (p personDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = p.Count()
 	if err != nil {
@@ -443,6 +443,7 @@ type ICommentDo interface {
 	Limit(limit int) ICommentDo
 	Offset(offset int) ICommentDo
 	Count() (count int64, err error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
 	Scopes(funcs ...func(gen.Dao) gen.Dao) ICommentDo
 	Unscoped() ICommentDo
 	Create(values ...*tests_test.Comment) error
@@ -468,8 +469,11 @@ type ICommentDo interface {
 	Joins(fields ...field.RelationField) ICommentDo
 	Preload(fields ...field.RelationField) ICommentDo
 	FirstOrInit() (*tests_test.Comment, error)
+	FirstOrInitWith(seed *tests_test.Comment) (*tests_test.Comment, error)
 	FirstOrCreate() (*tests_test.Comment, error)
+	FirstOrCreateWith(seed *tests_test.Comment) (*tests_test.Comment, error)
 	FindByPage(offset int, limit int) (result []*tests_test.Comment, count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*tests_test.Comment, count int64, err error)
 	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
 	Rows() (*sql.Rows, error)
 	Row() *sql.Row
@@ -578,8 +582,9 @@ func (c commentDo) Create(values ...*tests_test.Comment) error {
 	return c.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (c commentDo) CreateInBatches(values []*tests_test.Comment, batchSize int) error {
-	return c.DO.CreateInBatches(values, batchSize)
+	return c.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -663,6 +668,17 @@ func (c commentDo) FirstOrInit() (*tests_test.Comment, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (c commentDo) FirstOrInitWith(seed *tests_test.Comment) (*tests_test.Comment, error) {
+	if seed == nil {
+		seed = new(tests_test.Comment)
+	}
+	if err := c.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (c commentDo) FirstOrCreate() (*tests_test.Comment, error) {
 	if result, err := c.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -671,6 +687,17 @@ func (c commentDo) FirstOrCreate() (*tests_test.Comment, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (c commentDo) FirstOrCreateWith(seed *tests_test.Comment) (*tests_test.Comment, error) {
+	if seed == nil {
+		seed = new(tests_test.Comment)
+	}
+	if err := c.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (c commentDo) FindByPage(offset int, limit int) (result []*tests_test.Comment, count int64, err error) {
 	result, err = c.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -686,6 +713,18 @@ func (c commentDo) FindByPage(offset int, limit int) (result []*tests_test.Comme
 	return
 }
 
+func (c commentDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*tests_test.Comment, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = c.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return c.Where(conds...).FindByPage(offset, limit)
+}
+
+func (c commentDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return c.Where(conds...).Count()
+}
+
 func (c commentDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = c.Count()
 	if err != nil {
@@ -417,11 +417,14 @@ type IPostDo interface {
 	Limit(limit int) IPostDo
 	Offset(offset int) IPostDo
 	Count() (count int64, err error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
 	Scopes(funcs ...func(gen.Dao) gen.Dao) IPostDo
 	Unscoped() IPostDo
 	Create(values ...*tests_test.Post) error
 	CreateInBatches(values []*tests_test.Post, batchSize int) error
 	Save(values ...*tests_test.Post) error
+	CountAssociationComments(m *tests_test.Post) (int64, error)
+	ClearComments(m *tests_test.Post) error
 	First() (*tests_test.Post, error)
 	Take() (*tests_test.Post, error)
 	Last() (*tests_test.Post, error)
@@ -442,8 +445,11 @@ type IPostDo interface {
 	Joins(fields ...field.RelationField) IPostDo
 	Preload(fields ...field.RelationField) IPostDo
 	FirstOrInit() (*tests_test.Post, error)
+	FirstOrInitWith(seed *tests_test.Post) (*tests_test.Post, error)
 	FirstOrCreate() (*tests_test.Post, error)
+	FirstOrCreateWith(seed *tests_test.Post) (*tests_test.Post, error)
 	FindByPage(offset int, limit int) (result []*tests_test.Post, count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*tests_test.Post, count int64, err error)
 	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
 	Rows() (*sql.Rows, error)
 	Row() *sql.Row
@@ -552,8 +558,9 @@ func (p postDo) Create(values ...*tests_test.Post) error {
 	return p.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (p postDo) CreateInBatches(values []*tests_test.Post, batchSize int) error {
-	return p.DO.CreateInBatches(values, batchSize)
+	return p.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -637,6 +644,17 @@ func (p postDo) FirstOrInit() (*tests_test.Post, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (p postDo) FirstOrInitWith(seed *tests_test.Post) (*tests_test.Post, error) {
+	if seed == nil {
+		seed = new(tests_test.Post)
+	}
+	if err := p.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (p postDo) FirstOrCreate() (*tests_test.Post, error) {
 	if result, err := p.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -645,6 +663,17 @@ func (p postDo) FirstOrCreate() (*tests_test.Post, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (p postDo) FirstOrCreateWith(seed *tests_test.Post) (*tests_test.Post, error) {
+	if seed == nil {
+		seed = new(tests_test.Post)
+	}
+	if err := p.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (p postDo) FindByPage(offset int, limit int) (result []*tests_test.Post, count int64, err error) {
 	result, err = p.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -660,6 +689,18 @@ func (p postDo) FindByPage(offset int, limit int) (result []*tests_test.Post, co
 	return
 }
 
+func (p postDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*tests_test.Post, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = p.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return p.Where(conds...).FindByPage(offset, limit)
+}
+
+func (p postDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return p.Where(conds...).Count()
+}
+
 func (p postDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = p.Count()
 	if err != nil {
@@ -682,3 +723,18 @@ func (p *postDo) withDO(do gen.Dao) *postDo {
 	p.DO = *do.(*gen.DO)
 	return p
 }
+
+// CountAssociationComments count Comments associated with m
+func (p postDo) CountAssociationComments(m *tests_test.Post) (int64, error) {
+	association := p.DO.UnderlyingDB().Model(m).Association("Comments")
+	if association.Error != nil {
+		return 0, association.Error
+	}
+	count := association.Count()
+	return count, association.Error
+}
+
+// ClearComments remove references between m and its Comments, associated records are not deleted
+func (p postDo) ClearComments(m *tests_test.Post) error {
+	return p.DO.UnderlyingDB().Model(m).Association("Comments").Clear()
+}
@@ -413,11 +413,16 @@ type IUserDo interface {
 	Limit(limit int) IUserDo
 	Offset(offset int) IUserDo
 	Count() (count int64, err error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
 	Scopes(funcs ...func(gen.Dao) gen.Dao) IUserDo
 	Unscoped() IUserDo
 	Create(values ...*tests_test.User) error
 	CreateInBatches(values []*tests_test.User, batchSize int) error
 	Save(values ...*tests_test.User) error
+	CountAssociationPosts(m *tests_test.User) (int64, error)
+	ClearPosts(m *tests_test.User) error
+	CountAssociationComments(m *tests_test.User) (int64, error)
+	ClearComments(m *tests_test.User) error
 	First() (*tests_test.User, error)
 	Take() (*tests_test.User, error)
 	Last() (*tests_test.User, error)
@@ -438,8 +443,11 @@ type IUserDo interface {
 	Joins(fields ...field.RelationField) IUserDo
 	Preload(fields ...field.RelationField) IUserDo
 	FirstOrInit() (*tests_test.User, error)
+	FirstOrInitWith(seed *tests_test.User) (*tests_test.User, error)
 	FirstOrCreate() (*tests_test.User, error)
+	FirstOrCreateWith(seed *tests_test.User) (*tests_test.User, error)
 	FindByPage(offset int, limit int) (result []*tests_test.User, count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*tests_test.User, count int64, err error)
 	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
 	Rows() (*sql.Rows, error)
 	Row() *sql.Row
@@ -548,8 +556,9 @@ func (u userDo) Create(values ...*tests_test.User) error {
 	return u.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (u userDo) CreateInBatches(values []*tests_test.User, batchSize int) error {
-	return u.DO.CreateInBatches(values, batchSize)
+	return u.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -633,6 +642,17 @@ func (u userDo) FirstOrInit() (*tests_test.User, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (u userDo) FirstOrInitWith(seed *tests_test.User) (*tests_test.User, error) {
+	if seed == nil {
+		seed = new(tests_test.User)
+	}
+	if err := u.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (u userDo) FirstOrCreate() (*tests_test.User, error) {
 	if result, err := u.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -641,6 +661,17 @@ func (u userDo) FirstOrCreate() (*tests_test.User, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (u userDo) FirstOrCreateWith(seed *tests_test.User) (*tests_test.User, error) {
+	if seed == nil {
+		seed = new(tests_test.User)
+	}
+	if err := u.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (u userDo) FindByPage(offset int, limit int) (result []*tests_test.User, count int64, err error) {
 	result, err = u.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -656,6 +687,18 @@ func (u userDo) FindByPage(offset int, limit int) (result []*tests_test.User, co
 	return
 }
 
+func (u userDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*tests_test.User, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = u.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return u.Where(conds...).FindByPage(offset, limit)
+}
+
+func (u userDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return u.Where(conds...).Count()
+}
+
 func (u userDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = u.Count()
 	if err != nil {
@@ -678,3 +721,33 @@ func (u *userDo) withDO(do gen.Dao) *userDo {
 	u.DO = *do.(*gen.DO)
 	return u
 }
+
+// CountAssociationPosts count Posts associated with m
+func (u userDo) CountAssociationPosts(m *tests_test.User) (int64, error) {
+	association := u.DO.UnderlyingDB().Model(m).Association("Posts")
+	if association.Error != nil {
+		return 0, association.Error
+	}
+	count := association.Count()
+	return count, association.Error
+}
+
+// ClearPosts remove references between m and its Posts, associated records are not deleted
+func (u userDo) ClearPosts(m *tests_test.User) error {
+	return u.DO.UnderlyingDB().Model(m).Association("Posts").Clear()
+}
+
+// CountAssociationComments count Comments associated with m
+func (u userDo) CountAssociationComments(m *tests_test.User) (int64, error) {
+	association := u.DO.UnderlyingDB().Model(m).Association("Comments")
+	if association.Error != nil {
+		return 0, association.Error
+	}
+	count := association.Count()
+	return count, association.Error
+}
+
+// ClearComments remove references between m and its Comments, associated records are not deleted
+func (u userDo) ClearComments(m *tests_test.User) error {
+	return u.DO.UnderlyingDB().Model(m).Association("Comments").Clear()
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"gorm.io/gen"
 	"gorm.io/gen/field"
@@ -109,6 +110,10 @@ type bankDo struct {
 }
 type IBankDo interface {
 	gen.IGenericsDo[IBankDo, *model.Bank]
+	FirstOrInitWith(seed *model.Bank) (*model.Bank, error)
+	FirstOrCreateWith(seed *model.Bank) (*model.Bank, error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.Bank, count int64, err error)
 }
 
 func (b *bankDo) withDO(do gen.Dao) IBankDo {
@@ -117,3 +122,8 @@ func (b *bankDo) withDO(do gen.Dao) IBankDo {
 	_r.IWithDO = gen.WithDOFunc[IBankDo](b.withDO)
 	return _r
 }
+
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
+func (b *bankDo) CreateInBatches(values []*model.Bank, batchSize int) error {
+	return b.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"gorm.io/gen"
 	"gorm.io/gen/field"
@@ -135,6 +136,10 @@ type userDo struct {
 }
 type IUserDo interface {
 	gen.IGenericsDo[IUserDo, *model.User]
+	FirstOrInitWith(seed *model.User) (*model.User, error)
+	FirstOrCreateWith(seed *model.User) (*model.User, error)
+	CountBy(conds ...gen.Condition) (count int64, err error)
+	FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.User, count int64, err error)
 	FindByUsers(user model.User) (result []model.User)
 	FindByComplexIf(user *model.User) (result []model.User)
 	FindByIfTime(start time.Time) (result []model.User)
@@ -906,3 +911,8 @@ func (u *userDo) withDO(do gen.Dao) IUserDo {
 	_r.IWithDO = gen.WithDOFunc[IUserDo](u.withDO)
 	return _r
 }
+
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
+func (u *userDo) CreateInBatches(values []*model.User, batchSize int) error {
+	return u.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
+}
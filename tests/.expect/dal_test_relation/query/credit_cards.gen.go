@@ -221,8 +221,9 @@ func (c creditCardDo) Create(values ...*model.CreditCard) error {
 	return c.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (c creditCardDo) CreateInBatches(values []*model.CreditCard, batchSize int) error {
-	return c.DO.CreateInBatches(values, batchSize)
+	return c.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -306,6 +307,17 @@ func (c creditCardDo) FirstOrInit() (*model.CreditCard, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (c creditCardDo) FirstOrInitWith(seed *model.CreditCard) (*model.CreditCard, error) {
+	if seed == nil {
+		seed = new(model.CreditCard)
+	}
+	if err := c.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (c creditCardDo) FirstOrCreate() (*model.CreditCard, error) {
 	if result, err := c.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -314,6 +326,17 @@ func (c creditCardDo) FirstOrCreate() (*model.CreditCard, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (c creditCardDo) FirstOrCreateWith(seed *model.CreditCard) (*model.CreditCard, error) {
+	if seed == nil {
+		seed = new(model.CreditCard)
+	}
+	if err := c.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (c creditCardDo) FindByPage(offset int, limit int) (result []*model.CreditCard, count int64, err error) {
 	result, err = c.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -329,6 +352,18 @@ func (c creditCardDo) FindByPage(offset int, limit int) (result []*model.CreditC
 	return
 }
 
+func (c creditCardDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.CreditCard, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = c.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return c.Where(conds...).FindByPage(offset, limit)
+}
+
+func (c creditCardDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return c.Where(conds...).Count()
+}
+
 func (c creditCardDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = c.Count()
 	if err != nil {
@@ -112,6 +112,7 @@ func (c *customer) fillFieldMap() {
 	c.fieldMap["updated_at"] = c.UpdatedAt
 	c.fieldMap["deleted_at"] = c.DeletedAt
 	c.fieldMap["bank_id"] = c.BankID
+
 }
 
 func (c customer) clone(db *gorm.DB) customer {
@@ -163,6 +164,11 @@ func (a customerHasOneBank) Model(m *model.Customer) *customerHasOneBankTx {
 	return &customerHasOneBankTx{a.db.Model(m).Association(a.Name())}
 }
 
+func (a customerHasOneBank) Unscoped() *customerHasOneBank {
+	a.db = a.db.Unscoped()
+	return &a
+}
+
 type customerHasOneBankTx struct{ tx *gorm.Association }
 
 func (a customerHasOneBankTx) Find() (result *model.Bank, err error) {
@@ -201,6 +207,11 @@ func (a customerHasOneBankTx) Count() int64 {
 	return a.tx.Count()
 }
 
+func (a customerHasOneBankTx) Unscoped() *customerHasOneBankTx {
+	a.tx = a.tx.Unscoped()
+	return &a
+}
+
 type customerHasManyCreditCards struct {
 	db *gorm.DB
 
@@ -234,6 +245,11 @@ func (a customerHasManyCreditCards) Model(m *model.Customer) *customerHasManyCre
 	return &customerHasManyCreditCardsTx{a.db.Model(m).Association(a.Name())}
 }
 
+func (a customerHasManyCreditCards) Unscoped() *customerHasManyCreditCards {
+	a.db = a.db.Unscoped()
+	return &a
+}
+
 type customerHasManyCreditCardsTx struct{ tx *gorm.Association }
 
 func (a customerHasManyCreditCardsTx) Find() (result []*model.CreditCard, err error) {
@@ -272,6 +288,11 @@ func (a customerHasManyCreditCardsTx) Count() int64 {
 	return a.tx.Count()
 }
 
+func (a customerHasManyCreditCardsTx) Unscoped() *customerHasManyCreditCardsTx {
+	a.tx = a.tx.Unscoped()
+	return &a
+}
+
 type customerDo struct{ gen.DO }
 
 func (c customerDo) Debug() *customerDo {
@@ -373,8 +394,9 @@ func (c customerDo) Create(values ...*model.Customer) error {
 	return c.DO.Create(values)
 }
 
+// CreateInBatches create values in batches of batchSize, associations of values are not saved
 func (c customerDo) CreateInBatches(values []*model.Customer, batchSize int) error {
-	return c.DO.CreateInBatches(values, batchSize)
+	return c.DO.UnderlyingDB().Omit(clause.Associations).CreateInBatches(values, batchSize).Error
 }
 
 // Save : !!! underlying implementation is different with GORM
@@ -458,6 +480,17 @@ func (c customerDo) FirstOrInit() (*model.Customer, error) {
 	}
 }
 
+// FirstOrInitWith find first record into seed, seed keeps its values and is initialized with conditions and attrs when not found
+func (c customerDo) FirstOrInitWith(seed *model.Customer) (*model.Customer, error) {
+	if seed == nil {
+		seed = new(model.Customer)
+	}
+	if err := c.DO.FirstOrInitWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (c customerDo) FirstOrCreate() (*model.Customer, error) {
 	if result, err := c.DO.FirstOrCreate(); err != nil {
 		return nil, err
@@ -466,6 +499,17 @@ func (c customerDo) FirstOrCreate() (*model.Customer, error) {
 	}
 }
 
+// FirstOrCreateWith find first record into seed, seed is created with conditions and attrs when not found
+func (c customerDo) FirstOrCreateWith(seed *model.Customer) (*model.Customer, error) {
+	if seed == nil {
+		seed = new(model.Customer)
+	}
+	if err := c.DO.FirstOrCreateWith(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
 func (c customerDo) FindByPage(offset int, limit int) (result []*model.Customer, count int64, err error) {
 	result, err = c.Offset(offset).Limit(limit).Find()
 	if err != nil {
@@ -481,6 +525,18 @@ func (c customerDo) FindByPage(offset int, limit int) (result []*model.Customer,
 	return
 }
 
+func (c customerDo) FindPage(offset int, limit int, conds ...gen.Condition) (result []*model.Customer, count int64, err error) {
+	if limit <= 0 { // all matching records without paging
+		result, err = c.Where(conds...).Find()
+		return result, int64(len(result)), err
+	}
+	return c.Where(conds...).FindByPage(offset, limit)
+}
+
+func (c customerDo) CountBy(conds ...gen.Condition) (count int64, err error) {
+	return c.Where(conds...).Count()
+}
+
 func (c customerDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
 	count, err = c.Count()
 	if err != nil {
@@ -503,3 +559,18 @@ func (c *customerDo) withDO(do gen.Dao) *customerDo {
 	c.DO = *do.(*gen.DO)
 	return c
 }
+
+// CountAssociationCreditCards count CreditCards associated with m
+func (c customerDo) CountAssociationCreditCards(m *model.Customer) (int64, error) {
+	association := c.DO.UnderlyingDB().Model(m).Association("CreditCards")
+	if association.Error != nil {
+		return 0, association.Error
+	}
+	count := association.Count()
+	return count, association.Error
+}
+
+// ClearCreditCards remove references between m and its CreditCards, associated records are not deleted
+func (c customerDo) ClearCreditCards(m *model.Customer) error {
+	return c.DO.UnderlyingDB().Model(m).Association("CreditCards").Clear()
+}
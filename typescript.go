@@ -0,0 +1,104 @@
+package gen
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gen/field"
+	"gorm.io/gen/internal/model"
+)
+
+// tsIdentifierReg property names matching it are written without quotes
+var tsIdentifierReg = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// GenerateTypeScript write a <table>.ts file of TypeScript interface into outDir for each table introspected by GenerateModel,
+// property names are json tag names of fields or column names, go code is not affected
+func (g *Generator) GenerateTypeScript(outDir string) error {
+	if err := g.mkdirAll(outDir); err != nil {
+		return fmt.Errorf("make dir %s fail: %w", outDir, err)
+	}
+
+	names := make([]string, 0, len(g.models))
+	for name, meta := range g.models {
+		if meta != nil && meta.Source == model.Table {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		meta := g.models[name]
+		fileName := filepath.Join(outDir, meta.FileName+".ts")
+		if err := g.writeFile(fileName, typeScriptInterface(meta.ModelStructName, meta.TableName, meta.Fields)); err != nil {
+			return fmt.Errorf("write typescript file %s fail: %w", fileName, err)
+		}
+		g.info(fmt.Sprintf("generate typescript file: %s", fileName))
+	}
+	return nil
+}
+
+// typeScriptInterface build TypeScript interface of model, string literal union type is declared for each enum field
+func typeScriptInterface(modelName, tableName string, fields []*model.Field) []byte {
+	var enums, props strings.Builder
+	for _, f := range fields {
+		if f.Column == nil {
+			continue
+		}
+		name := f.ColumnName
+		if tag := strings.Split(f.Tag[field.TagKeyJson], ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		if !tsIdentifierReg.MatchString(name) {
+			name = strconv.Quote(name)
+		}
+
+		typ := typeScriptType(f.Type)
+		if f.Enum != nil {
+			values := make([]string, 0, len(f.Enum.Values))
+			for _, v := range f.Enum.Values {
+				values = append(values, strconv.Quote(v.Value))
+			}
+			fmt.Fprintf(&enums, "export type %s = %s;\n\n", f.Enum.TypeName, strings.Join(values, " | "))
+			typ = f.Enum.TypeName
+		}
+		if nullable, _ := f.Column.Nullable(); nullable || strings.HasPrefix(f.Type, "*") || f.Type == "gorm.DeletedAt" {
+			typ += " | null"
+		}
+		fmt.Fprintf(&props, "  %s: %s;\n", name, typ)
+	}
+
+	var buf strings.Builder
+	buf.WriteString("// Code generated by gorm.io/gen. DO NOT EDIT.\n\n")
+	buf.WriteString(enums.String())
+	fmt.Fprintf(&buf, "// %s mapped from table <%s>\n", modelName, tableName)
+	fmt.Fprintf(&buf, "export interface %s {\n%s}\n", modelName, props.String())
+	return []byte(buf.String())
+}
+
+// typeScriptType TypeScript type of go type in json, unknown for types without a fixed json shape
+func typeScriptType(goType string) string {
+	switch strings.TrimPrefix(goType, "*") {
+	case "string", "[]byte":
+		return "string"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "number"
+	case "bool":
+		return "boolean"
+	case "time.Time", "gorm.DeletedAt", "datatypes.Date":
+		return "Date"
+	case "pq.StringArray":
+		return "string[]"
+	case "pq.Int32Array", "pq.Int64Array", "pq.Float32Array", "pq.Float64Array":
+		return "number[]"
+	case "pq.BoolArray":
+		return "boolean[]"
+	default:
+		return "unknown"
+	}
+}